@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"april/internal/events"
 	httpapi "april/internal/http"
 	"april/internal/repository"
 	"april/internal/service"
@@ -21,16 +22,91 @@ func main() {
 	ordersRepo := repository.NewMemoryOrders(store)
 	tx := repository.NewMemoryTx(store)
 
-	productsSvc := service.NewProductService(store)
+	bus := events.NewBus()
+
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetEvents(bus)
+	categoriesRepo := repository.NewMemoryCategories(store)
+	productsSvc.SetCategories(categoriesRepo)
+	searchSvc := service.NewSearchService(store)
+	if err := searchSvc.Rebuild(context.Background()); err != nil {
+		log.Fatalf("search index: %v", err)
+	}
+	searchSvc.Subscribe(bus)
+	productsSvc.SetSearch(searchSvc)
+	suggestSvc := service.NewSuggestService(store, ordersRepo)
+	if err := suggestSvc.Rebuild(context.Background()); err != nil {
+		log.Fatalf("suggest index: %v", err)
+	}
+	suggestSvc.Subscribe(bus)
+	pricesSvc := service.NewPriceService(store, repository.NewMemoryPriceChanges(store), tx)
+	pricesSvc.SetEvents(bus)
+	pricesSvc.Subscribe(bus)
+	categoriesSvc := service.NewCategoryService(categoriesRepo, store, tx)
 	ordersSvc := service.NewOrderService(store, ordersRepo, tx)
+	ordersSvc.SetEvents(bus)
+	batchesRepo := repository.NewMemoryBatches(store)
+	serialsRepo := repository.NewMemorySerials(store)
+	ordersSvc.SetSerials(serialsRepo)
+	serialsSvc := service.NewSerialService(store, batchesRepo, serialsRepo, tx)
+	promotionsSvc := service.NewPromotionService(repository.NewMemoryPromotions(store), store)
+	promotionsSvc.SetCategories(categoriesRepo)
+	ordersSvc.SetDiscounts(promotionsSvc)
+	refundsRepo := repository.NewMemoryRefunds(store)
+	ordersSvc.SetRefunds(refundsRepo)
+	ordersSvc.SetEdits(repository.NewMemoryOrderEdits(store))
+	creditsSvc := service.NewCreditService(repository.NewMemoryCredits(store), tx)
+	// реального провайдера пока нет, а провайдер в памяти одобряет любой токен,
+	// поэтому оплаты включаются только явно, для разработки: PAYMENT_PROVIDER=fake
+	var paymentsSvc *service.PaymentService
+	if os.Getenv("PAYMENT_PROVIDER") == "fake" {
+		log.Printf("payments use the in-memory provider; nothing is actually charged")
+		paymentsSvc = service.NewPaymentService(ordersRepo, repository.NewMemoryPayments(store), service.NewFakePaymentProvider(), tx)
+		paymentsSvc.SetRefunds(refundsRepo)
+		paymentsSvc.SetCredits(creditsSvc)
+		paymentsSvc.Subscribe(bus)
+	}
+	loyaltySvc := service.NewLoyaltyService(repository.NewMemoryLoyalty(store), tx)
+	ordersSvc.SetLoyalty(loyaltySvc)
+	loyaltySvc.Subscribe(bus)
+	cartsSvc := service.NewCartService(repository.NewMemoryCarts(store), store, ordersSvc, tx)
 
-	srv := httpapi.NewServer(productsSvc, ordersSvc)
+	var notifier service.Notifier = service.LogNotifier{}
+	if url := os.Getenv("LOW_STOCK_WEBHOOK_URL"); url != "" {
+		notifier = service.NewWebhookNotifier(url)
+	}
+	alertsSvc := service.NewAlertService(store, repository.NewMemoryAlerts(store), notifier)
+	alertsSvc.Subscribe(bus)
+
+	purchasesSvc := service.NewPurchaseService(store, repository.NewMemorySuppliers(store), repository.NewMemoryPurchaseOrders(store), tx)
+	purchasesSvc.SetEvents(bus)
+	purchasesSvc.SetSerials(batchesRepo, serialsRepo)
+	stocktakesSvc := service.NewStocktakeService(store, repository.NewMemoryStocktakes(store), tx)
+	stocktakesSvc.SetEvents(bus)
+
+	srv := httpapi.NewServer(productsSvc, ordersSvc,
+		httpapi.WithAlerts(alertsSvc),
+		httpapi.WithPurchasing(purchasesSvc),
+		httpapi.WithStocktakes(stocktakesSvc),
+		httpapi.WithCategories(categoriesSvc),
+		httpapi.WithSerials(serialsSvc),
+		httpapi.WithSuggestions(suggestSvc),
+		httpapi.WithPrices(pricesSvc),
+		httpapi.WithPromotions(promotionsSvc),
+		httpapi.WithPayments(paymentsSvc),
+		httpapi.WithCredits(creditsSvc),
+		httpapi.WithLoyalty(loyaltySvc),
+		httpapi.WithCarts(cartsSvc),
+	)
 
 	httpServer := &http.Server{
 		Addr:    ":9091",
 		Handler: srv.Engine(),
 	}
 
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	go pricesSvc.Run(schedulerCtx, time.Minute)
+
 	go func() {
 		log.Printf("HTTP server listening on %s", httpServer.Addr)
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -41,9 +117,11 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
+	stopScheduler()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := httpServer.Shutdown(ctx); err != nil {
 		log.Printf("shutdown error: %v", err)
 	}
+	bus.Wait()
 }
@@ -9,6 +9,29 @@ type Product struct {
 	SKU   string  `json:"sku"`
 	Price float64 `json:"price"`
 	Stock int64   `json:"stock"`
+	// ReorderPoint порог запаса, при достижении которого товар нужно дозаказать (0 — не отслеживается)
+	ReorderPoint int64 `json:"reorder_point"`
+	// ReorderQty рекомендуемое количество дозаказа
+	ReorderQty int64 `json:"reorder_quantity"`
+	// CategoryIDs категории каталога, к которым отнесён товар
+	CategoryIDs []int64 `json:"category_ids"`
+	// Attributes значения атрибутов по коду; допустимые коды и типы задаёт схема категорий
+	Attributes map[string]string `json:"attributes"`
+	// Barcodes штрихкоды GTIN-8/12/13/14, уникальные в пределах каталога
+	Barcodes []string `json:"barcodes"`
+	// Serialized маркированный товар: каждая упаковка учитывается по своему коду
+	Serialized bool `json:"serialized"`
+	// TaxRate ставка НДС в процентах; цена товара включает налог
+	TaxRate float64 `json:"tax_rate"`
+	// ArchivedAt время архивации; архивный товар скрыт из каталога (nil — активен)
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// LowStock true, если запас опустился до точки дозаказа
+func (p Product) LowStock() bool {
+	return p.ReorderPoint > 0 && p.Stock <= p.ReorderPoint
 }
 
 // OrderStatus тип статуса заказа
@@ -23,7 +46,26 @@ const (
 // OrderItem позиция в заказе
 type OrderItem struct {
 	ProductID int64 `json:"product_id"`
-	Quantity  int64 `json:"quantity"`
+	// Barcode позволяет указать товар штрихкодом вместо product_id
+	Barcode  string `json:"barcode,omitempty"`
+	Quantity int64  `json:"quantity"`
+	// Serials коды проданных упаковок маркированного товара, по одному на единицу
+	Serials []string `json:"serials,omitempty"`
+	// UnitPrice цена единицы на момент заказа
+	UnitPrice float64 `json:"unit_price"`
+	// Discount скидка на всю строку, Discounts — её разбивка по акциям
+	Discount  float64        `json:"discount"`
+	Discounts []LineDiscount `json:"discounts,omitempty"`
+	// TaxRate ставка НДС на момент заказа, Tax — налог, включённый в сумму строки после скидок
+	TaxRate float64 `json:"tax_rate"`
+	Tax     float64 `json:"tax"`
+}
+
+// LineDiscount скидка одной акции на строку заказа
+type LineDiscount struct {
+	PromotionID int64   `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Amount      float64 `json:"amount"`
 }
 
 // Order сущность заказа
@@ -32,6 +74,610 @@ type Order struct {
 	CustomerName string      `json:"customer_name"`
 	Items        []OrderItem `json:"items"`
 	Status       OrderStatus `json:"status"`
+	// PromoCodes промокоды, применённые при создании
+	PromoCodes []string `json:"promo_codes,omitempty"`
+	// PointsRedeemed баллы лояльности, списанные в счёт скидки при создании, за
+	// вычетом возвращённых, когда после изменения заказа скидка в него не поместилась
+	PointsRedeemed int64 `json:"points_redeemed,omitempty"`
+	// Subtotal сумма по ценам без скидок, Total — к оплате
+	Subtotal float64 `json:"subtotal"`
+	Discount float64 `json:"discount"`
+	Total    float64 `json:"total"`
+	// Tax НДС, включённый в Total
+	Tax       float64   `json:"tax"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaxRateSummary итоги продаж по одной ставке НДС: Gross — с налогом, Net — без него
+type TaxRateSummary struct {
+	Rate  float64 `json:"rate"`
+	Net   float64 `json:"net"`
+	Tax   float64 `json:"tax"`
+	Gross float64 `json:"gross"`
+}
+
+// TaxSummary налоговая сводка по заказам, созданным в периоде [From, To)
+type TaxSummary struct {
+	From   time.Time        `json:"from"`
+	To     time.Time        `json:"to"`
+	Orders int              `json:"orders"`
+	Rates  []TaxRateSummary `json:"rates"`
+	Net    float64          `json:"net"`
+	Tax    float64          `json:"tax"`
+	Gross  float64          `json:"gross"`
+}
+
+// AlertStatus статус оповещения о низком запасе
+type AlertStatus string
+
+const (
+	AlertStatusOpen     AlertStatus = "Open"
+	AlertStatusResolved AlertStatus = "Resolved"
+)
+
+// LowStockAlert оповещение о том, что запас товара достиг точки дозаказа
+type LowStockAlert struct {
+	ID           int64       `json:"id"`
+	ProductID    int64       `json:"product_id"`
+	ProductName  string      `json:"product_name"`
+	Stock        int64       `json:"stock"`
+	ReorderPoint int64       `json:"reorder_point"`
+	ReorderQty   int64       `json:"reorder_quantity"`
+	Status       AlertStatus `json:"status"`
 	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
+	ResolvedAt   *time.Time  `json:"resolved_at,omitempty"`
+}
+
+// Supplier поставщик товаров
+type Supplier struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Contact   string    `json:"contact"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PurchaseOrderStatus статус заказа поставщику
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderStatusDraft             PurchaseOrderStatus = "Draft"
+	PurchaseOrderStatusSent              PurchaseOrderStatus = "Sent"
+	PurchaseOrderStatusPartiallyReceived PurchaseOrderStatus = "PartiallyReceived"
+	PurchaseOrderStatusReceived          PurchaseOrderStatus = "Received"
+	PurchaseOrderStatusCancelled         PurchaseOrderStatus = "Cancelled"
+)
+
+// PurchaseOrderLine строка заказа поставщику
+type PurchaseOrderLine struct {
+	ProductID int64   `json:"product_id"`
+	Quantity  int64   `json:"quantity"`
+	Received  int64   `json:"received"`
+	UnitCost  float64 `json:"unit_cost"`
+}
+
+// Outstanding сколько ещё ожидается к приёмке
+func (l PurchaseOrderLine) Outstanding() int64 { return l.Quantity - l.Received }
+
+// PurchaseOrder заказ поставщику на пополнение запаса
+type PurchaseOrder struct {
+	ID         int64               `json:"id"`
+	SupplierID int64               `json:"supplier_id"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+	Status     PurchaseOrderStatus `json:"status"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// ReceiptLine принятое по заказу поставщику количество товара
+type ReceiptLine struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+	// Batch и Serials обязательны для маркированного товара: номер серии и коды упаковок
+	Batch   string   `json:"batch,omitempty"`
+	Serials []string `json:"serials,omitempty"`
+}
+
+// StocktakeStatus статус инвентаризации
+type StocktakeStatus string
+
+const (
+	StocktakeStatusOpen      StocktakeStatus = "Open"
+	StocktakeStatusApproved  StocktakeStatus = "Approved"
+	StocktakeStatusCancelled StocktakeStatus = "Cancelled"
+)
+
+// StocktakeLine строка инвентаризации: ожидаемый на момент начала запас и посчитанный факт
+type StocktakeLine struct {
+	ProductID int64  `json:"product_id"`
+	Expected  int64  `json:"expected"`
+	Counted   *int64 `json:"counted"`
+	// Variance расхождение факта с ожидаемым (counted - expected)
+	Variance int64 `json:"variance"`
+	// Adjusted корректировка, фактически применённая к запасу при утверждении
+	Adjusted int64 `json:"adjusted"`
+}
+
+// Stocktake сессия инвентаризации (пересчёта остатков)
+type Stocktake struct {
+	ID         int64           `json:"id"`
+	Status     StocktakeStatus `json:"status"`
+	Lines      []StocktakeLine `json:"lines"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	ApprovedAt *time.Time      `json:"approved_at,omitempty"`
+}
+
+// StockCount посчитанное количество товара в одной передаче результатов
+type StockCount struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+// Category узел дерева каталога. ParentID = 0 — корневая категория,
+// Position — порядок среди соседей
+type Category struct {
+	ID       int64  `json:"id"`
+	ParentID int64  `json:"parent_id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Position int    `json:"position"`
+	// Attributes схема атрибутов товаров категории; наследуется подкатегориями
+	Attributes []AttributeDef `json:"attributes"`
+}
+
+// AttributeType тип значения атрибута товара
+type AttributeType string
+
+const (
+	AttributeTypeString  AttributeType = "string"
+	AttributeTypeNumber  AttributeType = "number"
+	AttributeTypeInteger AttributeType = "integer"
+	AttributeTypeBoolean AttributeType = "boolean"
+	AttributeTypeEnum    AttributeType = "enum"
+)
+
+// Коды атрибутов аптечного каталога
+const (
+	AttrManufacturer    = "manufacturer"
+	AttrActiveSubstance = "active_substance"
+	AttrDosage          = "dosage"
+	AttrDosageForm      = "dosage_form"
+	AttrPackSize        = "pack_size"
+	AttrCountry         = "country"
+)
+
+// AttributeDef описание атрибута в схеме категории
+type AttributeDef struct {
+	Code     string        `json:"code"`
+	Name     string        `json:"name"`
+	Type     AttributeType `json:"type"`
+	Required bool          `json:"required"`
+	// Options допустимые значения для типа enum
+	Options []string `json:"options,omitempty"`
+}
+
+// CategoryNode категория с дочерними узлами для выдачи дерева
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+// Batch серия (партия) товара от производителя
+type Batch struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	Number    string    `json:"number"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SerialStatus состояние маркированной упаковки
+type SerialStatus string
+
+const (
+	SerialStatusInStock SerialStatus = "InStock"
+	SerialStatusSold    SerialStatus = "Sold"
+)
+
+// SerialUnit маркированная упаковка с уникальным кодом Data Matrix
+type SerialUnit struct {
+	Code      string       `json:"code"`
+	ProductID int64        `json:"product_id"`
+	BatchID   int64        `json:"batch_id"`
+	Status    SerialStatus `json:"status"`
+	// OrderID заказ, в котором упаковка продана (0 — на складе)
+	OrderID   int64     `json:"order_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PriceBucket диапазон цен [From, To) и число товаров в нём; To == nil — без верхней границы
+type PriceBucket struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to,omitempty"`
+	Count int      `json:"count"`
+}
+
+// FacetCount число товаров с данным значением признака
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// CategoryFacet число товаров в категории с учётом подкатегорий
+type CategoryFacet struct {
+	CategoryID int64 `json:"category_id"`
+	Count      int   `json:"count"`
+}
+
+// ProductFacets агрегаты по выборке товаров для панели фильтров каталога
+type ProductFacets struct {
+	Total         int             `json:"total"`
+	Price         []PriceBucket   `json:"price"`
+	InStock       int             `json:"in_stock"`
+	OutOfStock    int             `json:"out_of_stock"`
+	Categories    []CategoryFacet `json:"categories"`
+	Manufacturers []FacetCount    `json:"manufacturers"`
+}
+
+// PriceChangeStatus состояние изменения цены
+type PriceChangeStatus string
+
+const (
+	PriceChangeStatusScheduled PriceChangeStatus = "Scheduled"
+	PriceChangeStatusApplied   PriceChangeStatus = "Applied"
+	PriceChangeStatusCancelled PriceChangeStatus = "Cancelled"
+)
+
+// PriceChange запись истории цены товара: применённое изменение или запланированное
+// на EffectiveFrom
+type PriceChange struct {
+	ID            int64             `json:"id"`
+	ProductID     int64             `json:"product_id"`
+	Price         float64           `json:"price"`
+	Status        PriceChangeStatus `json:"status"`
+	EffectiveFrom time.Time         `json:"effective_from"`
+	CreatedAt     time.Time         `json:"created_at"`
+	AppliedAt     *time.Time        `json:"applied_at,omitempty"`
+}
+
+// PromotionKind вид акции
+type PromotionKind string
+
+const (
+	// PromotionKindPercent скидка Percent процентов
+	PromotionKindPercent PromotionKind = "percent"
+	// PromotionKindFixed скидка Amount с каждой единицы
+	PromotionKindFixed PromotionKind = "fixed"
+	// PromotionKindBuyGet из каждых BuyQty+FreeQty единиц FreeQty бесплатно
+	PromotionKindBuyGet PromotionKind = "buy_get"
+)
+
+// Promotion правило скидки. Без ProductIDs и CategoryIDs действует на весь каталог,
+// категория включает подкатегории. Акция с Code применяется только по промокоду.
+type Promotion struct {
+	ID      int64         `json:"id"`
+	Name    string        `json:"name"`
+	Kind    PromotionKind `json:"kind"`
+	Percent float64       `json:"percent,omitempty"`
+	Amount  float64       `json:"amount,omitempty"`
+	BuyQty  int64         `json:"buy_qty,omitempty"`
+	FreeQty int64         `json:"free_qty,omitempty"`
+	Code    string        `json:"code,omitempty"`
+	// Priority акции с большим приоритетом считаются раньше, следующие — от остатка суммы
+	Priority int `json:"priority"`
+	// Exclusive не суммируется: не применяется к строке с другой скидкой и закрывает её для следующих
+	Exclusive   bool       `json:"exclusive"`
+	ProductIDs  []int64    `json:"product_ids"`
+	CategoryIDs []int64    `json:"category_ids"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ActiveAt true, если акция действует в момент t
+func (p Promotion) ActiveAt(t time.Time) bool {
+	return (p.StartsAt == nil || !t.Before(*p.StartsAt)) && (p.EndsAt == nil || t.Before(*p.EndsAt))
+}
+
+// PaymentStatus статус оплаты заказа
+type PaymentStatus string
+
+const (
+	// PaymentStatusPending оплата начата, ответа провайдера ещё нет
+	PaymentStatusPending           PaymentStatus = "Pending"
+	PaymentStatusAuthorized        PaymentStatus = "Authorized"
+	PaymentStatusCaptured          PaymentStatus = "Captured"
+	PaymentStatusPartiallyRefunded PaymentStatus = "PartiallyRefunded"
+	PaymentStatusRefunded          PaymentStatus = "Refunded"
+	PaymentStatusVoided            PaymentStatus = "Voided"
+	PaymentStatusFailed            PaymentStatus = "Failed"
+)
+
+// Payment оплата заказа у платёжного провайдера: Amount заблокирована (авторизована),
+// Captured списана, Refunded возвращена из списанной
+type Payment struct {
+	ID       int64  `json:"id"`
+	OrderID  int64  `json:"order_id"`
+	Provider string `json:"provider"`
+	// ProviderRef идентификатор авторизации у провайдера
+	ProviderRef string `json:"provider_ref,omitempty"`
+	// AccountID счёт подарочной карты или депозита, если оплачено с него
+	AccountID int64         `json:"account_id,omitempty"`
+	Amount    float64       `json:"amount"`
+	Captured  float64       `json:"captured"`
+	Refunded  float64       `json:"refunded"`
+	Status    PaymentStatus `json:"status"`
+	// Error причина отказа провайдера или ошибка последней операции
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Active true, пока по оплате есть заблокированные или списанные и не возвращённые деньги
+func (p Payment) Active() bool {
+	switch p.Status {
+	case PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusPartiallyRefunded:
+		return true
+	}
+	return false
+}
+
+// RefundKind операция с заказом, по которой рассчитан возврат денег
+type RefundKind string
+
+const (
+	RefundKindCancel RefundKind = "cancel"
+	// RefundKindReturn покупатель вернул полученный товар
+	RefundKindReturn RefundKind = "return"
+	// RefundKindPartialCancel часть заказа не выполнена магазином и снята с заказа
+	RefundKindPartialCancel RefundKind = "partial_cancel"
+	// RefundKindEdit сумма заказа уменьшилась после изменения строк; строк у возврата нет
+	RefundKindEdit RefundKind = "edit"
+)
+
+// RefundStatus статус возврата денег
+type RefundStatus string
+
+const (
+	// RefundStatusPending сумма рассчитана, деньги ещё не возвращены
+	RefundStatusPending RefundStatus = "Pending"
+	// RefundStatusCompleted деньги возвращены через платёжного провайдера
+	RefundStatusCompleted RefundStatus = "Completed"
+	// RefundStatusNotRequired по заказу ничего не списано: оплаты не было или она только заблокирована
+	RefundStatusNotRequired RefundStatus = "NotRequired"
+	RefundStatusFailed      RefundStatus = "Failed"
+)
+
+// RefundLine возвращаемая часть строки заказа по ценам, скидкам и налогу на момент заказа
+type RefundLine struct {
+	ProductID int64   `json:"product_id"`
+	Quantity  int64   `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	// Amount сумма по цене без скидки, Total — к возврату (Amount - Discount)
+	Amount   float64 `json:"amount"`
+	Discount float64 `json:"discount"`
+	Tax      float64 `json:"tax"`
+	Total    float64 `json:"total"`
+}
+
+// RefundMethod куда возвращаются деньги
+type RefundMethod string
+
+const (
+	// RefundMethodOriginal на оплаты заказа: провайдеру и на счета, с которых платили
+	RefundMethodOriginal RefundMethod = "original"
+	// RefundMethodStoreCredit на депозит покупателя в магазине
+	RefundMethodStoreCredit RefundMethod = "store_credit"
+)
+
+// Refund возврат денег по отмене, частичной отмене или частичному возврату заказа
+type Refund struct {
+	ID      int64        `json:"id"`
+	OrderID int64        `json:"order_id"`
+	Kind    RefundKind   `json:"kind"`
+	Method  RefundMethod `json:"method"`
+	// Reason причина частичной отмены
+	Reason string       `json:"reason,omitempty"`
+	Lines  []RefundLine `json:"lines"`
+	// Amount сумма к возврату, Tax — включённый в неё НДС
+	Amount float64 `json:"amount"`
+	Tax    float64 `json:"tax"`
+	// Refunded фактически возвращено: не больше оплаченного сверх новой суммы заказа
+	Refunded float64      `json:"refunded"`
+	Status   RefundStatus `json:"status"`
+	// PaymentID последняя оплата, по которой возвращены деньги; AccountID — депозит при возврате на него
+	PaymentID int64     `json:"payment_id,omitempty"`
+	AccountID int64     `json:"account_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RefundKindSummary итоги возвратов одного вида за период
+type RefundKindSummary struct {
+	Kind    RefundKind `json:"kind"`
+	Refunds int        `json:"refunds"`
+	Units   int64      `json:"units"`
+	Amount  float64    `json:"amount"`
+	Tax     float64    `json:"tax"`
+}
+
+// RefundReasonSummary итоги частичных отмен по одной причине
+type RefundReasonSummary struct {
+	Reason  string  `json:"reason"`
+	Refunds int     `json:"refunds"`
+	Units   int64   `json:"units"`
+	Amount  float64 `json:"amount"`
+}
+
+// RefundSummary сводка возвратов, рассчитанных в периоде [From, To): возвраты
+// покупателей и отмены магазина считаются отдельно
+type RefundSummary struct {
+	From  time.Time           `json:"from"`
+	To    time.Time           `json:"to"`
+	Kinds []RefundKindSummary `json:"kinds"`
+	// CancelReasons частичные отмены в разрезе причин
+	CancelReasons []RefundReasonSummary `json:"cancel_reasons"`
+}
+
+// CreditAccountKind вид предоплаченного счёта
+type CreditAccountKind string
+
+const (
+	CreditAccountGiftCard CreditAccountKind = "gift_card"
+	// CreditAccountStoreCredit депозит покупателя, один на покупателя
+	CreditAccountStoreCredit CreditAccountKind = "store_credit"
+)
+
+// CreditAccount подарочная карта или депозит покупателя; оплата — по Code.
+// Баланс меняется только вместе с записью в истории операций.
+type CreditAccount struct {
+	ID        int64             `json:"id"`
+	Kind      CreditAccountKind `json:"kind"`
+	Code      string            `json:"code"`
+	Customer  string            `json:"customer,omitempty"`
+	Balance   float64           `json:"balance"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// CreditTransactionKind вид операции по счёту
+type CreditTransactionKind string
+
+const (
+	// CreditTransactionIssue пополнение: выпуск карты или начисление депозита
+	CreditTransactionIssue CreditTransactionKind = "issue"
+	// CreditTransactionPayment оплата заказа
+	CreditTransactionPayment CreditTransactionKind = "payment"
+	// CreditTransactionRefund возврат денег по заказу на счёт
+	CreditTransactionRefund CreditTransactionKind = "refund"
+)
+
+// CreditTransaction неизменяемая запись истории счёта. Amount со знаком,
+// Balance — баланс после операции.
+type CreditTransaction struct {
+	ID        int64                 `json:"id"`
+	AccountID int64                 `json:"account_id"`
+	Kind      CreditTransactionKind `json:"kind"`
+	Amount    float64               `json:"amount"`
+	Balance   float64               `json:"balance"`
+	OrderID   int64                 `json:"order_id,omitempty"`
+	PaymentID int64                 `json:"payment_id,omitempty"`
+	RefundID  int64                 `json:"refund_id,omitempty"`
+	Note      string                `json:"note,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// LoyaltyRules правила программы лояльности; нулевые значения отключают начисление и списание
+type LoyaltyRules struct {
+	// EarnRate баллов за единицу суммы заказа к оплате, с округлением вниз
+	EarnRate float64 `json:"earn_rate"`
+	// MinOrderTotal минимальная сумма заказа, за которую начисляются баллы
+	MinOrderTotal float64 `json:"min_order_total"`
+	// PointValue скидка за один балл при списании
+	PointValue float64 `json:"point_value"`
+	// MaxRedeemPercent какую часть суммы заказа можно оплатить баллами
+	MaxRedeemPercent float64 `json:"max_redeem_percent"`
+}
+
+// LoyaltyEntryKind вид операции с баллами
+type LoyaltyEntryKind string
+
+const (
+	// LoyaltyEntryEarn начисление за заказ
+	LoyaltyEntryEarn LoyaltyEntryKind = "earn"
+	// LoyaltyEntryRevoke отзыв начисленного при отмене и возврате
+	LoyaltyEntryRevoke LoyaltyEntryKind = "revoke"
+	// LoyaltyEntryRedeem списание в счёт скидки на заказ
+	LoyaltyEntryRedeem LoyaltyEntryKind = "redeem"
+	// LoyaltyEntryRestore возврат списанных баллов при отмене и возврате
+	LoyaltyEntryRestore LoyaltyEntryKind = "restore"
+)
+
+// LoyaltyEntry неизменяемая запись журнала баллов покупателя. Points со знаком,
+// Balance — баланс покупателя после операции.
+type LoyaltyEntry struct {
+	ID        int64            `json:"id"`
+	Customer  string           `json:"customer"`
+	Kind      LoyaltyEntryKind `json:"kind"`
+	Points    int64            `json:"points"`
+	Balance   int64            `json:"balance"`
+	OrderID   int64            `json:"order_id,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// LoyaltyBalance баланс баллов покупателя
+type LoyaltyBalance struct {
+	Customer string `json:"customer"`
+	Balance  int64  `json:"balance"`
+}
+
+// CartStatus статус корзины
+type CartStatus string
+
+const (
+	CartStatusOpen CartStatus = "Open"
+	// CartStatusCheckedOut корзина оформлена в заказ OrderID и больше не меняется
+	CartStatusCheckedOut CartStatus = "CheckedOut"
+)
+
+// CartItem строка корзины; цены и скидки рассчитываются при каждом чтении
+type CartItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+// Cart корзина покупателя до оформления заказа
+type Cart struct {
+	ID           int64      `json:"id"`
+	CustomerName string     `json:"customer_name"`
+	Items        []CartItem `json:"items"`
+	PromoCodes   []string   `json:"promo_codes,omitempty"`
+	Status       CartStatus `json:"status"`
+	OrderID      int64      `json:"order_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// StockWarning строка корзины, которую сейчас нельзя заказать: не хватает запаса или товар в архиве
+type StockWarning struct {
+	ProductID int64  `json:"product_id"`
+	Requested int64  `json:"requested"`
+	Available int64  `json:"available"`
+	Message   string `json:"message"`
+}
+
+// CartView корзина с текущими ценами, скидками и итогами, как если бы заказ оформлялся сейчас
+type CartView struct {
+	Cart
+	Lines    []OrderItem    `json:"lines"`
+	Subtotal float64        `json:"subtotal"`
+	Discount float64        `json:"discount"`
+	Total    float64        `json:"total"`
+	Tax      float64        `json:"tax"`
+	Warnings []StockWarning `json:"warnings"`
+}
+
+// OrderLineChange изменение количества товара при редактировании заказа:
+// Before 0 — товар добавлен, After 0 — удалён
+type OrderLineChange struct {
+	ProductID int64 `json:"product_id"`
+	Before    int64 `json:"before"`
+	After     int64 `json:"after"`
+}
+
+// OrderEdit запись журнала изменений заказа
+type OrderEdit struct {
+	ID          int64             `json:"id"`
+	OrderID     int64             `json:"order_id"`
+	Changes     []OrderLineChange `json:"changes"`
+	TotalBefore float64           `json:"total_before"`
+	TotalAfter  float64           `json:"total_after"`
+	Note        string            `json:"note,omitempty"`
+	// RefundID возврат разницы, если сумма заказа уменьшилась
+	RefundID int64 `json:"refund_id,omitempty"`
+	// PointsRestored списанные баллы, скидка за которые не поместилась в изменённый заказ
+	PointsRestored int64     `json:"points_restored,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
 }
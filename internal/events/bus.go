@@ -0,0 +1,153 @@
+// Package events — внутрипроцессная шина доменных событий.
+// Сервисы публикуют события после фиксации транзакции, подписчики
+// (уведомления, кэши, индексы) реагируют на них, не меняя сервисы.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Event доменное событие
+type Event interface {
+	EventName() string
+}
+
+// Handler обработчик события
+type Handler func(ctx context.Context, e Event)
+
+type subscription struct {
+	handler Handler
+	async   bool
+}
+
+// Bus шина событий с синхронными и асинхронными подписчиками.
+// Нулевой указатель допустим: Publish на nil-шине ничего не делает.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]subscription
+	wg   sync.WaitGroup
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]subscription)}
+}
+
+// Subscribe регистрирует синхронный обработчик: он выполняется в горутине публикующего
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.add(name, subscription{handler: h})
+}
+
+// SubscribeAsync регистрирует асинхронный обработчик: он выполняется в отдельной горутине
+// с контекстом, не зависящим от запроса
+func (b *Bus) SubscribeAsync(name string, h Handler) {
+	b.add(name, subscription{handler: h, async: true})
+}
+
+func (b *Bus) add(name string, s subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[name] = append(b.subs[name], s)
+}
+
+// Publish доставляет события подписчикам в порядке подписки.
+// Паника обработчика логируется и не прерывает доставку остальным.
+func (b *Bus) Publish(ctx context.Context, evts ...Event) {
+	if b == nil {
+		return
+	}
+	if d, ok := ctx.Value(deferKey{}).(*deferred); ok {
+		d.add(b, evts)
+		return
+	}
+	for _, e := range evts {
+		b.mu.RLock()
+		subs := append([]subscription(nil), b.subs[e.EventName()]...)
+		b.mu.RUnlock()
+		for _, s := range subs {
+			if s.async {
+				b.wg.Add(1)
+				go func(h Handler, e Event) {
+					defer b.wg.Done()
+					dispatch(context.Background(), h, e)
+				}(s.handler, e)
+				continue
+			}
+			dispatch(ctx, s.handler, e)
+		}
+	}
+}
+
+type deferKey struct{}
+
+type deferredBatch struct {
+	bus  *Bus
+	evts []Event
+}
+
+type deferred struct {
+	mu      sync.Mutex
+	batches []deferredBatch
+}
+
+func (d *deferred) add(b *Bus, evts []Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.batches = append(d.batches, deferredBatch{bus: b, evts: evts})
+}
+
+// Defer возвращает контекст, в котором Publish копит события вместо доставки, и
+// функцию, доставляющую накопленное с исходным контекстом. Нужен, когда сервисы
+// вызываются внутри общей транзакции: подписчики увидят события только после
+// её фиксации, а при ошибке flush не вызывается и события отбрасываются.
+func Defer(ctx context.Context) (context.Context, func()) {
+	d := &deferred{}
+	flush := func() {
+		d.mu.Lock()
+		batches := d.batches
+		d.batches = nil
+		d.mu.Unlock()
+		for _, bt := range batches {
+			bt.bus.Publish(ctx, bt.evts...)
+		}
+	}
+	return context.WithValue(ctx, deferKey{}, d), flush
+}
+
+// Wait дожидается завершения запущенных асинхронных обработчиков
+func (b *Bus) Wait() {
+	if b == nil {
+		return
+	}
+	b.wg.Wait()
+}
+
+func dispatch(ctx context.Context, h Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("event handler panic on %s: %v", e.EventName(), r)
+		}
+	}()
+	h(ctx, e)
+}
+
+// On подписывает типизированный синхронный обработчик на событие типа E
+func On[E Event](b *Bus, fn func(ctx context.Context, e E)) {
+	var zero E
+	b.Subscribe(zero.EventName(), typed(fn))
+}
+
+// OnAsync подписывает типизированный асинхронный обработчик на событие типа E
+func OnAsync[E Event](b *Bus, fn func(ctx context.Context, e E)) {
+	var zero E
+	b.SubscribeAsync(zero.EventName(), typed(fn))
+}
+
+func typed[E Event](fn func(ctx context.Context, e E)) Handler {
+	return func(ctx context.Context, e Event) {
+		if ev, ok := e.(E); ok {
+			fn(ctx, ev)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestBus_SyncHandlersInOrder(t *testing.T) {
+	ctx := context.Background()
+	b := NewBus()
+	var got []string
+	b.Subscribe(NameProductCreated, func(ctx context.Context, e Event) { got = append(got, "first") })
+	b.Subscribe(NameProductCreated, func(ctx context.Context, e Event) { got = append(got, "second") })
+	b.Subscribe(NameProductDeleted, func(ctx context.Context, e Event) { got = append(got, "other") })
+
+	b.Publish(ctx, ProductCreated{Product: domain.Product{ID: 1}})
+	if len(got) != 2 || got[0] != "first" || got[1] != "second" {
+		t.Fatalf("unexpected handlers: %v", got)
+	}
+}
+
+func TestBus_TypedAndAsync(t *testing.T) {
+	ctx := context.Background()
+	b := NewBus()
+	var syncID, asyncCount int64
+	On(b, func(ctx context.Context, e OrderCreated) { syncID = e.Order.ID })
+	OnAsync(b, func(ctx context.Context, e OrderCreated) { atomic.AddInt64(&asyncCount, 1) })
+
+	b.Publish(ctx, OrderCreated{Order: domain.Order{ID: 7}}, OrderCreated{Order: domain.Order{ID: 8}})
+	b.Wait()
+	if syncID != 8 {
+		t.Fatalf("sync handler expected last id 8, got %v", syncID)
+	}
+	if atomic.LoadInt64(&asyncCount) != 2 {
+		t.Fatalf("async handler expected 2 calls, got %v", asyncCount)
+	}
+}
+
+func TestBus_PanicDoesNotStopDelivery(t *testing.T) {
+	ctx := context.Background()
+	b := NewBus()
+	called := false
+	b.Subscribe(NameOrderCancelled, func(ctx context.Context, e Event) { panic("boom") })
+	b.Subscribe(NameOrderCancelled, func(ctx context.Context, e Event) { called = true })
+	b.Publish(ctx, OrderCancelled{})
+	if !called {
+		t.Fatalf("second handler not called")
+	}
+}
+
+func TestBus_NilIsNoop(t *testing.T) {
+	var b *Bus
+	b.Publish(context.Background(), ProductDeleted{ProductID: 1})
+	b.Wait()
+}
+
+func TestBus_DeferUntilFlush(t *testing.T) {
+	b := NewBus()
+	var got []int64
+	On(b, func(ctx context.Context, e OrderCreated) { got = append(got, e.Order.ID) })
+
+	ctx, flush := Defer(context.Background())
+	b.Publish(ctx, OrderCreated{Order: domain.Order{ID: 1}})
+	b.Publish(ctx, OrderCreated{Order: domain.Order{ID: 2}})
+	if len(got) != 0 {
+		t.Fatalf("expected delivery deferred, got %v", got)
+	}
+	flush()
+	flush()
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected events in order once, got %v", got)
+	}
+}
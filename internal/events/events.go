@@ -0,0 +1,101 @@
+package events
+
+import "april/internal/domain"
+
+// Имена доменных событий
+const (
+	NameProductCreated      = "product.created"
+	NameProductUpdated      = "product.updated"
+	NameProductDeleted      = "product.deleted"
+	NameOrderCreated        = "order.created"
+	NameOrderCancelled      = "order.cancelled"
+	NameOrderReturned       = "order.returned"
+	NameOrderEdited         = "order.edited"
+	NameOrderLinesCancelled = "order.lines_cancelled"
+	NameGoodsReceived       = "purchase.received"
+
+	NameStocktakeApproved = "stocktake.approved"
+)
+
+// ProductCreated публикуется после создания товара
+type ProductCreated struct {
+	Product domain.Product
+}
+
+func (ProductCreated) EventName() string { return NameProductCreated }
+
+// ProductUpdated публикуется после изменения товара, Previous — состояние до изменения
+type ProductUpdated struct {
+	Previous domain.Product
+	Product  domain.Product
+}
+
+func (ProductUpdated) EventName() string { return NameProductUpdated }
+
+// ProductDeleted публикуется после удаления товара
+type ProductDeleted struct {
+	ProductID int64
+}
+
+func (ProductDeleted) EventName() string { return NameProductDeleted }
+
+// OrderCreated публикуется после создания заказа и списания запаса
+type OrderCreated struct {
+	Order domain.Order
+}
+
+func (OrderCreated) EventName() string { return NameOrderCreated }
+
+// OrderCancelled публикуется после отмены заказа и возврата запаса; Refund — рассчитанный возврат денег
+type OrderCancelled struct {
+	Order  domain.Order
+	Refund domain.Refund
+}
+
+func (OrderCancelled) EventName() string { return NameOrderCancelled }
+
+// OrderReturned публикуется после частичного возврата, Returned — возвращённые позиции,
+// Refund — рассчитанный возврат денег
+type OrderReturned struct {
+	Order    domain.Order
+	Returned []domain.OrderItem
+	Refund   domain.Refund
+}
+
+func (OrderReturned) EventName() string { return NameOrderReturned }
+
+// OrderEdited публикуется после редактирования строк заказа и пересчёта запаса;
+// Refund — возврат разницы, если сумма уменьшилась (иначе нулевой)
+type OrderEdited struct {
+	Order  domain.Order
+	Edit   domain.OrderEdit
+	Refund domain.Refund
+}
+
+func (OrderEdited) EventName() string { return NameOrderEdited }
+
+// OrderLinesCancelled публикуется после частичной отмены: Cancelled — снятые
+// магазином позиции, Refund — рассчитанный возврат денег
+type OrderLinesCancelled struct {
+	Order     domain.Order
+	Cancelled []domain.OrderItem
+	Refund    domain.Refund
+	Reason    string
+}
+
+func (OrderLinesCancelled) EventName() string { return NameOrderLinesCancelled }
+
+// GoodsReceived публикуется после приёмки товара по заказу поставщику
+type GoodsReceived struct {
+	PurchaseOrder domain.PurchaseOrder
+	Lines         []domain.ReceiptLine
+}
+
+func (GoodsReceived) EventName() string { return NameGoodsReceived }
+
+// StocktakeApproved публикуется после утверждения инвентаризации и корректировки запаса
+type StocktakeApproved struct {
+	Stocktake domain.Stocktake
+}
+
+func (StocktakeApproved) EventName() string { return NameStocktakeApproved }
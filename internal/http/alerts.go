@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List low-stock alerts
+// @Tags alerts
+// @Produce json
+// @Param status query string false "open (default) or all"
+// @Success 200 {array} domain.LowStockAlert
+// @Failure 400 {object} map[string]string
+// @Router /alerts/low-stock [get]
+func (s *Server) listLowStockAlerts(c *gin.Context) {
+	openOnly := true
+	switch c.DefaultQuery("status", "open") {
+	case "open":
+	case "all":
+		openOnly = false
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	list, err := s.alerts.ListLowStock(c, openOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestLowStockAlerts(t *testing.T) {
+	store := repository.NewMemoryStore()
+	bus := events.NewBus()
+	productsSvc := service.NewProductService(store, repository.NewMemoryTx(store))
+	productsSvc.SetEvents(bus)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), repository.NewMemoryTx(store))
+	ordersSvc.SetEvents(bus)
+	alertsSvc := service.NewAlertService(store, repository.NewMemoryAlerts(store), nil)
+	alertsSvc.Subscribe(bus)
+	s := NewServer(productsSvc, ordersSvc, WithAlerts(alertsSvc))
+
+	w := doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Aspirin", "sku": "S1", "price": 10, "stock": 5, "reorder_point": 2, "reorder_quantity": 10,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create product %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John",
+		"items":         []map[string]any{{"product_id": 1, "quantity": 4}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create order %v", w.Code)
+	}
+	bus.Wait()
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/alerts/low-stock", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list alerts %v", w.Code)
+	}
+	var list []domain.LowStockAlert
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ProductID != 1 || list[0].Stock != 1 {
+		t.Fatalf("unexpected alerts %+v", list)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/alerts/low-stock?status=bogus", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", w.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestBarcodeFlow(t *testing.T) {
+	s := setupServer(t)
+
+	w := doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Aspirin", "sku": "S1", "price": 10, "stock": 5, "barcodes": []string{"4006381333931"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Other", "sku": "S2", "price": 1, "barcodes": []string{"4006381333931"},
+	})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Other", "sku": "S2", "price": 1, "barcodes": []string{"4006381333930"},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 on checksum, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products/by-barcode/4006381333931", nil)
+	var p domain.Product
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &p) != nil || p.ID != 1 {
+		t.Fatalf("by barcode %v %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/by-barcode/96385074", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/by-barcode/123", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "A", "items": []map[string]any{{"barcode": "4006381333931", "quantity": 2}},
+	})
+	var o domain.Order
+	if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &o) != nil || o.Items[0].ProductID != 1 {
+		t.Fatalf("order by barcode %v %s", w.Code, w.Body.String())
+	}
+}
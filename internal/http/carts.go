@@ -0,0 +1,286 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/service"
+)
+
+type createCartReq struct {
+	CustomerName string `json:"customer_name"`
+}
+
+type cartItemReq struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+type cartQtyReq struct {
+	Quantity int64 `json:"quantity"`
+}
+
+type cartPromoReq struct {
+	Code string `json:"code"`
+}
+
+type checkoutReq struct {
+	SuggestSubstitutes bool  `json:"suggest_substitutes"`
+	LoyaltyPoints      int64 `json:"loyalty_points"`
+}
+
+// @Summary Create cart
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param input body createCartReq true "Cart"
+// @Success 201 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Router /carts [post]
+func (s *Server) createCart(c *gin.Context) {
+	var req createCartReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cart, err := s.carts.Create(c, req.CustomerName)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, cart)
+}
+
+// @Summary Get cart
+// @Description Lines are priced with current prices and promotions; warnings list lines that cannot be ordered now
+// @Tags carts
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Success 200 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /carts/{id} [get]
+func (s *Server) getCart(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	cart, err := s.carts.Get(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Add cart item
+// @Description Adds quantity to the product line, creating it if needed
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Param input body cartItemReq true "Item"
+// @Success 200 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /carts/{id}/items [post]
+func (s *Server) addCartItem(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req cartItemReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cart, err := s.carts.AddItem(c, id, req.ProductID, req.Quantity)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Set cart item quantity
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Param product_id path int true "Product ID"
+// @Param input body cartQtyReq true "Quantity"
+// @Success 200 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /carts/{id}/items/{product_id} [put]
+func (s *Server) setCartItem(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	productID, err := parseID(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product_id"})
+		return
+	}
+	var req cartQtyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cart, err := s.carts.SetItem(c, id, productID, req.Quantity)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Remove cart item
+// @Tags carts
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Param product_id path int true "Product ID"
+// @Success 200 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /carts/{id}/items/{product_id} [delete]
+func (s *Server) removeCartItem(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	productID, err := parseID(c.Param("product_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product_id"})
+		return
+	}
+	cart, err := s.carts.RemoveItem(c, id, productID)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Apply promo code to cart
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Param input body cartPromoReq true "Promo code"
+// @Success 200 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /carts/{id}/promo-codes [post]
+func (s *Server) addCartPromoCode(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req cartPromoReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cart, err := s.carts.AddPromoCode(c, id, req.Code)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Remove promo code from cart
+// @Tags carts
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Param code path string true "Promo code"
+// @Success 200 {object} domain.CartView
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /carts/{id}/promo-codes/{code} [delete]
+func (s *Server) removeCartPromoCode(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	cart, err := s.carts.RemovePromoCode(c, id, c.Param("code"))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Checkout cart
+// @Description Creates an order from the cart lines and promo codes and closes the cart in one transaction
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param id path int true "Cart ID"
+// @Param input body checkoutReq false "Checkout options"
+// @Success 201 {object} domain.Order
+// @Failure 400 {object} map[string]any
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /carts/{id}/checkout [post]
+func (s *Server) checkoutCart(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req checkoutReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+			return
+		}
+	}
+	var opts []service.CreateOrderOption
+	if req.SuggestSubstitutes {
+		opts = append(opts, service.WithSubstituteSuggestions())
+	}
+	if req.LoyaltyPoints != 0 {
+		opts = append(opts, service.WithLoyaltyPoints(req.LoyaltyPoints))
+	}
+	o, err := s.carts.Checkout(c, id, opts...)
+	if err != nil {
+		var stockErr *service.StockError
+		if errors.As(err, &stockErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":       err.Error(),
+				"product_id":  stockErr.ProductID,
+				"requested":   stockErr.Requested,
+				"available":   stockErr.Available,
+				"substitutes": stockErr.Substitutes,
+			})
+			return
+		}
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, o)
+}
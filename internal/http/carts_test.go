@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestCarts(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	cartsSvc := service.NewCartService(repository.NewMemoryCarts(store), store, ordersSvc, tx)
+	s := NewServer(service.NewProductService(store, tx), ordersSvc, WithCarts(cartsSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 10, "stock": 5})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "B", "sku": "S2", "price": 7, "stock": 0})
+
+	if w := doJSON(t, s, http.MethodPost, "/api/v1/carts", map[string]any{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without customer, got %v", w.Code)
+	}
+	w := doJSON(t, s, http.MethodPost, "/api/v1/carts", map[string]any{"customer_name": "John"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create %v %s", w.Code, w.Body.String())
+	}
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/carts/1/items", map[string]any{"product_id": 1, "quantity": 1})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/carts/1/items", map[string]any{"product_id": 2, "quantity": 1})
+	w = doJSON(t, s, http.MethodPut, "/api/v1/carts/1/items/1", map[string]any{"quantity": 3})
+	var view domain.CartView
+	_ = json.Unmarshal(w.Body.Bytes(), &view)
+	if w.Code != http.StatusOK || view.Total != 37 || len(view.Warnings) != 1 || view.Warnings[0].ProductID != 2 {
+		t.Fatalf("set item %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/carts/1/promo-codes", map[string]any{"code": "X"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without promotions, got %v", w.Code)
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/carts/1/checkout", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 on missing stock, got %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodDelete, "/api/v1/carts/1/items/2", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &view)
+	if w.Code != http.StatusOK || len(view.Items) != 1 || len(view.Warnings) != 0 {
+		t.Fatalf("remove %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodDelete, "/api/v1/carts/1/items/2", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing line, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/carts/1/checkout", nil)
+	var o domain.Order
+	_ = json.Unmarshal(w.Body.Bytes(), &o)
+	if w.Code != http.StatusCreated || o.Total != 30 || o.CustomerName != "John" {
+		t.Fatalf("checkout %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/carts/1", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &view)
+	if view.Status != domain.CartStatusCheckedOut || view.OrderID != o.ID {
+		t.Fatalf("cart after checkout %s", w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/carts/1/checkout", nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on second checkout, got %v", w.Code)
+	}
+}
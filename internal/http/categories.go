@@ -0,0 +1,262 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+)
+
+type createCategoryReq struct {
+	Name       string                `json:"name"`
+	Slug       string                `json:"slug"`
+	ParentID   int64                 `json:"parent_id"`
+	Attributes []domain.AttributeDef `json:"attributes"`
+}
+
+// @Summary Create category
+// @Description Appends the category to its parent's children; slug is derived from name when empty
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param input body createCategoryReq true "Category"
+// @Success 201 {object} domain.Category
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /categories [post]
+func (s *Server) createCategory(c *gin.Context) {
+	var req createCategoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cat, err := s.categories.Create(c, domain.Category{
+		Name: req.Name, Slug: req.Slug, ParentID: req.ParentID, Attributes: req.Attributes,
+	})
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, cat)
+}
+
+// @Summary Category tree
+// @Tags categories
+// @Produce json
+// @Success 200 {array} domain.CategoryNode
+// @Router /categories [get]
+func (s *Server) categoryTree(c *gin.Context) {
+	tree, err := s.categories.Tree(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// @Summary Get category by id
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /categories/{id} [get]
+func (s *Server) getCategory(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	cat, err := s.categories.Get(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+type updateCategoryReq struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// @Summary Update category
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param input body updateCategoryReq true "Category"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /categories/{id} [put]
+func (s *Server) updateCategory(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req updateCategoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cat, err := s.categories.Update(c, domain.Category{ID: id, Name: req.Name, Slug: req.Slug})
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+// @Summary Delete category
+// @Description Only categories without children and products can be deleted
+// @Tags categories
+// @Param id path int true "Category ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /categories/{id} [delete]
+func (s *Server) deleteCategory(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := s.categories.Delete(c, id); err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type moveCategoryReq struct {
+	ParentID int64 `json:"parent_id"`
+	Position int   `json:"position"`
+}
+
+// @Summary Move category
+// @Description Moves the category under parent_id (0 for root) at the given position
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param input body moveCategoryReq true "Target"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /categories/{id}/move [post]
+func (s *Server) moveCategory(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req moveCategoryReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cat, err := s.categories.Move(c, id, req.ParentID, req.Position)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+type reorderCategoriesReq struct {
+	ParentID    int64   `json:"parent_id"`
+	CategoryIDs []int64 `json:"category_ids"`
+}
+
+// @Summary Reorder children of a category
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param input body reorderCategoriesReq true "New order of all children"
+// @Success 200 {array} domain.CategoryNode
+// @Failure 400 {object} map[string]string
+// @Router /categories/reorder [post]
+func (s *Server) reorderCategories(c *gin.Context) {
+	var req reorderCategoriesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	tree, err := s.categories.Reorder(c, req.ParentID, req.CategoryIDs)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// @Summary Effective attribute schema of category
+// @Description Includes attributes inherited from ancestors
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {array} domain.AttributeDef
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /categories/{id}/attributes [get]
+func (s *Server) categorySchema(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	defs, err := s.categories.Schema(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, defs)
+}
+
+type setCategoryAttributesReq struct {
+	Attributes []domain.AttributeDef `json:"attributes"`
+}
+
+// @Summary Replace attribute schema of category
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param input body setCategoryAttributesReq true "Attribute definitions"
+// @Success 200 {object} domain.Category
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /categories/{id}/attributes [put]
+func (s *Server) setCategoryAttributes(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req setCategoryAttributesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	cat, err := s.categories.SetAttributes(c, id, req.Attributes)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestCategoryFlow(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	categoriesRepo := repository.NewMemoryCategories(store)
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetCategories(categoriesRepo)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	s := NewServer(productsSvc, ordersSvc, WithCategories(service.NewCategoryService(categoriesRepo, store, tx)))
+
+	w := doJSON(t, s, http.MethodPost, "/api/v1/categories", map[string]any{"name": "Лекарства"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create category %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/categories", map[string]any{"name": "Cold", "parent_id": 1})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create child %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/categories", map[string]any{"name": "Dup", "slug": "cold"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on duplicate slug, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Theraflu", "sku": "S1", "price": 10, "stock": 1, "category_ids": []int64{2},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create product %v", w.Code)
+	}
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "Other", "sku": "S2", "price": 1, "stock": 1})
+
+	for _, ref := range []string{"1", "lekarstva", "cold"} {
+		w = doJSON(t, s, http.MethodGet, "/api/v1/products?category="+ref, nil)
+		var list []domain.Product
+		_ = json.Unmarshal(w.Body.Bytes(), &list)
+		if w.Code != http.StatusOK || len(list) != 1 {
+			t.Fatalf("filter by %s: %v %d", ref, w.Code, len(list))
+		}
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products?category=missing", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown category, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/categories/2/move", map[string]any{"parent_id": 0, "position": 0})
+	if w.Code != http.StatusOK {
+		t.Fatalf("move %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/categories/reorder", map[string]any{"parent_id": 0, "category_ids": []int64{1, 2}})
+	var tree []domain.CategoryNode
+	_ = json.Unmarshal(w.Body.Bytes(), &tree)
+	if w.Code != http.StatusOK || len(tree) != 2 || tree[0].ID != 1 {
+		t.Fatalf("reorder %v %+v", w.Code, tree)
+	}
+	w = doJSON(t, s, http.MethodDelete, "/api/v1/categories/2", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 deleting non-empty category, got %v", w.Code)
+	}
+}
+
+func TestCategoryAttributes(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	categoriesRepo := repository.NewMemoryCategories(store)
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetCategories(categoriesRepo)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	s := NewServer(productsSvc, ordersSvc, WithCategories(service.NewCategoryService(categoriesRepo, store, tx)))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/categories", map[string]any{"name": "Meds"})
+	w := doJSON(t, s, http.MethodPut, "/api/v1/categories/1/attributes", map[string]any{
+		"attributes": []map[string]any{
+			{"code": "manufacturer", "name": "Manufacturer", "type": "string", "required": true},
+			{"code": "country", "name": "Country", "type": "string"},
+		},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("set attributes %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Aspirin", "sku": "S1", "price": 1, "stock": 1, "category_ids": []int64{1},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without required attribute, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Aspirin", "sku": "S1", "price": 1, "stock": 1, "category_ids": []int64{1},
+		"attributes": map[string]string{"manufacturer": "Bayer", "country": "DE"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create product %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products?attr.country=de&attr.manufacturer=Bayer", nil)
+	var list []domain.Product
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 {
+		t.Fatalf("attribute filter %v %d", w.Code, len(list))
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products?attr.country=FR", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 0 {
+		t.Fatalf("expected empty list, got %d", len(list))
+	}
+}
@@ -0,0 +1,142 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+type giftCardReq struct {
+	// Code номер карты; пустой генерируется
+	Code   string  `json:"code"`
+	Amount float64 `json:"amount"`
+	Note   string  `json:"note"`
+}
+
+type storeCreditReq struct {
+	Customer string  `json:"customer"`
+	Amount   float64 `json:"amount"`
+	Note     string  `json:"note"`
+}
+
+// @Summary Issue gift card
+// @Description Creates a gift card with the initial balance; the code is generated when empty
+// @Tags credits
+// @Accept json
+// @Produce json
+// @Param input body giftCardReq true "Gift card"
+// @Success 201 {object} domain.CreditAccount
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /gift-cards [post]
+func (s *Server) issueGiftCard(c *gin.Context) {
+	var req giftCardReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	a, err := s.credits.IssueGiftCard(c, req.Code, req.Amount, req.Note)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, a)
+}
+
+// @Summary Add store credit
+// @Description Credits the customer's store credit account, opening it on the first credit
+// @Tags credits
+// @Accept json
+// @Produce json
+// @Param input body storeCreditReq true "Store credit"
+// @Success 200 {object} domain.CreditAccount
+// @Failure 400 {object} map[string]string
+// @Router /store-credit [post]
+func (s *Server) addStoreCredit(c *gin.Context) {
+	var req storeCreditReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	a, err := s.credits.AddStoreCredit(c, req.Customer, req.Amount, req.Note)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// @Summary List credit accounts
+// @Tags credits
+// @Produce json
+// @Param kind query string false "gift_card or store_credit"
+// @Param code query string false "Account code"
+// @Param customer query string false "Customer name"
+// @Success 200 {array} domain.CreditAccount
+// @Failure 400 {object} map[string]string
+// @Router /credit-accounts [get]
+func (s *Server) listCreditAccounts(c *gin.Context) {
+	list, err := s.credits.List(c, repository.CreditAccountFilter{
+		Kind:     domain.CreditAccountKind(c.Query("kind")),
+		Code:     c.Query("code"),
+		Customer: c.Query("customer"),
+	})
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get credit account by id
+// @Tags credits
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {object} domain.CreditAccount
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /credit-accounts/{id} [get]
+func (s *Server) getCreditAccount(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	a, err := s.credits.Get(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// @Summary Credit account transactions
+// @Description Immutable history of issues, payments and refunds with the balance after each
+// @Tags credits
+// @Produce json
+// @Param id path int true "Account ID"
+// @Success 200 {array} domain.CreditTransaction
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /credit-accounts/{id}/transactions [get]
+func (s *Server) creditTransactions(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.credits.Transactions(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestCredits(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	ordersRepo := repository.NewMemoryOrders(store)
+	ordersSvc := service.NewOrderService(store, ordersRepo, tx)
+	ordersSvc.SetEvents(bus)
+	paymentsSvc := service.NewPaymentService(ordersRepo, repository.NewMemoryPayments(store), service.NewFakePaymentProvider(), tx)
+	refundsRepo := repository.NewMemoryRefunds(store)
+	ordersSvc.SetRefunds(refundsRepo)
+	paymentsSvc.SetRefunds(refundsRepo)
+	paymentsSvc.Subscribe(bus)
+	creditsSvc := service.NewCreditService(repository.NewMemoryCredits(store), tx)
+	paymentsSvc.SetCredits(creditsSvc)
+	s := NewServer(service.NewProductService(store, tx), ordersSvc, WithPayments(paymentsSvc), WithCredits(creditsSvc))
+
+	w := doJSON(t, s, http.MethodPost, "/api/v1/gift-cards", map[string]any{"code": "gift-1", "amount": 12})
+	var card domain.CreditAccount
+	_ = json.Unmarshal(w.Body.Bytes(), &card)
+	if w.Code != http.StatusCreated || card.Code != "GIFT-1" || card.Balance != 12 {
+		t.Fatalf("issue %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/gift-cards", map[string]any{"code": "GIFT-1", "amount": 5}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for taken code, got %v", w.Code)
+	}
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 10, "stock": 5})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John", "items": []map[string]any{{"product_id": 1, "quantity": 3}},
+	})
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/pay", map[string]any{"credit_code": "gift-1"})
+	var p domain.Payment
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusCreated || p.Captured != 12 || p.AccountID != card.ID {
+		t.Fatalf("pay with card %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/pay", map[string]any{"token": "tok_visa"})
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusCreated || p.Captured != 18 {
+		t.Fatalf("pay rest %v %s", w.Code, w.Body.String())
+	}
+
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/partial-return", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}}, "refund_to": "cash",
+	}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown refund_to, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/partial-return", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}}, "refund_to": "store_credit",
+	})
+	var resp struct {
+		domain.Order
+		Refund domain.Refund `json:"refund"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Refund.Method != domain.RefundMethodStoreCredit || resp.Refund.Refunded != 10 {
+		t.Fatalf("return to store credit %v %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/credit-accounts?kind=store_credit&customer=John", nil)
+	var accounts []domain.CreditAccount
+	_ = json.Unmarshal(w.Body.Bytes(), &accounts)
+	if w.Code != http.StatusOK || len(accounts) != 1 || accounts[0].Balance != 10 {
+		t.Fatalf("list %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/credit-accounts/1/transactions", nil)
+	var txs []domain.CreditTransaction
+	_ = json.Unmarshal(w.Body.Bytes(), &txs)
+	if w.Code != http.StatusOK || len(txs) != 2 || txs[1].Balance != 0 || txs[1].OrderID != 1 {
+		t.Fatalf("transactions %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodGet, "/api/v1/credit-accounts/9", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+}
@@ -1,28 +1,112 @@
 package httpapi
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"april/internal/domain"
+	"april/internal/patch"
 	"april/internal/repository"
 	"april/internal/service"
 )
 
 type Server struct {
-	engine   *gin.Engine
-	products *service.ProductService
-	orders   *service.OrderService
+	engine     *gin.Engine
+	products   *service.ProductService
+	orders     *service.OrderService
+	alerts     *service.AlertService
+	purchases  *service.PurchaseService
+	stocktakes *service.StocktakeService
+	categories *service.CategoryService
+	serials    *service.SerialService
+	suggest    *service.SuggestService
+	prices     *service.PriceService
+	promotions *service.PromotionService
+	payments   *service.PaymentService
+	credits    *service.CreditService
+	loyalty    *service.LoyaltyService
+	carts      *service.CartService
 }
 
-func NewServer(products *service.ProductService, orders *service.OrderService) *Server {
+// Option подключает к серверу дополнительный сервис и его маршруты
+type Option func(*Server)
+
+// WithAlerts включает эндпоинты оповещений о низком запасе
+func WithAlerts(alerts *service.AlertService) Option {
+	return func(s *Server) { s.alerts = alerts }
+}
+
+// WithPurchasing включает эндпоинты поставщиков и заказов поставщикам
+func WithPurchasing(purchases *service.PurchaseService) Option {
+	return func(s *Server) { s.purchases = purchases }
+}
+
+// WithStocktakes включает эндпоинты инвентаризаций
+func WithStocktakes(stocktakes *service.StocktakeService) Option {
+	return func(s *Server) { s.stocktakes = stocktakes }
+}
+
+// WithCategories включает эндпоинты дерева категорий и фильтр товаров по категории
+func WithCategories(categories *service.CategoryService) Option {
+	return func(s *Server) { s.categories = categories }
+}
+
+// WithSerials включает эндпоинты маркированных упаковок и серий
+func WithSerials(serials *service.SerialService) Option {
+	return func(s *Server) { s.serials = serials }
+}
+
+// WithSuggestions включает автодополнение названий товаров
+func WithSuggestions(suggest *service.SuggestService) Option {
+	return func(s *Server) { s.suggest = suggest }
+}
+
+// WithPrices включает историю и расписание цен товаров
+func WithPrices(prices *service.PriceService) Option {
+	return func(s *Server) { s.prices = prices }
+}
+
+// WithPromotions включает эндпоинты акций
+func WithPromotions(promotions *service.PromotionService) Option {
+	return func(s *Server) { s.promotions = promotions }
+}
+
+// WithPayments включает оплату заказов
+func WithPayments(payments *service.PaymentService) Option {
+	return func(s *Server) { s.payments = payments }
+}
+
+// WithCredits включает подарочные карты и депозиты покупателей
+func WithCredits(credits *service.CreditService) Option {
+	return func(s *Server) { s.credits = credits }
+}
+
+// WithLoyalty включает правила, балансы и журнал баллов лояльности
+func WithLoyalty(loyalty *service.LoyaltyService) Option {
+	return func(s *Server) { s.loyalty = loyalty }
+}
+
+// WithCarts включает корзины и их оформление в заказ
+func WithCarts(carts *service.CartService) Option {
+	return func(s *Server) { s.carts = carts }
+}
+
+func NewServer(products *service.ProductService, orders *service.OrderService, opts ...Option) *Server {
 	r := gin.New()
 	r.Use(gin.Logger(), gin.Recovery())
 	s := &Server{engine: r, products: products, orders: orders}
+	for _, opt := range opts {
+		opt(s)
+	}
 	s.registerRoutes()
 	return s
 }
@@ -37,25 +121,150 @@ func (s *Server) registerRoutes() {
 	{
 		products := v1.Group("/products")
 		products.POST("", s.createProduct)
+		products.GET("by-barcode/:code", s.getProductByBarcode)
 		products.GET(":id", s.getProduct)
+		products.GET(":id/substitutes", s.productSubstitutes)
 		products.PUT(":id", s.updateProduct)
+		products.PATCH(":id", s.patchProduct)
 		products.DELETE(":id", s.deleteProduct)
+		products.POST(":id/restore", s.restoreProduct)
+		products.POST(":id/purge", s.purgeProduct)
 		products.GET("", s.listProducts)
+		if s.suggest != nil {
+			products.GET("suggest", s.suggestProducts)
+		}
+		if s.prices != nil {
+			products.GET(":id/prices", s.productPrices)
+			products.POST(":id/prices", s.schedulePrice)
+			products.DELETE(":id/prices/:change_id", s.cancelPrice)
+		}
 
 		orders := v1.Group("/orders")
 		orders.POST("", s.createOrder)
+		orders.GET("tax-summary", s.taxSummary)
+		orders.GET("refund-summary", s.refundSummary)
 		orders.GET(":id", s.getOrder)
 		orders.POST(":id/cancel", s.cancelOrder)
 		orders.POST(":id/partial-return", s.partialReturn)
+		orders.POST(":id/partial-cancel", s.partialCancel)
+		orders.GET(":id/refunds", s.orderRefunds)
+		orders.POST(":id/edit", s.editOrder)
+		orders.GET(":id/edits", s.orderEdits)
+		if s.payments != nil {
+			orders.POST(":id/pay", s.payOrder)
+			orders.GET(":id/payments", s.orderPayments)
+			payments := v1.Group("/payments")
+			payments.GET(":id", s.getPayment)
+			payments.POST(":id/capture", s.capturePayment)
+			payments.POST(":id/void", s.voidPayment)
+		}
+
+		if s.alerts != nil {
+			alerts := v1.Group("/alerts")
+			alerts.GET("/low-stock", s.listLowStockAlerts)
+		}
+
+		if s.purchases != nil {
+			suppliers := v1.Group("/suppliers")
+			suppliers.POST("", s.createSupplier)
+			suppliers.GET("", s.listSuppliers)
+			suppliers.GET(":id", s.getSupplier)
+			suppliers.PUT(":id", s.updateSupplier)
+
+			pos := v1.Group("/purchase-orders")
+			pos.POST("", s.createPurchaseOrder)
+			pos.GET("", s.listPurchaseOrders)
+			pos.GET(":id", s.getPurchaseOrder)
+			pos.POST(":id/send", s.sendPurchaseOrder)
+			pos.POST(":id/receive", s.receiveGoods)
+			pos.POST(":id/cancel", s.cancelPurchaseOrder)
+		}
+
+		if s.stocktakes != nil {
+			st := v1.Group("/stocktakes")
+			st.POST("", s.startStocktake)
+			st.GET("", s.listStocktakes)
+			st.GET(":id", s.getStocktake)
+			st.POST(":id/counts", s.submitCounts)
+			st.GET(":id/variances", s.stocktakeVariances)
+			st.POST(":id/approve", s.approveStocktake)
+			st.POST(":id/cancel", s.cancelStocktake)
+		}
+
+		if s.categories != nil {
+			cats := v1.Group("/categories")
+			cats.POST("", s.createCategory)
+			cats.GET("", s.categoryTree)
+			cats.POST("/reorder", s.reorderCategories)
+			cats.GET(":id", s.getCategory)
+			cats.PUT(":id", s.updateCategory)
+			cats.DELETE(":id", s.deleteCategory)
+			cats.POST(":id/move", s.moveCategory)
+			cats.GET(":id/attributes", s.categorySchema)
+			cats.PUT(":id/attributes", s.setCategoryAttributes)
+		}
+
+		if s.serials != nil {
+			products.GET(":id/batches", s.productBatches)
+			serials := v1.Group("/serials")
+			serials.POST("", s.registerSerials)
+			serials.GET("", s.listSerials)
+			serials.GET(":code", s.getSerial)
+		}
+
+		if s.credits != nil {
+			v1.POST("/gift-cards", s.issueGiftCard)
+			v1.POST("/store-credit", s.addStoreCredit)
+			credits := v1.Group("/credit-accounts")
+			credits.GET("", s.listCreditAccounts)
+			credits.GET(":id", s.getCreditAccount)
+			credits.GET(":id/transactions", s.creditTransactions)
+		}
+
+		if s.carts != nil {
+			carts := v1.Group("/carts")
+			carts.POST("", s.createCart)
+			carts.GET(":id", s.getCart)
+			carts.POST(":id/items", s.addCartItem)
+			carts.PUT(":id/items/:product_id", s.setCartItem)
+			carts.DELETE(":id/items/:product_id", s.removeCartItem)
+			carts.POST(":id/promo-codes", s.addCartPromoCode)
+			carts.DELETE(":id/promo-codes/:code", s.removeCartPromoCode)
+			carts.POST(":id/checkout", s.checkoutCart)
+		}
+
+		if s.loyalty != nil {
+			loyalty := v1.Group("/loyalty")
+			loyalty.GET("/rules", s.getLoyaltyRules)
+			loyalty.PUT("/rules", s.setLoyaltyRules)
+			loyalty.GET("/balance", s.loyaltyBalance)
+			loyalty.GET("/entries", s.loyaltyEntries)
+		}
+
+		if s.promotions != nil {
+			promos := v1.Group("/promotions")
+			promos.POST("", s.createPromotion)
+			promos.GET("", s.listPromotions)
+			promos.GET(":id", s.getPromotion)
+			promos.PUT(":id", s.updatePromotion)
+			promos.DELETE(":id", s.deletePromotion)
+		}
 	}
 }
 
 // Product handlers
 type createProductReq struct {
-	Name  string  `json:"name"`
-	SKU   string  `json:"sku"`
-	Price float64 `json:"price"`
-	Stock int64   `json:"stock"`
+	Name         string            `json:"name"`
+	SKU          string            `json:"sku"`
+	Price        float64           `json:"price"`
+	Stock        int64             `json:"stock"`
+	ReorderPoint int64             `json:"reorder_point"`
+	ReorderQty   int64             `json:"reorder_quantity"`
+	CategoryIDs  []int64           `json:"category_ids"`
+	Attributes   map[string]string `json:"attributes"`
+	Barcodes     []string          `json:"barcodes"`
+	Serialized   bool              `json:"serialized"`
+	TaxRate      float64           `json:"tax_rate"`
 }
 
 // @Summary Create product
@@ -72,7 +281,11 @@ func (s *Server) createProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
 		return
 	}
-	p, err := s.products.Create(c, domain.Product{Name: req.Name, SKU: req.SKU, Price: req.Price, Stock: req.Stock})
+	p, err := s.products.Create(c, domain.Product{
+		Name: req.Name, SKU: req.SKU, Price: req.Price, Stock: req.Stock,
+		ReorderPoint: req.ReorderPoint, ReorderQty: req.ReorderQty, CategoryIDs: req.CategoryIDs,
+		Attributes: req.Attributes, Barcodes: req.Barcodes, Serialized: req.Serialized, TaxRate: req.TaxRate,
+	})
 	if err != nil {
 		status := mapErrorToStatus(err)
 		c.JSON(status, gin.H{"error": err.Error()})
@@ -104,18 +317,74 @@ func (s *Server) getProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, p)
 }
 
+// @Summary Get product by barcode (GTIN-8/12/13/14)
+// @Tags products
+// @Produce json
+// @Param code path string true "Barcode"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /products/by-barcode/{code} [get]
+func (s *Server) getProductByBarcode(c *gin.Context) {
+	p, err := s.products.GetByBarcode(c, c.Param("code"))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// @Summary Find in-stock substitutes (same active substance and dosage)
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /products/{id}/substitutes [get]
+func (s *Server) productSubstitutes(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.products.Substitutes(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// updateProductReq полное представление товара для PUT: обязательны все поля
 type updateProductReq struct {
-	Name  string  `json:"name"`
-	Price float64 `json:"price"`
-	Stock int64   `json:"stock"`
+	Name         string            `json:"name"`
+	SKU          string            `json:"sku"`
+	Price        float64           `json:"price"`
+	Stock        int64             `json:"stock"`
+	ReorderPoint int64             `json:"reorder_point"`
+	ReorderQty   int64             `json:"reorder_quantity"`
+	CategoryIDs  []int64           `json:"category_ids"`
+	Attributes   map[string]string `json:"attributes"`
+	Barcodes     []string          `json:"barcodes"`
+	Serialized   bool              `json:"serialized"`
+	TaxRate      float64           `json:"tax_rate"`
 }
 
-// @Summary Update product
+var updateProductFields = []string{
+	"name", "sku", "price", "stock", "reorder_point", "reorder_quantity",
+	"category_ids", "attributes", "barcodes", "serialized", "tax_rate",
+}
+
+// @Summary Replace product
+// @Description Full replacement: every field is required, use PATCH for partial updates
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
-// @Param input body updateProductReq true "Update"
+// @Param input body updateProductReq true "Product"
 // @Success 200 {object} domain.Product
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -126,12 +395,28 @@ func (s *Server) updateProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
+	body, err := c.GetRawData()
+	var fields map[string]json.RawMessage
+	if err != nil || json.Unmarshal(body, &fields) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	for _, name := range updateProductFields {
+		if _, ok := fields[name]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": invalidParam(name, "is required").Error()})
+			return
+		}
+	}
 	var req updateProductReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
 		return
 	}
-	p, err := s.products.Update(c, domain.Product{ID: id, Name: req.Name, Price: req.Price, Stock: req.Stock})
+	p, err := s.products.Update(c, domain.Product{
+		ID: id, Name: req.Name, SKU: req.SKU, Price: req.Price, Stock: req.Stock,
+		ReorderPoint: req.ReorderPoint, ReorderQty: req.ReorderQty, CategoryIDs: req.CategoryIDs,
+		Attributes: req.Attributes, Barcodes: req.Barcodes, Serialized: req.Serialized, TaxRate: req.TaxRate,
+	})
 	if err != nil {
 		status := mapErrorToStatus(err)
 		c.JSON(status, gin.H{"error": err.Error()})
@@ -140,12 +425,62 @@ func (s *Server) updateProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, p)
 }
 
-// @Summary Delete product
+// @Summary Patch product
+// @Description application/merge-patch+json (RFC 7396, also accepted as application/json)
+// @Description or application/json-patch+json (RFC 6902). A failed "test" operation returns 409.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body object true "Merge patch document or array of JSON Patch operations"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Router /products/{id} [patch]
+func (s *Server) patchProduct(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	var p patch.Patch
+	switch c.ContentType() {
+	case "application/json-patch+json":
+		p, err = patch.ParseJSON(body)
+	case "application/merge-patch+json", "application/json":
+		p, err = patch.ParseMerge(body)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content type"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	product, err := s.products.Patch(c, id, p)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// @Summary Archive product
+// @Description Hides the product from listing and ordering; orders still resolve it by id
 // @Tags products
 // @Param id path int true "Product ID"
 // @Success 204
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /products/{id} [delete]
 func (s *Server) deleteProduct(c *gin.Context) {
 	id, err := parseID(c.Param("id"))
@@ -153,7 +488,54 @@ func (s *Server) deleteProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	if err := s.products.Delete(c, id); err != nil {
+	if _, err := s.products.Archive(c, id); err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Restore archived product
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /products/{id}/restore [post]
+func (s *Server) restoreProduct(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	p, err := s.products.Restore(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// @Summary Purge archived product
+// @Description Removes an archived product for good; 409 while orders, purchase orders, stocktakes, serials, carts or promotions reference it
+// @Tags products
+// @Param id path int true "Product ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /products/{id}/purge [post]
+func (s *Server) purgeProduct(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := s.products.Purge(c, id); err != nil {
 		status := mapErrorToStatus(err)
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
@@ -162,40 +544,214 @@ func (s *Server) deleteProduct(c *gin.Context) {
 }
 
 // @Summary List products
+// @Description With facets=true the list is wrapped as {"items": [...], "facets": domain.ProductFacets}
 // @Tags products
 // @Produce json
-// @Param q query string false "Name contains"
+// @Param q query string false "Full-text query (morphology, transliteration, typos); results ordered by relevance"
 // @Param min_price query number false "Min price"
 // @Param max_price query number false "Max price"
+// @Param category query string false "Category id or slug, includes subcategories"
+// @Param sku query string false "SKU prefix, case-insensitive"
+// @Param ids query string false "Comma-separated product ids"
+// @Param in_stock query bool false "true — only in stock, false — only out of stock"
+// @Param min_stock query int false "Min stock"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param updated_from query string false "Updated at or after (RFC 3339)"
+// @Param updated_to query string false "Updated before (RFC 3339)"
+// @Param archived query bool false "true — only archived; archived products are hidden by default"
+// @Param attr.code query string false "Attribute value by code, e.g. attr.manufacturer=Bayer; repeat for any-of"
+// @Param facets query bool false "Wrap the list as {items, facets} with facet counts"
+// @Param price_buckets query string false "Comma-separated price bucket edges for facets, e.g. 100,500,1000"
 // @Success 200 {array} domain.Product
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /products [get]
 func (s *Server) listProducts(c *gin.Context) {
-	var f repository.ProductFilter
-	if q := c.Query("q"); q != "" {
-		f.NameSubstring = q
+	f, err := s.productFilter(c)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
 	}
-	if v := c.Query("min_price"); v != "" {
-		if x, err := strconv.ParseFloat(v, 64); err == nil {
-			f.MinPrice = &x
-		}
+	withFacets, err := queryBool(c, "facets")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	if v := c.Query("max_price"); v != "" {
-		if x, err := strconv.ParseFloat(v, 64); err == nil {
-			f.MaxPrice = &x
+	if withFacets != nil && *withFacets {
+		edges, err := queryPriceEdges(c, "price_buckets")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		list, facets, err := s.products.ListWithFacets(c, f, edges)
+		if err != nil {
+			status := mapErrorToStatus(err)
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusOK, productListWithFacets{Items: list, Facets: facets})
+		return
 	}
 	list, err := s.products.List(c, f)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, list)
 }
 
+// productFilter разбирает параметры списка товаров; неразборчивое значение — ошибка
+// с именем параметра, а не молчаливый пропуск условия
+func (s *Server) productFilter(c *gin.Context) (repository.ProductFilter, error) {
+	var f repository.ProductFilter
+	f.NameSubstring = c.Query("q")
+	f.SKUPrefix = c.Query("sku")
+	var err error
+	if f.MinPrice, err = queryFloat(c, "min_price"); err != nil {
+		return f, err
+	}
+	if f.MaxPrice, err = queryFloat(c, "max_price"); err != nil {
+		return f, err
+	}
+	if f.MinStock, err = queryInt(c, "min_stock"); err != nil {
+		return f, err
+	}
+	if f.InStock, err = queryBool(c, "in_stock"); err != nil {
+		return f, err
+	}
+	if f.Archived, err = queryBool(c, "archived"); err != nil {
+		return f, err
+	}
+	for _, t := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &f.CreatedFrom}, {"created_to", &f.CreatedTo},
+		{"updated_from", &f.UpdatedFrom}, {"updated_to", &f.UpdatedTo},
+	} {
+		if *t.dst, err = queryTime(c, t.name); err != nil {
+			return f, err
+		}
+	}
+	if v := c.Query("ids"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return f, invalidParam("ids", "must be comma-separated integers")
+			}
+			f.IDs = append(f.IDs, id)
+		}
+	}
+	if v := c.Query("category"); v != "" {
+		id, err := s.resolveCategory(c, v)
+		if err != nil {
+			return f, err
+		}
+		f.CategoryID = id
+	}
+	for key, values := range c.Request.URL.Query() {
+		if code, ok := strings.CutPrefix(key, "attr."); ok && code != "" {
+			if f.Attributes == nil {
+				f.Attributes = make(map[string][]string)
+			}
+			f.Attributes[code] = values
+		}
+	}
+	return f, nil
+}
+
+func invalidParam(name, message string) error {
+	return &service.ValidationError{Field: name, Message: message}
+}
+
+func queryFloat(c *gin.Context, name string) (*float64, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	x, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, invalidParam(name, "must be a number")
+	}
+	return &x, nil
+}
+
+func queryInt(c *gin.Context, name string) (*int64, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	x, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, invalidParam(name, "must be an integer")
+	}
+	return &x, nil
+}
+
+func queryBool(c *gin.Context, name string) (*bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	x, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, invalidParam(name, "must be true or false")
+	}
+	return &x, nil
+}
+
+// queryPriceEdges разбирает границы ценовых диапазонов через запятую;
+// повторы дали бы пустые диапазоны нулевой ширины, поэтому тоже ошибка
+func queryPriceEdges(c *gin.Context, name string) ([]float64, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	var edges []float64
+	seen := make(map[float64]bool)
+	for _, part := range strings.Split(v, ",") {
+		x, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil, invalidParam(name, "must be comma-separated numbers")
+		}
+		if seen[x] {
+			return nil, invalidParam(name, "must not repeat edges")
+		}
+		seen[x] = true
+		edges = append(edges, x)
+	}
+	return edges, nil
+}
+
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	x, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, invalidParam(name, "must be an RFC 3339 timestamp")
+	}
+	return &x, nil
+}
+
+// productListWithFacets ответ списка товаров с агрегатами для панели фильтров
+type productListWithFacets struct {
+	Items  []domain.Product      `json:"items"`
+	Facets *domain.ProductFacets `json:"facets"`
+}
+
 // Order handlers
 type createOrderReq struct {
-	CustomerName string             `json:"customer_name"`
-	Items        []domain.OrderItem `json:"items"`
+	CustomerName       string             `json:"customer_name"`
+	Items              []domain.OrderItem `json:"items"`
+	SuggestSubstitutes bool               `json:"suggest_substitutes"`
+	PromoCodes         []string           `json:"promo_codes"`
+	// LoyaltyPoints баллы, списываемые в счёт скидки
+	LoyaltyPoints int64 `json:"loyalty_points"`
 }
 
 // @Summary Create order
@@ -213,8 +769,29 @@ func (s *Server) createOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
 		return
 	}
-	o, err := s.orders.CreateOrder(c, req.CustomerName, req.Items)
+	var opts []service.CreateOrderOption
+	if req.SuggestSubstitutes {
+		opts = append(opts, service.WithSubstituteSuggestions())
+	}
+	if len(req.PromoCodes) > 0 {
+		opts = append(opts, service.WithPromoCodes(req.PromoCodes...))
+	}
+	if req.LoyaltyPoints != 0 {
+		opts = append(opts, service.WithLoyaltyPoints(req.LoyaltyPoints))
+	}
+	o, err := s.orders.CreateOrder(c, req.CustomerName, req.Items, opts...)
 	if err != nil {
+		var stockErr *service.StockError
+		if errors.As(err, &stockErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":       err.Error(),
+				"product_id":  stockErr.ProductID,
+				"requested":   stockErr.Requested,
+				"available":   stockErr.Available,
+				"substitutes": stockErr.Substitutes,
+			})
+			return
+		}
 		status := mapErrorToStatus(err)
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
@@ -222,6 +799,72 @@ func (s *Server) createOrder(c *gin.Context) {
 	c.JSON(http.StatusCreated, o)
 }
 
+// @Summary Tax summary
+// @Description VAT by rate for orders created in [from, to); cancelled orders are excluded
+// @Tags orders
+// @Produce json
+// @Param from query string true "Period start, RFC 3339"
+// @Param to query string true "Period end (exclusive), RFC 3339"
+// @Success 200 {object} domain.TaxSummary
+// @Failure 400 {object} map[string]string
+// @Router /orders/tax-summary [get]
+func (s *Server) taxSummary(c *gin.Context) {
+	from, err := queryTime(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := queryTime(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from == nil || to == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidParam("from", "from and to are required").Error()})
+		return
+	}
+	sum, err := s.orders.TaxSummary(c, *from, *to)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sum)
+}
+
+// @Summary Refund summary
+// @Description Refunds calculated in [from, to) by kind: customer returns, partial and full cancellations are reported separately; partial cancellations are also grouped by reason
+// @Tags orders
+// @Produce json
+// @Param from query string true "Period start, RFC 3339"
+// @Param to query string true "Period end (exclusive), RFC 3339"
+// @Success 200 {object} domain.RefundSummary
+// @Failure 400 {object} map[string]string
+// @Router /orders/refund-summary [get]
+func (s *Server) refundSummary(c *gin.Context) {
+	from, err := queryTime(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := queryTime(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if from == nil || to == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidParam("from", "from and to are required").Error()})
+		return
+	}
+	sum, err := s.orders.RefundSummary(c, *from, *to)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sum)
+}
+
 // @Summary Get order by id
 // @Tags orders
 // @Produce json
@@ -249,7 +892,7 @@ func (s *Server) getOrder(c *gin.Context) {
 // @Tags orders
 // @Produce json
 // @Param id path int true "Order ID"
-// @Success 200 {object} domain.Order
+// @Success 200 {object} orderWithRefund
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 409 {object} map[string]string
@@ -260,17 +903,104 @@ func (s *Server) cancelOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
-	o, err := s.orders.CancelOrder(c, id)
+	o, refund, err := s.orders.CancelOrder(c, id)
 	if err != nil {
 		status := mapErrorToStatus(err)
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, o)
+	c.JSON(http.StatusOK, orderWithRefund{Order: *o, Refund: refund})
+}
+
+type editOrderReq struct {
+	// Items новые количества товаров: 0 удаляет товар из заказа, товар не из заказа добавляется
+	Items []domain.OrderItem `json:"items"`
+	Note  string             `json:"note"`
+}
+
+// orderWithEdit заказ после редактирования вместе с записью журнала
+type orderWithEdit struct {
+	domain.Order
+	Edit *domain.OrderEdit `json:"edit"`
+}
+
+// @Summary Edit order lines
+// @Description Sets new quantities for products of a Confirmed or Pending order: 0 removes the product, unknown products are added at the current price.
+// @Description Stock is reserved or released by the difference; serialized products cannot be edited.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param input body editOrderReq true "New quantities"
+// @Success 200 {object} orderWithEdit
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /orders/{id}/edit [post]
+func (s *Server) editOrder(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req editOrderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	o, edit, err := s.orders.EditOrder(c, id, req.Items, req.Note)
+	if err != nil {
+		var stockErr *service.StockError
+		if errors.As(err, &stockErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      err.Error(),
+				"product_id": stockErr.ProductID,
+				"requested":  stockErr.Requested,
+				"available":  stockErr.Available,
+			})
+			return
+		}
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, orderWithEdit{Order: *o, Edit: edit})
+}
+
+// @Summary Order edits
+// @Description Audit log of line edits with totals before and after each one
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} domain.OrderEdit
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/edits [get]
+func (s *Server) orderEdits(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.orders.Edits(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// orderWithRefund заказ после отмены или возврата вместе с рассчитанным возвратом денег
+type orderWithRefund struct {
+	domain.Order
+	Refund *domain.Refund `json:"refund"`
 }
 
 type partialReturnReq struct {
 	Items []domain.OrderItem `json:"items"`
+	// RefundTo original (по умолчанию) или store_credit — на депозит покупателя
+	RefundTo domain.RefundMethod `json:"refund_to,omitempty"`
 }
 
 // @Summary Partial return
@@ -279,7 +1009,7 @@ type partialReturnReq struct {
 // @Produce json
 // @Param id path int true "Order ID"
 // @Param input body partialReturnReq true "Return items"
-// @Success 200 {object} domain.Order
+// @Success 200 {object} orderWithRefund
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 409 {object} map[string]string
@@ -295,29 +1025,120 @@ func (s *Server) partialReturn(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
 		return
 	}
-	o, err := s.orders.PartialReturn(c, id, req.Items)
+	var opts []service.ReturnOption
+	switch req.RefundTo {
+	case "", domain.RefundMethodOriginal:
+	case domain.RefundMethodStoreCredit:
+		opts = append(opts, service.RefundToStoreCredit())
+	default:
+		err := invalidParam("refund_to", "must be original or store_credit")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	o, refund, err := s.orders.PartialReturn(c, id, req.Items, opts...)
 	if err != nil {
 		status := mapErrorToStatus(err)
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, o)
+	c.JSON(http.StatusOK, orderWithRefund{Order: *o, Refund: refund})
+}
+
+type partialCancelReq struct {
+	Items []domain.OrderItem `json:"items"`
+	// Reason почему позиции не будут выполнены, например out_of_stock
+	Reason string `json:"reason"`
+}
+
+// @Summary Partial cancellation
+// @Description Removes quantities the store cannot fulfil from a Confirmed or Pending order, restocks them and refunds them with kind partial_cancel and the given reason
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param input body partialCancelReq true "Cancelled items and reason"
+// @Success 200 {object} orderWithRefund
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /orders/{id}/partial-cancel [post]
+func (s *Server) partialCancel(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req partialCancelReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	o, refund, err := s.orders.PartialCancel(c, id, req.Items, req.Reason)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, orderWithRefund{Order: *o, Refund: refund})
+}
+
+// @Summary Order refunds
+// @Description Refunds calculated for cancellation, partial cancellations and returns of the order
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} domain.Refund
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/refunds [get]
+func (s *Server) orderRefunds(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.orders.Refunds(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
 }
 
 func parseID(s string) (int64, error) {
 	return strconv.ParseInt(s, 10, 64)
 }
 
+// resolveCategory принимает id или слаг категории
+func (s *Server) resolveCategory(c *gin.Context, ref string) (int64, error) {
+	if id, err := parseID(ref); err == nil {
+		return id, nil
+	}
+	if s.categories == nil {
+		return 0, service.ErrInvalidInput
+	}
+	cat, err := s.categories.GetBySlug(c, ref)
+	if err != nil {
+		return 0, err
+	}
+	return cat.ID, nil
+}
+
 func mapErrorToStatus(err error) int {
-	switch err {
-	case service.ErrInvalidInput:
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
 		return http.StatusBadRequest
-	case service.ErrNotEnoughStock:
+	case errors.Is(err, service.ErrNotEnoughStock):
 		return http.StatusBadRequest
-	case repository.ErrNotFound:
+	case errors.Is(err, repository.ErrNotFound):
 		return http.StatusNotFound
-	case service.ErrInvalidState:
+	case errors.Is(err, repository.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidState):
 		return http.StatusConflict
+	case errors.Is(err, service.ErrPaymentDeclined):
+		return http.StatusPaymentRequired
 	default:
 		return http.StatusInternalServerError
 	}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"april/internal/domain"
 	"april/internal/repository"
 	"april/internal/service"
 )
@@ -16,7 +19,7 @@ func setupServer(t *testing.T) *Server {
 	store := repository.NewMemoryStore()
 	ordersRepo := repository.NewMemoryOrders(store)
 	tx := repository.NewMemoryTx(store)
-	productsSvc := service.NewProductService(store)
+	productsSvc := service.NewProductService(store, tx)
 	ordersSvc := service.NewOrderService(store, ordersRepo, tx)
 	return NewServer(productsSvc, ordersSvc)
 }
@@ -50,23 +53,59 @@ func TestProductFlow(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("get code %v", w.Code)
 	}
-	// update
+	// update: PUT replaces the whole product
 	w = doJSON(t, s, http.MethodPut, "/api/v1/products/1", map[string]any{
 		"name": "A+", "price": 12, "stock": 7,
 	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "sku") {
+		t.Fatalf("expected 400 for partial PUT, got %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPut, "/api/v1/products/1", map[string]any{
+		"name": "A+", "sku": "S1", "price": 12, "stock": 7, "reorder_point": 0, "reorder_quantity": 0,
+		"category_ids": []int64{}, "attributes": map[string]string{}, "barcodes": []string{}, "serialized": false, "tax_rate": 10,
+	})
 	if w.Code != http.StatusOK {
-		t.Fatalf("update code %v", w.Code)
+		t.Fatalf("update code %v %s", w.Code, w.Body.String())
 	}
 	// list
 	w = doJSON(t, s, http.MethodGet, "/api/v1/products?q=asp", nil)
 	if w.Code != http.StatusOK {
 		t.Fatalf("list code %v", w.Code)
 	}
-	// delete
+	// delete archives the product
 	w = doJSON(t, s, http.MethodDelete, "/api/v1/products/1", nil)
 	if w.Code != http.StatusNoContent {
 		t.Fatalf("delete code %v", w.Code)
 	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products/1", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "archived_at") {
+		t.Fatalf("archived product not resolvable %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products", nil)
+	if w.Body.String() != "[]" {
+		t.Fatalf("archived product listed %s", w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products?archived=true", nil)
+	if w.Code != http.StatusOK || w.Body.String() == "[]" {
+		t.Fatalf("archived filter %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products/1/restore", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("restore code %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products/1/purge", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 purging active product, got %v", w.Code)
+	}
+	_ = doJSON(t, s, http.MethodDelete, "/api/v1/products/1", nil)
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products/1/purge", nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("purge code %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products/1", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after purge, got %v", w.Code)
+	}
 }
 
 func TestOrderFlow(t *testing.T) {
@@ -109,6 +148,78 @@ func TestOrderFlow(t *testing.T) {
 	}
 }
 
+func TestTaxSummary(t *testing.T) {
+	s := setupServer(t)
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 120, "stock": 5, "tax_rate": 20})
+	w := doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John", "items": []map[string]any{{"product_id": 1, "quantity": 2}},
+	})
+	var o domain.Order
+	_ = json.Unmarshal(w.Body.Bytes(), &o)
+	if w.Code != http.StatusCreated || o.Tax != 40 || o.Items[0].Tax != 40 {
+		t.Fatalf("order tax %v %s", w.Code, w.Body.String())
+	}
+
+	from := o.CreatedAt.Add(-time.Minute).Format(time.RFC3339)
+	to := o.CreatedAt.Add(time.Minute).Format(time.RFC3339)
+	w = doJSON(t, s, http.MethodGet, "/api/v1/orders/tax-summary?from="+from+"&to="+to, nil)
+	var sum domain.TaxSummary
+	_ = json.Unmarshal(w.Body.Bytes(), &sum)
+	if w.Code != http.StatusOK || sum.Orders != 1 || sum.Tax != 40 || sum.Net != 200 || len(sum.Rates) != 1 {
+		t.Fatalf("summary %v %s", w.Code, w.Body.String())
+	}
+	for _, q := range []string{"", "?from=" + from, "?from=yesterday&to=" + to, "?from=" + to + "&to=" + from} {
+		if w = doJSON(t, s, http.MethodGet, "/api/v1/orders/tax-summary"+q, nil); w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %v", q, w.Code)
+		}
+	}
+}
+
+func TestPatchProduct(t *testing.T) {
+	s := setupServer(t)
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "Aspirin", "sku": "S1", "price": 10, "stock": 5})
+	patchReq := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		s.Engine().ServeHTTP(w, req)
+		return w
+	}
+
+	w := patchReq("application/merge-patch+json", `{"price":12,"reorder_point":2}`)
+	var p domain.Product
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusOK || p.Price != 12 || p.ReorderPoint != 2 || p.SKU != "S1" || p.Stock != 5 {
+		t.Fatalf("merge patch %v %s", w.Code, w.Body.String())
+	}
+	w = patchReq("application/json-patch+json", `[{"op":"test","path":"/price","value":12},{"op":"replace","path":"/name","value":"Aspirin C"}]`)
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusOK || p.Name != "Aspirin C" {
+		t.Fatalf("json patch %v %s", w.Code, w.Body.String())
+	}
+	w = patchReq("application/json-patch+json", `[{"op":"test","path":"/price","value":10},{"op":"replace","path":"/stock","value":0}]`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on failed test, got %v", w.Code)
+	}
+	for body, field := range map[string]string{
+		`{"price":-1}`:   "price",
+		`{"sku":null}`:   "sku",
+		`{"stock":"10"}`: "stock",
+		`{"id":5}`:       "id",
+	} {
+		w = patchReq("application/json", body)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), field) {
+			t.Fatalf("%s: expected 400 naming %s, got %v %s", body, field, w.Code, w.Body.String())
+		}
+	}
+	if w = patchReq("application/json-patch+json", `{"op":"add"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed patch, got %v", w.Code)
+	}
+	if w = patchReq("text/plain", `{}`); w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415, got %v", w.Code)
+	}
+}
+
 func TestHTTP_BadRequests(t *testing.T) {
 	s := setupServer(t)
 	// invalid product body
@@ -122,6 +233,24 @@ func TestHTTP_BadRequests(t *testing.T) {
 	if w.Code != http.StatusBadRequest {
 		t.Fatalf("expected 400, got %v", w.Code)
 	}
+
+	// malformed or contradictory list parameters
+	for query, field := range map[string]string{
+		"min_price=abc":               "min_price",
+		"min_price=200&max_price=100": "min_price",
+		"in_stock=maybe":              "in_stock",
+		"ids=1,x":                     "ids",
+		"created_from=yesterday":      "created_from",
+	} {
+		w = doJSON(t, s, http.MethodGet, "/api/v1/products?"+query, nil)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), field) {
+			t.Fatalf("%s: expected 400 naming %s, got %v %s", query, field, w.Code, w.Body.String())
+		}
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products?min_price=1&in_stock=true&sku=S&ids=1,2", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v %s", w.Code, w.Body.String())
+	}
 }
 
 func TestHTTP_NotFound_Conflict(t *testing.T) {
@@ -141,3 +270,152 @@ func TestHTTP_NotFound_Conflict(t *testing.T) {
 		t.Fatalf("expected 409, got %v", w.Code)
 	}
 }
+
+func TestListProducts_Facets(t *testing.T) {
+	s := setupServer(t)
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 50, "stock": 1})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "B", "sku": "S2", "price": 700})
+
+	w := doJSON(t, s, http.MethodGet, "/api/v1/products?facets=true&price_buckets=100,1000", nil)
+	var resp struct {
+		Items  []json.RawMessage `json:"items"`
+		Facets struct {
+			Total      int `json:"total"`
+			InStock    int `json:"in_stock"`
+			OutOfStock int `json:"out_of_stock"`
+			Price      []struct {
+				Count int `json:"count"`
+			} `json:"price"`
+		} `json:"facets"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &resp) != nil {
+		t.Fatalf("facets %v %s", w.Code, w.Body.String())
+	}
+	if len(resp.Items) != 2 || resp.Facets.Total != 2 || resp.Facets.InStock != 1 || len(resp.Facets.Price) != 3 || resp.Facets.Price[1].Count != 1 {
+		t.Fatalf("unexpected facets %s", w.Body.String())
+	}
+	for _, tc := range []struct{ query, param string }{
+		{"facets=true&price_buckets=1,x", "price_buckets"},
+		{"facets=true&price_buckets=100,100", "price_buckets"},
+		{"facets=true&price_buckets=NaN", "price_buckets"},
+		{"facets=yes", "facets"},
+	} {
+		w := doJSON(t, s, http.MethodGet, "/api/v1/products?"+tc.query, nil)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tc.param) {
+			t.Fatalf("%s: expected 400 naming %s, got %v %s", tc.query, tc.param, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestEditOrderFlow(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	ordersSvc.SetEdits(repository.NewMemoryOrderEdits(store))
+	s := NewServer(service.NewProductService(store, tx), ordersSvc)
+	for _, p := range []map[string]any{
+		{"name": "Aspirin", "sku": "S1", "price": 10, "stock": 5},
+		{"name": "Gel", "sku": "S2", "price": 20, "stock": 1},
+	} {
+		if w := doJSON(t, s, http.MethodPost, "/api/v1/products", p); w.Code != http.StatusCreated {
+			t.Fatalf("create product %v", w.Code)
+		}
+	}
+	w := doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John",
+		"items":         []map[string]any{{"product_id": 1, "quantity": 3}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create order %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/edit", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}, {"product_id": 2, "quantity": 1}},
+		"note":  "customer called",
+	})
+	var got struct {
+		Total float64           `json:"total"`
+		Edit  *domain.OrderEdit `json:"edit"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &got) != nil {
+		t.Fatalf("edit %v %s", w.Code, w.Body.String())
+	}
+	if got.Total != 30 || got.Edit == nil || got.Edit.TotalBefore != 30 || len(got.Edit.Changes) != 2 {
+		t.Fatalf("unexpected edit response %s", w.Body.String())
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/edit", map[string]any{
+		"items": []map[string]any{{"product_id": 2, "quantity": 3}},
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"available":0`) {
+		t.Fatalf("expected stock error, got %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/9/edit", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}},
+	})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/orders/1/edits", nil)
+	var list []domain.OrderEdit
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &list) != nil || len(list) != 1 || list[0].Note != "customer called" {
+		t.Fatalf("edits %v %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPartialCancelAndRefundSummary(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), repository.NewMemoryTx(store))
+	ordersSvc.SetRefunds(repository.NewMemoryRefunds(store))
+	s := NewServer(service.NewProductService(store, repository.NewMemoryTx(store)), ordersSvc)
+	from := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if w := doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "Aspirin", "sku": "S1", "price": 10, "stock": 5}); w.Code != http.StatusCreated {
+		t.Fatalf("create product %v", w.Code)
+	}
+	w := doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John",
+		"items":         []map[string]any{{"product_id": 1, "quantity": 3}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create order %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/partial-cancel", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}},
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "reason") {
+		t.Fatalf("expected missing reason rejected, got %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/partial-cancel", map[string]any{
+		"items":  []map[string]any{{"product_id": 1, "quantity": 2}},
+		"reason": "out_of_stock",
+	})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"kind":"partial_cancel"`) || !strings.Contains(w.Body.String(), `"reason":"out_of_stock"`) {
+		t.Fatalf("partial cancel %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/partial-return", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("partial return %v", w.Code)
+	}
+
+	to := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	w = doJSON(t, s, http.MethodGet, "/api/v1/orders/refund-summary?from="+from+"&to="+to, nil)
+	var sum domain.RefundSummary
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &sum) != nil {
+		t.Fatalf("summary %v %s", w.Code, w.Body.String())
+	}
+	byKind := map[domain.RefundKind]domain.RefundKindSummary{}
+	for _, k := range sum.Kinds {
+		byKind[k.Kind] = k
+	}
+	if byKind[domain.RefundKindReturn].Units != 1 || byKind[domain.RefundKindPartialCancel].Units != 2 || len(sum.CancelReasons) != 1 {
+		t.Fatalf("unexpected summary %s", w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/orders/refund-summary?from="+from, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without to, got %v", w.Code)
+	}
+}
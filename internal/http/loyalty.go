@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+)
+
+// @Summary Loyalty rules
+// @Tags loyalty
+// @Produce json
+// @Success 200 {object} domain.LoyaltyRules
+// @Router /loyalty/rules [get]
+func (s *Server) getLoyaltyRules(c *gin.Context) {
+	r, err := s.loyalty.Rules(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// @Summary Set loyalty rules
+// @Description Points are earned as floor(order total * earn_rate) for orders of at least min_order_total.
+// @Description Redemption gives point_value off per point, up to max_redeem_percent of the order; zero values disable earning or redemption.
+// @Tags loyalty
+// @Accept json
+// @Produce json
+// @Param input body domain.LoyaltyRules true "Rules"
+// @Success 200 {object} domain.LoyaltyRules
+// @Failure 400 {object} map[string]string
+// @Router /loyalty/rules [put]
+func (s *Server) setLoyaltyRules(c *gin.Context) {
+	var req domain.LoyaltyRules
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	r, err := s.loyalty.SetRules(c, req)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// @Summary Customer loyalty balance
+// @Tags loyalty
+// @Produce json
+// @Param customer query string true "Customer name"
+// @Success 200 {object} domain.LoyaltyBalance
+// @Failure 400 {object} map[string]string
+// @Router /loyalty/balance [get]
+func (s *Server) loyaltyBalance(c *gin.Context) {
+	b, err := s.loyalty.Balance(c, c.Query("customer"))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+// @Summary Customer loyalty ledger
+// @Description Earned, revoked, redeemed and restored points with the balance after each entry
+// @Tags loyalty
+// @Produce json
+// @Param customer query string true "Customer name"
+// @Success 200 {array} domain.LoyaltyEntry
+// @Failure 400 {object} map[string]string
+// @Router /loyalty/entries [get]
+func (s *Server) loyaltyEntries(c *gin.Context) {
+	list, err := s.loyalty.Entries(c, c.Query("customer"))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
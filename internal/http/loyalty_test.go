@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestLoyalty(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	ordersSvc.SetEvents(bus)
+	loyaltySvc := service.NewLoyaltyService(repository.NewMemoryLoyalty(store), tx)
+	ordersSvc.SetLoyalty(loyaltySvc)
+	loyaltySvc.Subscribe(bus)
+	s := NewServer(service.NewProductService(store, tx), ordersSvc, WithLoyalty(loyaltySvc))
+
+	if w := doJSON(t, s, http.MethodPut, "/api/v1/loyalty/rules", map[string]any{"earn_rate": -1}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for negative rate, got %v", w.Code)
+	}
+	w := doJSON(t, s, http.MethodPut, "/api/v1/loyalty/rules", map[string]any{"earn_rate": 0.1, "point_value": 0.5, "max_redeem_percent": 20})
+	if w.Code != http.StatusOK {
+		t.Fatalf("rules %v %s", w.Code, w.Body.String())
+	}
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 50, "stock": 10})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John", "items": []map[string]any{{"product_id": 1, "quantity": 2}},
+	})
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John", "items": []map[string]any{{"product_id": 1, "quantity": 1}}, "loyalty_points": 10,
+	})
+	var o domain.Order
+	_ = json.Unmarshal(w.Body.Bytes(), &o)
+	if w.Code != http.StatusCreated || o.Total != 45 || o.PointsRedeemed != 10 {
+		t.Fatalf("redeem %v %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/loyalty/balance?customer=John", nil)
+	var b domain.LoyaltyBalance
+	_ = json.Unmarshal(w.Body.Bytes(), &b)
+	if w.Code != http.StatusOK || b.Balance != 4 {
+		t.Fatalf("balance %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/loyalty/entries?customer=John", nil)
+	var entries []domain.LoyaltyEntry
+	_ = json.Unmarshal(w.Body.Bytes(), &entries)
+	if w.Code != http.StatusOK || len(entries) != 3 || entries[1].Kind != domain.LoyaltyEntryRedeem {
+		t.Fatalf("entries %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodGet, "/api/v1/loyalty/balance", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without customer, got %v", w.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+	"april/internal/service"
+)
+
+type payOrderReq struct {
+	Token string `json:"token"`
+	// AuthorizeOnly только блокирует сумму; списание — POST /payments/{id}/capture
+	AuthorizeOnly bool `json:"authorize_only"`
+	// CreditCode оплата с подарочной карты или депозита вместо провайдера;
+	// Amount — сумма, по умолчанию сколько хватает баланса
+	CreditCode string  `json:"credit_code,omitempty"`
+	Amount     float64 `json:"amount,omitempty"`
+}
+
+// @Summary Pay for order
+// @Description Authorizes the unpaid rest of the order with the payment provider and captures it unless authorize_only is set.
+// @Description With credit_code pays from a gift card or store credit instead, up to amount or the account balance.
+// @Description A declined payment is recorded with status Failed and answered with 402.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param input body payOrderReq true "Payment"
+// @Success 201 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]any
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /orders/{id}/pay [post]
+func (s *Server) payOrder(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req payOrderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	var p *domain.Payment
+	if req.CreditCode != "" {
+		p, err = s.payments.PayWithCredit(c, id, req.CreditCode, req.Amount)
+	} else {
+		p, err = s.payments.Pay(c, id, req.Token, !req.AuthorizeOnly)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrPaymentDeclined) {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error(), "payment": p})
+			return
+		}
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+// @Summary Order payments
+// @Tags payments
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/payments [get]
+func (s *Server) orderPayments(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.payments.List(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get payment by id
+// @Tags payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /payments/{id} [get]
+func (s *Server) getPayment(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	p, err := s.payments.Get(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// @Summary Capture payment
+// @Description Captures an authorized payment up to the current order total
+// @Tags payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /payments/{id}/capture [post]
+func (s *Server) capturePayment(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	p, err := s.payments.Capture(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// @Summary Void payment
+// @Description Releases an authorized payment that has not been captured
+// @Tags payments
+// @Produce json
+// @Param id path int true "Payment ID"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /payments/{id}/void [post]
+func (s *Server) voidPayment(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	p, err := s.payments.Void(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestPayments(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	ordersRepo := repository.NewMemoryOrders(store)
+	ordersSvc := service.NewOrderService(store, ordersRepo, tx)
+	ordersSvc.SetEvents(bus)
+	paymentsSvc := service.NewPaymentService(ordersRepo, repository.NewMemoryPayments(store), service.NewFakePaymentProvider(), tx)
+	refundsRepo := repository.NewMemoryRefunds(store)
+	ordersSvc.SetRefunds(refundsRepo)
+	paymentsSvc.SetRefunds(refundsRepo)
+	paymentsSvc.Subscribe(bus)
+	s := NewServer(service.NewProductService(store, tx), ordersSvc, WithPayments(paymentsSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 10, "stock": 5})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "John", "items": []map[string]any{{"product_id": 1, "quantity": 3}},
+	})
+
+	w := doJSON(t, s, http.MethodPost, "/api/v1/orders/1/pay", map[string]any{"token": service.FakeDeclineToken})
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("expected 402, got %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/pay", map[string]any{"token": "tok_visa", "authorize_only": true})
+	var p domain.Payment
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if w.Code != http.StatusCreated || p.Status != domain.PaymentStatusAuthorized {
+		t.Fatalf("authorize %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/pay", map[string]any{"token": "tok_visa"}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for second payment, got %v", w.Code)
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/payments/2/capture", nil); w.Code != http.StatusOK {
+		t.Fatalf("capture %v %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/partial-return", map[string]any{
+		"items": []map[string]any{{"product_id": 1, "quantity": 1}},
+	})
+	var resp struct {
+		domain.Order
+		Refund domain.Refund `json:"refund"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Total != 20 || resp.Refund.Amount != 10 || resp.Refund.Status != domain.RefundStatusCompleted {
+		t.Fatalf("partial return %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders/1/cancel", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Status != domain.OrderStatusCancelled || resp.Refund.Amount != 20 || resp.Refund.Kind != domain.RefundKindCancel {
+		t.Fatalf("cancel %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/orders/1/refunds", nil)
+	var refunds []domain.Refund
+	_ = json.Unmarshal(w.Body.Bytes(), &refunds)
+	if w.Code != http.StatusOK || len(refunds) != 2 {
+		t.Fatalf("refunds %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/orders/1/payments", nil)
+	var list []domain.Payment
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 2 || list[0].Status != domain.PaymentStatusFailed || list[1].Status != domain.PaymentStatusRefunded {
+		t.Fatalf("payments %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodGet, "/api/v1/payments/9", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+}
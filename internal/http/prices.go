@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type schedulePriceReq struct {
+	Price         float64   `json:"price"`
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// @Summary Product price timeline
+// @Tags prices
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} domain.PriceChange
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /products/{id}/prices [get]
+func (s *Server) productPrices(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.prices.History(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Schedule a future price
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param input body schedulePriceReq true "Price and RFC 3339 effective_from"
+// @Success 201 {object} domain.PriceChange
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /products/{id}/prices [post]
+func (s *Server) schedulePrice(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req schedulePriceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	change, err := s.prices.Schedule(c, id, req.Price, req.EffectiveFrom)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, change)
+}
+
+// @Summary Cancel a scheduled price
+// @Tags prices
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param change_id path int true "Price change ID"
+// @Success 200 {object} domain.PriceChange
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /products/{id}/prices/{change_id} [delete]
+func (s *Server) cancelPrice(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	changeID, err := parseID(c.Param("change_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid change_id"})
+		return
+	}
+	change, err := s.prices.Cancel(c, id, changeID)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestPriceSchedule(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetEvents(bus)
+	pricesSvc := service.NewPriceService(store, repository.NewMemoryPriceChanges(store), tx)
+	pricesSvc.Subscribe(bus)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	s := NewServer(productsSvc, ordersSvc, WithPrices(pricesSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 10, "stock": 1})
+	at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	w := doJSON(t, s, http.MethodPost, "/api/v1/products/1/prices", map[string]any{"price": 8, "effective_from": at})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("schedule %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/products/1/prices", map[string]any{"price": 8, "effective_from": "2000-01-01T00:00:00Z"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for past date, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products/1/prices", nil)
+	var list []domain.PriceChange
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 2 || list[1].Status != domain.PriceChangeStatusScheduled {
+		t.Fatalf("timeline %v %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(t, s, http.MethodDelete, "/api/v1/products/1/prices/2", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("cancel %v", w.Code)
+	}
+	if w = doJSON(t, s, http.MethodDelete, "/api/v1/products/1/prices/1", nil); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 cancelling applied change, got %v", w.Code)
+	}
+	if w = doJSON(t, s, http.MethodGet, "/api/v1/products/9/prices", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+}
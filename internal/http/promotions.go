@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+)
+
+type promotionReq struct {
+	Name        string               `json:"name"`
+	Kind        domain.PromotionKind `json:"kind"`
+	Percent     float64              `json:"percent"`
+	Amount      float64              `json:"amount"`
+	BuyQty      int64                `json:"buy_qty"`
+	FreeQty     int64                `json:"free_qty"`
+	Code        string               `json:"code"`
+	Priority    int                  `json:"priority"`
+	Exclusive   bool                 `json:"exclusive"`
+	ProductIDs  []int64              `json:"product_ids"`
+	CategoryIDs []int64              `json:"category_ids"`
+	StartsAt    *time.Time           `json:"starts_at"`
+	EndsAt      *time.Time           `json:"ends_at"`
+}
+
+func (r promotionReq) promotion(id int64) domain.Promotion {
+	return domain.Promotion{
+		ID: id, Name: r.Name, Kind: r.Kind, Percent: r.Percent, Amount: r.Amount,
+		BuyQty: r.BuyQty, FreeQty: r.FreeQty, Code: r.Code, Priority: r.Priority, Exclusive: r.Exclusive,
+		ProductIDs: r.ProductIDs, CategoryIDs: r.CategoryIDs, StartsAt: r.StartsAt, EndsAt: r.EndsAt,
+	}
+}
+
+// @Summary Create promotion
+// @Description Kinds: percent (percent off), fixed (amount off per unit), buy_get (buy_qty paid + free_qty free).
+// @Description Without product_ids and category_ids the promotion covers the whole catalogue; with code it applies only to orders that pass the code.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param input body promotionReq true "Promotion"
+// @Success 201 {object} domain.Promotion
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /promotions [post]
+func (s *Server) createPromotion(c *gin.Context) {
+	var req promotionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	p, err := s.promotions.Create(c, req.promotion(0))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+// @Summary List promotions
+// @Tags promotions
+// @Produce json
+// @Success 200 {array} domain.Promotion
+// @Router /promotions [get]
+func (s *Server) listPromotions(c *gin.Context) {
+	list, err := s.promotions.List(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get promotion by id
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} domain.Promotion
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promotions/{id} [get]
+func (s *Server) getPromotion(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	p, err := s.promotions.Get(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// @Summary Update promotion
+// @Description Replaces the promotion; already created orders keep their discounts
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param input body promotionReq true "Promotion"
+// @Success 200 {object} domain.Promotion
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /promotions/{id} [put]
+func (s *Server) updatePromotion(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req promotionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	p, err := s.promotions.Update(c, req.promotion(id))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// @Summary Delete promotion
+// @Tags promotions
+// @Param id path int true "Promotion ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promotions/{id} [delete]
+func (s *Server) deletePromotion(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if err := s.promotions.Delete(c, id); err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestPromotionsAndPromoCodes(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	productsSvc := service.NewProductService(store, tx)
+	promotionsSvc := service.NewPromotionService(repository.NewMemoryPromotions(store), store)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	ordersSvc.SetDiscounts(promotionsSvc)
+	s := NewServer(productsSvc, ordersSvc, WithPromotions(promotionsSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 200, "stock": 5})
+	w := doJSON(t, s, http.MethodPost, "/api/v1/promotions", map[string]any{"name": "spring", "kind": "percent", "percent": 10, "code": "spring"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create promotion %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/promotions", map[string]any{"name": "bad", "kind": "percent"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", w.Code)
+	}
+	if w = doJSON(t, s, http.MethodPost, "/api/v1/promotions", map[string]any{"name": "dup", "kind": "fixed", "amount": 1, "code": "SPRING"}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "c", "items": []map[string]any{{"product_id": 1, "quantity": 2}}, "promo_codes": []string{"spring"},
+	})
+	var o domain.Order
+	_ = json.Unmarshal(w.Body.Bytes(), &o)
+	if w.Code != http.StatusCreated || o.Total != 360 || len(o.Items[0].Discounts) != 1 || o.Items[0].Discounts[0].Name != "spring" {
+		t.Fatalf("order with code %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "c", "items": []map[string]any{{"product_id": 1, "quantity": 1}}, "promo_codes": []string{"nope"},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown code, got %v", w.Code)
+	}
+
+	if w = doJSON(t, s, http.MethodPut, "/api/v1/promotions/1", map[string]any{"name": "spring", "kind": "fixed", "amount": 5}); w.Code != http.StatusOK {
+		t.Fatalf("update %v %s", w.Code, w.Body.String())
+	}
+	if w = doJSON(t, s, http.MethodDelete, "/api/v1/promotions/1", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete %v", w.Code)
+	}
+	if w = doJSON(t, s, http.MethodGet, "/api/v1/promotions/1", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+}
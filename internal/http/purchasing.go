@@ -0,0 +1,267 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+type supplierReq struct {
+	Name    string `json:"name"`
+	Contact string `json:"contact"`
+}
+
+// @Summary Create supplier
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param input body supplierReq true "Supplier"
+// @Success 201 {object} domain.Supplier
+// @Failure 400 {object} map[string]string
+// @Router /suppliers [post]
+func (s *Server) createSupplier(c *gin.Context) {
+	var req supplierReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	sup, err := s.purchases.CreateSupplier(c, domain.Supplier{Name: req.Name, Contact: req.Contact})
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sup)
+}
+
+// @Summary List suppliers
+// @Tags purchasing
+// @Produce json
+// @Success 200 {array} domain.Supplier
+// @Router /suppliers [get]
+func (s *Server) listSuppliers(c *gin.Context) {
+	list, err := s.purchases.ListSuppliers(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get supplier by id
+// @Tags purchasing
+// @Produce json
+// @Param id path int true "Supplier ID"
+// @Success 200 {object} domain.Supplier
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /suppliers/{id} [get]
+func (s *Server) getSupplier(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	sup, err := s.purchases.GetSupplier(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sup)
+}
+
+// @Summary Update supplier
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param id path int true "Supplier ID"
+// @Param input body supplierReq true "Supplier"
+// @Success 200 {object} domain.Supplier
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /suppliers/{id} [put]
+func (s *Server) updateSupplier(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req supplierReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	sup, err := s.purchases.UpdateSupplier(c, domain.Supplier{ID: id, Name: req.Name, Contact: req.Contact})
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sup)
+}
+
+type createPurchaseOrderReq struct {
+	SupplierID int64                      `json:"supplier_id"`
+	Lines      []domain.PurchaseOrderLine `json:"lines"`
+}
+
+// @Summary Create purchase order
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param input body createPurchaseOrderReq true "Purchase order"
+// @Success 201 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /purchase-orders [post]
+func (s *Server) createPurchaseOrder(c *gin.Context) {
+	var req createPurchaseOrderReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	po, err := s.purchases.CreatePurchaseOrder(c, req.SupplierID, req.Lines)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, po)
+}
+
+// @Summary List purchase orders
+// @Tags purchasing
+// @Produce json
+// @Param supplier_id query int false "Supplier ID"
+// @Param status query string false "Status"
+// @Success 200 {array} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Router /purchase-orders [get]
+func (s *Server) listPurchaseOrders(c *gin.Context) {
+	var f repository.PurchaseOrderFilter
+	if v := c.Query("supplier_id"); v != "" {
+		id, err := parseID(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid supplier_id"})
+			return
+		}
+		f.SupplierID = id
+	}
+	f.Status = domain.PurchaseOrderStatus(c.Query("status"))
+	list, err := s.purchases.ListPurchaseOrders(c, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get purchase order by id
+// @Tags purchasing
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /purchase-orders/{id} [get]
+func (s *Server) getPurchaseOrder(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	po, err := s.purchases.GetPurchaseOrder(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+// @Summary Send purchase order to supplier
+// @Tags purchasing
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /purchase-orders/{id}/send [post]
+func (s *Server) sendPurchaseOrder(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	po, err := s.purchases.SendPurchaseOrder(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+// @Summary Cancel purchase order
+// @Tags purchasing
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /purchase-orders/{id}/cancel [post]
+func (s *Server) cancelPurchaseOrder(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	po, err := s.purchases.CancelPurchaseOrder(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
+
+type receiveGoodsReq struct {
+	Lines []domain.ReceiptLine `json:"lines"`
+}
+
+// @Summary Receive goods for purchase order
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Param input body receiveGoodsReq true "Received lines"
+// @Success 200 {object} domain.PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /purchase-orders/{id}/receive [post]
+func (s *Server) receiveGoods(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req receiveGoodsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	po, err := s.purchases.ReceiveGoods(c, id, req.Lines)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, po)
+}
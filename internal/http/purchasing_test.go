@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestPurchasingFlow(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	productsSvc := service.NewProductService(store, tx)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	purchasesSvc := service.NewPurchaseService(store, repository.NewMemorySuppliers(store), repository.NewMemoryPurchaseOrders(store), tx)
+	s := NewServer(productsSvc, ordersSvc, WithPurchasing(purchasesSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 1, "stock": 0})
+	w := doJSON(t, s, http.MethodPost, "/api/v1/suppliers", map[string]any{"name": "Farm", "contact": "farm@example.com"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create supplier %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/purchase-orders", map[string]any{
+		"supplier_id": 1,
+		"lines":       []map[string]any{{"product_id": 1, "quantity": 5, "unit_cost": 0.5}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create po %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/purchase-orders/1/receive", map[string]any{
+		"lines": []map[string]any{{"product_id": 1, "quantity": 5}},
+	})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 receiving draft, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/purchase-orders/1/send", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("send %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/purchase-orders/1/receive", map[string]any{
+		"lines": []map[string]any{{"product_id": 1, "quantity": 5}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("receive %v", w.Code)
+	}
+	var po domain.PurchaseOrder
+	_ = json.Unmarshal(w.Body.Bytes(), &po)
+	if po.Status != domain.PurchaseOrderStatusReceived {
+		t.Fatalf("expected received, got %v", po.Status)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products/1", nil)
+	var p domain.Product
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if p.Stock != 5 {
+		t.Fatalf("stock expected 5, got %v", p.Stock)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/purchase-orders?status=Received&supplier_id=1", nil)
+	var list []domain.PurchaseOrder
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 {
+		t.Fatalf("list %v %d", w.Code, len(list))
+	}
+}
@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestProductSearch(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetEvents(bus)
+	searchSvc := service.NewSearchService(store)
+	searchSvc.Subscribe(bus)
+	productsSvc.SetSearch(searchSvc)
+	s := NewServer(productsSvc, service.NewOrderService(store, repository.NewMemoryOrders(store), tx))
+
+	for _, name := range []string{"Аспирин", "Парацетамол", "Аспирин Кардио"} {
+		if w := doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": name, "sku": name, "price": 1}); w.Code != http.StatusCreated {
+			t.Fatalf("create %v", w.Code)
+		}
+	}
+	w := doJSON(t, s, http.MethodGet, "/api/v1/products?q=aspirina", nil)
+	var list []domain.Product
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &list) != nil || len(list) != 2 || list[0].ID != 1 {
+		t.Fatalf("search %v %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,112 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+type registerSerialsReq struct {
+	ProductID int64    `json:"product_id"`
+	Batch     string   `json:"batch"`
+	Codes     []string `json:"codes"`
+}
+
+// @Summary Register serial codes for packs already in stock
+// @Tags serials
+// @Accept json
+// @Produce json
+// @Param input body registerSerialsReq true "Serial codes"
+// @Success 201 {array} domain.SerialUnit
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /serials [post]
+func (s *Server) registerSerials(c *gin.Context) {
+	var req registerSerialsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	units, err := s.serials.Register(c, req.ProductID, req.Batch, req.Codes)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, units)
+}
+
+// @Summary List serialised units
+// @Tags serials
+// @Produce json
+// @Param product_id query int false "Product ID"
+// @Param batch_id query int false "Batch ID"
+// @Param order_id query int false "Order ID"
+// @Param status query string false "InStock or Sold"
+// @Success 200 {array} domain.SerialUnit
+// @Failure 400 {object} map[string]string
+// @Router /serials [get]
+func (s *Server) listSerials(c *gin.Context) {
+	var f repository.SerialFilter
+	for param, dst := range map[string]*int64{"product_id": &f.ProductID, "batch_id": &f.BatchID, "order_id": &f.OrderID} {
+		if v := c.Query(param); v != "" {
+			id, err := parseID(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+				return
+			}
+			*dst = id
+		}
+	}
+	f.Status = domain.SerialStatus(c.Query("status"))
+	list, err := s.serials.List(c, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get serialised unit by code
+// @Tags serials
+// @Produce json
+// @Param code path string true "Serial code"
+// @Success 200 {object} domain.SerialUnit
+// @Failure 404 {object} map[string]string
+// @Router /serials/{code} [get]
+func (s *Server) getSerial(c *gin.Context) {
+	u, err := s.serials.Get(c, c.Param("code"))
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// @Summary List product batches
+// @Tags serials
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} domain.Batch
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /products/{id}/batches [get]
+func (s *Server) productBatches(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	list, err := s.serials.Batches(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestSerialsFlow(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	batches := repository.NewMemoryBatches(store)
+	serials := repository.NewMemorySerials(store)
+	productsSvc := service.NewProductService(store, tx)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	ordersSvc.SetSerials(serials)
+	s := NewServer(productsSvc, ordersSvc, WithSerials(service.NewSerialService(store, batches, serials, tx)))
+
+	w := doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{
+		"name": "Insulin", "sku": "I1", "price": 10, "stock": 2, "serialized": true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create product %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/serials", map[string]any{"product_id": 1, "batch": "B1", "codes": []string{"c1", "c2"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "A", "items": []map[string]any{{"product_id": 1, "quantity": 1}},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without codes, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "A", "items": []map[string]any{{"product_id": 1, "quantity": 1, "serials": []string{"c1"}}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create order %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "B", "items": []map[string]any{{"product_id": 1, "quantity": 1, "serials": []string{"c1"}}},
+	})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for sold code, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodGet, "/api/v1/serials/c1", nil)
+	var u domain.SerialUnit
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &u) != nil || u.Status != domain.SerialStatusSold || u.OrderID != 1 {
+		t.Fatalf("get serial %v %s", w.Code, w.Body.String())
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/serials?product_id=1&status=InStock", nil)
+	var list []domain.SerialUnit
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &list) != nil || len(list) != 1 || list[0].Code != "c2" {
+		t.Fatalf("list serials %v %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/serials?order_id=x", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %v", w.Code)
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/1/batches", nil); w.Code != http.StatusOK {
+		t.Fatalf("batches %v", w.Code)
+	}
+}
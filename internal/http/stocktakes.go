@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"april/internal/domain"
+)
+
+type startStocktakeReq struct {
+	ProductIDs []int64 `json:"product_ids"`
+}
+
+// @Summary Start stocktake
+// @Description Snapshots expected stock for the given products (whole catalogue if empty)
+// @Tags stocktakes
+// @Accept json
+// @Produce json
+// @Param input body startStocktakeReq false "Products to count"
+// @Success 201 {object} domain.Stocktake
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /stocktakes [post]
+func (s *Server) startStocktake(c *gin.Context) {
+	var req startStocktakeReq
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+			return
+		}
+	}
+	st, err := s.stocktakes.Start(c, req.ProductIDs)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, st)
+}
+
+// @Summary List stocktakes
+// @Tags stocktakes
+// @Produce json
+// @Success 200 {array} domain.Stocktake
+// @Router /stocktakes [get]
+func (s *Server) listStocktakes(c *gin.Context) {
+	list, err := s.stocktakes.List(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary Get stocktake by id
+// @Tags stocktakes
+// @Produce json
+// @Param id path int true "Stocktake ID"
+// @Success 200 {object} domain.Stocktake
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /stocktakes/{id} [get]
+func (s *Server) getStocktake(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	st, err := s.stocktakes.Get(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+type submitCountsReq struct {
+	Counts []domain.StockCount `json:"counts"`
+}
+
+// @Summary Submit counted quantities
+// @Description Quantities are added to previous submissions for the same product
+// @Tags stocktakes
+// @Accept json
+// @Produce json
+// @Param id path int true "Stocktake ID"
+// @Param input body submitCountsReq true "Counts"
+// @Success 200 {object} domain.Stocktake
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /stocktakes/{id}/counts [post]
+func (s *Server) submitCounts(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req submitCountsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+		return
+	}
+	st, err := s.stocktakes.SubmitCounts(c, id, req.Counts)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// @Summary Stocktake variances
+// @Tags stocktakes
+// @Produce json
+// @Param id path int true "Stocktake ID"
+// @Success 200 {array} domain.StocktakeLine
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /stocktakes/{id}/variances [get]
+func (s *Server) stocktakeVariances(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	lines, err := s.stocktakes.Variances(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lines)
+}
+
+// @Summary Approve stocktake
+// @Description Applies variances to current stock atomically
+// @Tags stocktakes
+// @Produce json
+// @Param id path int true "Stocktake ID"
+// @Success 200 {object} domain.Stocktake
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /stocktakes/{id}/approve [post]
+func (s *Server) approveStocktake(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	st, err := s.stocktakes.Approve(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// @Summary Cancel stocktake
+// @Tags stocktakes
+// @Produce json
+// @Param id path int true "Stocktake ID"
+// @Success 200 {object} domain.Stocktake
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /stocktakes/{id}/cancel [post]
+func (s *Server) cancelStocktake(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	st, err := s.stocktakes.Cancel(c, id)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestStocktakeFlow(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	productsSvc := service.NewProductService(store, tx)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	stocktakesSvc := service.NewStocktakeService(store, repository.NewMemoryStocktakes(store), tx)
+	s := NewServer(productsSvc, ordersSvc, WithStocktakes(stocktakesSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "A", "sku": "S1", "price": 1, "stock": 10})
+	w := doJSON(t, s, http.MethodPost, "/api/v1/stocktakes", map[string]any{"product_ids": []int64{1}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("start %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/stocktakes/1/counts", map[string]any{
+		"counts": []map[string]any{{"product_id": 1, "quantity": 12}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("counts %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/stocktakes/1/variances", nil)
+	var lines []domain.StocktakeLine
+	_ = json.Unmarshal(w.Body.Bytes(), &lines)
+	if w.Code != http.StatusOK || len(lines) != 1 || lines[0].Variance != 2 {
+		t.Fatalf("variances %v %+v", w.Code, lines)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/stocktakes/1/approve", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodPost, "/api/v1/stocktakes/1/approve", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on second approve, got %v", w.Code)
+	}
+	w = doJSON(t, s, http.MethodGet, "/api/v1/products/1", nil)
+	var p domain.Product
+	_ = json.Unmarshal(w.Body.Bytes(), &p)
+	if p.Stock != 12 {
+		t.Fatalf("stock expected 12, got %v", p.Stock)
+	}
+
+	// пустое тело — весь каталог
+	w = doJSON(t, s, http.MethodPost, "/api/v1/stocktakes", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("start whole catalogue %v", w.Code)
+	}
+}
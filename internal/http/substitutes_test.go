@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestSubstitutesFlow(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	categoriesRepo := repository.NewMemoryCategories(store)
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetCategories(categoriesRepo)
+	ordersSvc := service.NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	s := NewServer(productsSvc, ordersSvc, WithCategories(service.NewCategoryService(categoriesRepo, store, tx)))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/categories", map[string]any{"name": "Meds", "attributes": []map[string]any{
+		{"code": "active_substance", "name": "МНН", "type": "string"},
+		{"code": "dosage", "name": "Дозировка", "type": "string"},
+	}})
+	for _, p := range []map[string]any{
+		{"name": "Nurofen", "sku": "S1", "price": 300, "stock": 1},
+		{"name": "Ibuprofen", "sku": "S2", "price": 80, "stock": 5},
+	} {
+		p["category_ids"] = []int64{1}
+		p["attributes"] = map[string]string{"active_substance": "ibuprofen", "dosage": "200 mg"}
+		if w := doJSON(t, s, http.MethodPost, "/api/v1/products", p); w.Code != http.StatusCreated {
+			t.Fatalf("create product %v %s", w.Code, w.Body.String())
+		}
+	}
+
+	w := doJSON(t, s, http.MethodGet, "/api/v1/products/1/substitutes", nil)
+	var list []domain.Product
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &list) != nil || len(list) != 1 || list[0].ID != 2 {
+		t.Fatalf("substitutes %v %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/9/substitutes", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", w.Code)
+	}
+
+	w = doJSON(t, s, http.MethodPost, "/api/v1/orders", map[string]any{
+		"customer_name": "A", "items": []map[string]any{{"product_id": 1, "quantity": 2}}, "suggest_substitutes": true,
+	})
+	var resp struct {
+		Available   int64            `json:"available"`
+		Substitutes []domain.Product `json:"substitutes"`
+	}
+	if w.Code != http.StatusBadRequest || json.Unmarshal(w.Body.Bytes(), &resp) != nil || resp.Available != 1 || len(resp.Substitutes) != 1 {
+		t.Fatalf("expected stock error with substitutes, got %v %s", w.Code, w.Body.String())
+	}
+}
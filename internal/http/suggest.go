@@ -0,0 +1,36 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Autocomplete product names
+// @Description In-stock products first, then by units ordered
+// @Tags products
+// @Produce json
+// @Param prefix query string true "Beginning of the name, every word is matched as a prefix"
+// @Param limit query int false "Max suggestions (default 10, max 50)"
+// @Success 200 {array} service.Suggestion
+// @Failure 400 {object} map[string]string
+// @Router /products/suggest [get]
+func (s *Server) suggestProducts(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+	list, err := s.suggest.Suggest(c, c.Query("prefix"), limit)
+	if err != nil {
+		status := mapErrorToStatus(err)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/service"
+)
+
+func TestSuggestEndpoint(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	productsSvc := service.NewProductService(store, tx)
+	productsSvc.SetEvents(bus)
+	suggestSvc := service.NewSuggestService(store, repository.NewMemoryOrders(store))
+	suggestSvc.Subscribe(bus)
+	s := NewServer(productsSvc, service.NewOrderService(store, repository.NewMemoryOrders(store), tx), WithSuggestions(suggestSvc))
+
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "Нурофен", "sku": "N1", "price": 1, "stock": 1})
+	_ = doJSON(t, s, http.MethodPost, "/api/v1/products", map[string]any{"name": "Нурофен Экспресс", "sku": "N2", "price": 1})
+
+	w := doJSON(t, s, http.MethodGet, "/api/v1/products/suggest?prefix=nur&limit=5", nil)
+	var list []service.Suggestion
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &list) != nil || len(list) != 2 || list[0].ID != 1 {
+		t.Fatalf("suggest %v %s", w.Code, w.Body.String())
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/suggest?prefix=", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for empty prefix, got %v", w.Code)
+	}
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/suggest?prefix=n&limit=x", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad limit, got %v", w.Code)
+	}
+	// маршрут /products/:id не перехватывает suggest
+	if w := doJSON(t, s, http.MethodGet, "/api/v1/products/1", nil); w.Code != http.StatusOK {
+		t.Fatalf("get product %v", w.Code)
+	}
+}
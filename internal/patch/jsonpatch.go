@@ -0,0 +1,276 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation операция JSON Patch; Value задан только для add, replace и test
+type Operation struct {
+	Op    string
+	Path  string
+	From  string
+	Value any
+}
+
+// JSONPatch последовательность операций RFC 6902; применяется целиком или не применяется
+type JSONPatch []Operation
+
+// ParseJSON разбирает массив операций и проверяет обязательные члены каждой
+func ParseJSON(data []byte) (JSONPatch, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	out := make(JSONPatch, 0, len(raw))
+	for i, m := range raw {
+		var op Operation
+		if err := member(m, "op", &op.Op); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalid, i, err)
+		}
+		if err := member(m, "path", &op.Path); err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalid, i, err)
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			if err := member(m, "value", &op.Value); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalid, i, err)
+			}
+		case "move", "copy":
+			if err := member(m, "from", &op.From); err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalid, i, err)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("%w: operation %d: unknown op %q", ErrInvalid, i, op.Op)
+		}
+		out = append(out, op)
+	}
+	return out, nil
+}
+
+// member читает обязательный член операции
+func member(m map[string]json.RawMessage, name string, dst any) error {
+	raw, ok := m[name]
+	if !ok {
+		return fmt.Errorf("missing %q", name)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("%q: %v", name, err)
+	}
+	return nil
+}
+
+func (p JSONPatch) Apply(doc any) (any, error) {
+	doc = clone(doc)
+	for i, op := range p {
+		var err error
+		if doc, err = apply(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func apply(doc any, op Operation) (any, error) {
+	path, err := pointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		return add(doc, path, clone(op.Value))
+	case "remove":
+		doc, _, err = remove(doc, path)
+		return doc, err
+	case "replace":
+		if len(path) == 0 {
+			return clone(op.Value), nil
+		}
+		if doc, _, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+		return add(doc, path, clone(op.Value))
+	case "move":
+		from, err := pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalid)
+		}
+		doc, v, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+	case "copy":
+		from, err := pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, clone(v))
+	case "test":
+		v, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(v, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown op %q", ErrInvalid, op.Op)
+}
+
+// pointer разбирает JSON Pointer (RFC 6901) в последовательность ключей
+func pointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalid, s)
+	}
+	parts := strings.Split(s[1:], "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+func get(doc any, path []string) (any, error) {
+	for _, key := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[key]
+			if !ok {
+				return nil, notFound(key)
+			}
+			doc = v
+		case []any:
+			i, err := index(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, notFound(key)
+		}
+	}
+	return doc, nil
+}
+
+// update находит контейнер, содержащий последний ключ пути, и заменяет его результатом leaf
+func update(doc any, path []string, leaf func(container any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return leaf(doc, path[0])
+	}
+	key := path[0]
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = update(child, path[1:], leaf); err != nil {
+		return nil, err
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		c[key] = child
+	case []any:
+		i, _ := index(key, len(c)-1)
+		c[i] = child
+	}
+	return doc, nil
+}
+
+func add(doc any, path []string, v any) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	return update(doc, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[key] = v
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, v), nil
+			}
+			i, err := index(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], append([]any{v}, c[i:]...)...), nil
+		}
+		return nil, notFound(key)
+	})
+}
+
+func remove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the document root", ErrInvalid)
+	}
+	var removed any
+	doc, err := update(doc, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			v, ok := c[key]
+			if !ok {
+				return nil, notFound(key)
+			}
+			removed = v
+			delete(c, key)
+			return c, nil
+		case []any:
+			i, err := index(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, notFound(key)
+	})
+	return doc, removed, err
+}
+
+// index разбирает индекс массива в пределах [0, last]
+func index(key string, last int) (int, error) {
+	if key == "" || (len(key) > 1 && key[0] == '0') {
+		return 0, notFound(key)
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i > last {
+		return 0, notFound(key)
+	}
+	return i, nil
+}
+
+func notFound(key string) error {
+	return fmt.Errorf("%w: %q not found", ErrInvalid, key)
+}
+
+func clone(v any) any {
+	switch c := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(c))
+		for k, x := range c {
+			out[k] = clone(x)
+		}
+		return out
+	case []any:
+		out := make([]any, len(c))
+		for i, x := range c {
+			out[i] = clone(x)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,57 @@
+// Package patch — частичное изменение JSON-документов: JSON Merge Patch (RFC 7396)
+// и JSON Patch (RFC 6902). Документ — значение, полученное json.Unmarshal в any.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalid некорректный патч или путь, отсутствующий в документе
+	ErrInvalid = errors.New("invalid patch")
+	// ErrTestFailed значение не совпало в операции test
+	ErrTestFailed = errors.New("test operation failed")
+)
+
+// Patch изменение документа. Apply может менять переданный документ.
+type Patch interface {
+	Apply(doc any) (any, error)
+}
+
+// MergePatch документ RFC 7396: объекты сливаются, null удаляет ключ,
+// остальные значения заменяют целиком
+type MergePatch struct{ patch any }
+
+// ParseMerge разбирает тело merge-патча
+func ParseMerge(data []byte) (MergePatch, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return MergePatch{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return MergePatch{patch: v}, nil
+}
+
+func (p MergePatch) Apply(doc any) (any, error) {
+	return merge(doc, p.patch), nil
+}
+
+func merge(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = make(map[string]any, len(pm))
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+			continue
+		}
+		tm[k] = merge(tm[k], v)
+	}
+	return tm
+}
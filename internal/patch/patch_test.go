@@ -0,0 +1,113 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestMergePatch_RFC7396(t *testing.T) {
+	cases := []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		p, err := ParseMerge([]byte(tc.patch))
+		if err != nil {
+			t.Fatalf("parse %s: %v", tc.patch, err)
+		}
+		got, _ := p.Apply(decode(t, tc.target))
+		if want := decode(t, tc.want); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s + %s = %v, want %v", tc.target, tc.patch, got, want)
+		}
+	}
+}
+
+func TestJSONPatch_RFC6902(t *testing.T) {
+	cases := []struct{ doc, patch, want string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"a/b":1,"m~n":2}`, `[{"op":"copy","from":"/a~1b","path":"/m~0n"}]`, `{"a/b":1,"m~n":1}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+	}
+	for _, tc := range cases {
+		p, err := ParseJSON([]byte(tc.patch))
+		if err != nil {
+			t.Fatalf("parse %s: %v", tc.patch, err)
+		}
+		got, err := p.Apply(decode(t, tc.doc))
+		if err != nil {
+			t.Fatalf("apply %s: %v", tc.patch, err)
+		}
+		if want := decode(t, tc.want); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s + %s = %v, want %v", tc.doc, tc.patch, got, want)
+		}
+	}
+}
+
+func TestJSONPatch_Errors(t *testing.T) {
+	for _, bad := range []string{
+		`{"op":"add"}`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"jump","path":"/a"}]`,
+		`[{"op":"move","path":"/a"}]`,
+	} {
+		if _, err := ParseJSON([]byte(bad)); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: expected invalid patch, got %v", bad, err)
+		}
+	}
+
+	doc := `{"foo":"bar","list":[1]}`
+	for _, bad := range []string{
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"add","path":"/missing/child","value":1}]`,
+		`[{"op":"replace","path":"/list/1","value":1}]`,
+		`[{"op":"add","path":"/list/01","value":1}]`,
+		`[{"op":"move","from":"/list","path":"/list/0"}]`,
+		`[{"op":"add","path":"foo","value":1}]`,
+	} {
+		p, err := ParseJSON([]byte(bad))
+		if err != nil {
+			t.Fatalf("parse %s: %v", bad, err)
+		}
+		if _, err := p.Apply(decode(t, doc)); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("%s: expected invalid patch, got %v", bad, err)
+		}
+	}
+
+	// неудачный test отменяет весь патч, исходный документ не меняется
+	original := decode(t, doc)
+	p, _ := ParseJSON([]byte(`[{"op":"replace","path":"/foo","value":"baz"},{"op":"test","path":"/foo","value":"bar"}]`))
+	if _, err := p.Apply(original); !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("expected test failure, got %v", err)
+	}
+	if !reflect.DeepEqual(original, decode(t, doc)) {
+		t.Fatalf("document changed by failed patch: %v", original)
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,19 +11,79 @@ import (
 
 // MemoryStore объединённое in-memory хранилище и простой генератор ID
 type MemoryStore struct {
-	mu           sync.RWMutex
-	nextProdID   int64
-	nextOrderID  int64
-	productsByID map[int64]domain.Product
-	ordersByID   map[int64]domain.Order
+	mu              sync.RWMutex
+	nextProdID      int64
+	nextOrderID     int64
+	nextAlertID     int64
+	nextSupplierID  int64
+	nextPurchaseID  int64
+	nextStocktakeID int64
+	nextCategoryID  int64
+	nextBatchID     int64
+	nextPriceID     int64
+	nextPromoID     int64
+	nextPaymentID   int64
+	nextRefundID    int64
+	nextCreditID    int64
+	nextCreditTxID  int64
+	nextLoyaltyID   int64
+	nextCartID      int64
+	nextOrderEditID int64
+	productsByID    map[int64]domain.Product
+	ordersByID      map[int64]domain.Order
+	alertsByID      map[int64]domain.LowStockAlert
+	suppliersByID   map[int64]domain.Supplier
+	purchasesByID   map[int64]domain.PurchaseOrder
+	stocktakesByID  map[int64]domain.Stocktake
+	categoriesByID  map[int64]domain.Category
+	batchesByID     map[int64]domain.Batch
+	serialsByCode   map[string]domain.SerialUnit
+	pricesByID      map[int64]domain.PriceChange
+	promotionsByID  map[int64]domain.Promotion
+	paymentsByID    map[int64]domain.Payment
+	refundsByID     map[int64]domain.Refund
+	creditsByID     map[int64]domain.CreditAccount
+	creditTxs       []domain.CreditTransaction
+	loyaltyRules    domain.LoyaltyRules
+	loyaltyEntries  []domain.LoyaltyEntry
+	cartsByID       map[int64]domain.Cart
+	orderEdits      []domain.OrderEdit
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		nextProdID:   1,
-		nextOrderID:  1,
-		productsByID: make(map[int64]domain.Product),
-		ordersByID:   make(map[int64]domain.Order),
+		nextProdID:      1,
+		nextOrderID:     1,
+		nextAlertID:     1,
+		nextSupplierID:  1,
+		nextPurchaseID:  1,
+		nextStocktakeID: 1,
+		nextCategoryID:  1,
+		nextBatchID:     1,
+		nextPriceID:     1,
+		nextPromoID:     1,
+		nextPaymentID:   1,
+		nextRefundID:    1,
+		nextCreditID:    1,
+		nextCreditTxID:  1,
+		nextLoyaltyID:   1,
+		nextCartID:      1,
+		nextOrderEditID: 1,
+		productsByID:    make(map[int64]domain.Product),
+		ordersByID:      make(map[int64]domain.Order),
+		alertsByID:      make(map[int64]domain.LowStockAlert),
+		suppliersByID:   make(map[int64]domain.Supplier),
+		purchasesByID:   make(map[int64]domain.PurchaseOrder),
+		stocktakesByID:  make(map[int64]domain.Stocktake),
+		categoriesByID:  make(map[int64]domain.Category),
+		batchesByID:     make(map[int64]domain.Batch),
+		serialsByCode:   make(map[string]domain.SerialUnit),
+		pricesByID:      make(map[int64]domain.PriceChange),
+		promotionsByID:  make(map[int64]domain.Promotion),
+		paymentsByID:    make(map[int64]domain.Payment),
+		refundsByID:     make(map[int64]domain.Refund),
+		creditsByID:     make(map[int64]domain.CreditAccount),
+		cartsByID:       make(map[int64]domain.Cart),
 	}
 }
 
@@ -64,13 +125,110 @@ var _ ProductRepository = (*MemoryStore)(nil)
 
 // OrderRepository будет реализован отдельным типом MemoryOrders
 
+// cloneProduct копирует слайсы товара, чтобы хранилище не разделяло их с вызывающим
+func cloneProduct(p domain.Product) domain.Product {
+	p.CategoryIDs = append([]int64(nil), p.CategoryIDs...)
+	p.Barcodes = append([]string(nil), p.Barcodes...)
+	if p.Attributes != nil {
+		attrs := make(map[string]string, len(p.Attributes))
+		for k, v := range p.Attributes {
+			attrs[k] = v
+		}
+		p.Attributes = attrs
+	}
+	if p.ArchivedAt != nil {
+		at := *p.ArchivedAt
+		p.ArchivedAt = &at
+	}
+	return p
+}
+
+// cloneOrder копирует заказ вместе со строками, чтобы хранилище не делило слайсы с вызывающим
+func cloneOrder(o domain.Order) domain.Order {
+	o.PromoCodes = append([]string(nil), o.PromoCodes...)
+	items := make([]domain.OrderItem, len(o.Items))
+	for i, it := range o.Items {
+		it.Serials = append([]string(nil), it.Serials...)
+		it.Discounts = append([]domain.LineDiscount(nil), it.Discounts...)
+		items[i] = it
+	}
+	o.Items = items
+	return o
+}
+
+// productReferenced сообщает, что товар встречается в заказах, заказах поставщикам,
+// инвентаризациях, сериях, маркировке, корзинах или акциях
+func (m *MemoryStore) productReferenced(id int64) bool {
+	for _, o := range m.ordersByID {
+		for _, it := range o.Items {
+			if it.ProductID == id {
+				return true
+			}
+		}
+	}
+	for _, po := range m.purchasesByID {
+		for _, l := range po.Lines {
+			if l.ProductID == id {
+				return true
+			}
+		}
+	}
+	for _, st := range m.stocktakesByID {
+		for _, l := range st.Lines {
+			if l.ProductID == id {
+				return true
+			}
+		}
+	}
+	for _, b := range m.batchesByID {
+		if b.ProductID == id {
+			return true
+		}
+	}
+	for _, u := range m.serialsByCode {
+		if u.ProductID == id {
+			return true
+		}
+	}
+	for _, c := range m.cartsByID {
+		for _, it := range c.Items {
+			if it.ProductID == id {
+				return true
+			}
+		}
+	}
+	for _, pr := range m.promotionsByID {
+		for _, pid := range pr.ProductIDs {
+			if pid == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// barcodeTaken сообщает, что один из штрихкодов уже занят другим товаром
+func (m *MemoryStore) barcodeTaken(codes []string, exceptID int64) bool {
+	for _, p := range m.productsByID {
+		if p.ID != exceptID && hasAny(p.Barcodes, codes) {
+			return true
+		}
+	}
+	return false
+}
+
 // ProductRepository implementation
 func (m *MemoryStore) Create(ctx context.Context, p *domain.Product) error {
 	m.wlock(ctx)
 	defer m.wunlock(ctx)
+	if m.barcodeTaken(p.Barcodes, 0) {
+		return ErrConflict
+	}
 	p.ID = m.nextProdID
 	m.nextProdID++
-	m.productsByID[p.ID] = *p
+	p.CreatedAt = time.Now().UTC()
+	p.UpdatedAt = p.CreatedAt
+	m.productsByID[p.ID] = cloneProduct(*p)
 	return nil
 }
 
@@ -82,27 +240,55 @@ func (m *MemoryStore) GetByID(ctx context.Context, id int64) (*domain.Product, e
 		return nil, ErrNotFound
 	}
 	// return copy
-	cp := p
+	cp := cloneProduct(p)
 	return &cp, nil
 }
 
+func (m *MemoryStore) GetByBarcode(ctx context.Context, code string) (*domain.Product, error) {
+	m.rlock(ctx)
+	defer m.runlock(ctx)
+	for _, p := range m.productsByID {
+		if hasAny(p.Barcodes, []string{code}) {
+			cp := cloneProduct(p)
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
 func (m *MemoryStore) Update(ctx context.Context, p *domain.Product) error {
 	m.wlock(ctx)
 	defer m.wunlock(ctx)
-	if _, ok := m.productsByID[p.ID]; !ok {
+	prev, ok := m.productsByID[p.ID]
+	if !ok {
 		return ErrNotFound
 	}
-	m.productsByID[p.ID] = *p
+	if m.barcodeTaken(p.Barcodes, p.ID) {
+		return ErrConflict
+	}
+	p.CreatedAt = prev.CreatedAt
+	p.UpdatedAt = time.Now().UTC()
+	m.productsByID[p.ID] = cloneProduct(*p)
 	return nil
 }
 
+// Delete удаляет товар вместе с историей цен; ErrConflict, пока на него ссылаются другие сущности
 func (m *MemoryStore) Delete(ctx context.Context, id int64) error {
 	m.wlock(ctx)
 	defer m.wunlock(ctx)
 	if _, ok := m.productsByID[id]; !ok {
 		return ErrNotFound
 	}
+	if m.productReferenced(id) {
+		return ErrConflict
+	}
 	delete(m.productsByID, id)
+	// история цен принадлежит товару и удаляется вместе с ним
+	for cid, c := range m.pricesByID {
+		if c.ProductID == id {
+			delete(m.pricesByID, cid)
+		}
+	}
 	return nil
 }
 
@@ -110,19 +296,37 @@ func (m *MemoryStore) List(ctx context.Context, f ProductFilter) ([]domain.Produ
 	m.rlock(ctx)
 	defer m.runlock(ctx)
 	out := make([]domain.Product, 0)
-	for _, p := range m.productsByID {
-		if !containsIgnoreCase(p.Name, f.NameSubstring) {
-			continue
-		}
-		if f.MinPrice != nil && p.Price < *f.MinPrice {
-			continue
+	for _, p := range m.filterProducts(f) {
+		out = append(out, cloneProduct(p))
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Facets(ctx context.Context, f ProductFilter, opts FacetOptions) (*domain.ProductFacets, error) {
+	m.rlock(ctx)
+	defer m.runlock(ctx)
+	return computeFacets(m.filterProducts(f), opts), nil
+}
+
+// filterProducts выбирает товары под фильтр без копирования; вызывать под локом
+func (m *MemoryStore) filterProducts(f ProductFilter) []domain.Product {
+	candidates := m.productsByID
+	if f.IDs != nil {
+		// выборка по id не должна просматривать весь каталог
+		candidates = make(map[int64]domain.Product, len(f.IDs))
+		for _, id := range f.IDs {
+			if p, ok := m.productsByID[id]; ok {
+				candidates[id] = p
+			}
 		}
-		if f.MaxPrice != nil && p.Price > *f.MaxPrice {
-			continue
+	}
+	out := make([]domain.Product, 0)
+	for _, p := range candidates {
+		if matchesProduct(p, f) {
+			out = append(out, p)
 		}
-		out = append(out, p)
 	}
-	return out, nil
+	return out
 }
 
 // OrderRepository implementation on wrapper type
@@ -139,7 +343,7 @@ func (mo *MemoryOrders) Create(ctx context.Context, o *domain.Order) error {
 	mo.store.nextOrderID++
 	o.CreatedAt = time.Now().UTC()
 	o.UpdatedAt = o.CreatedAt
-	mo.store.ordersByID[o.ID] = *o
+	mo.store.ordersByID[o.ID] = cloneOrder(*o)
 	return nil
 }
 
@@ -150,7 +354,7 @@ func (mo *MemoryOrders) GetByID(ctx context.Context, id int64) (*domain.Order, e
 	if !ok {
 		return nil, ErrNotFound
 	}
-	cp := o
+	cp := cloneOrder(o)
 	return &cp, nil
 }
 
@@ -161,16 +365,37 @@ func (mo *MemoryOrders) Update(ctx context.Context, o *domain.Order) error {
 		return ErrNotFound
 	}
 	o.UpdatedAt = time.Now().UTC()
-	mo.store.ordersByID[o.ID] = *o
+	mo.store.ordersByID[o.ID] = cloneOrder(*o)
 	return nil
 }
 
+func (mo *MemoryOrders) List(ctx context.Context, f OrderFilter) ([]domain.Order, error) {
+	mo.store.rlock(ctx)
+	defer mo.store.runlock(ctx)
+	out := make([]domain.Order, 0)
+	for _, o := range mo.store.ordersByID {
+		if f.Status != "" && o.Status != f.Status {
+			continue
+		}
+		if !inRange(o.CreatedAt, f.CreatedFrom, f.CreatedTo) {
+			continue
+		}
+		out = append(out, cloneOrder(o))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
 // Tx manager using write lock to emulate transaction boundary
 type MemoryTx struct{ store *MemoryStore }
 
 func NewMemoryTx(store *MemoryStore) *MemoryTx { return &MemoryTx{store: store} }
 
 func (tx *MemoryTx) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// вложенная транзакция выполняется в рамках внешней: блокировка уже взята
+	if isTx(ctx) {
+		return fn(ctx)
+	}
 	// Для in-memory используем блокировку записи и помечаем контекст, чтобы репозитории пропускали внутренние локи
 	tx.store.mu.Lock()
 	defer tx.store.mu.Unlock()
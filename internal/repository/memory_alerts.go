@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryAlerts реализация AlertRepository поверх MemoryStore
+type MemoryAlerts struct{ store *MemoryStore }
+
+func NewMemoryAlerts(store *MemoryStore) *MemoryAlerts { return &MemoryAlerts{store: store} }
+
+var _ AlertRepository = (*MemoryAlerts)(nil)
+
+func (ma *MemoryAlerts) Create(ctx context.Context, a *domain.LowStockAlert) error {
+	ma.store.wlock(ctx)
+	defer ma.store.wunlock(ctx)
+	a.ID = ma.store.nextAlertID
+	ma.store.nextAlertID++
+	a.CreatedAt = time.Now().UTC()
+	ma.store.alertsByID[a.ID] = *a
+	return nil
+}
+
+func (ma *MemoryAlerts) Update(ctx context.Context, a *domain.LowStockAlert) error {
+	ma.store.wlock(ctx)
+	defer ma.store.wunlock(ctx)
+	if _, ok := ma.store.alertsByID[a.ID]; !ok {
+		return ErrNotFound
+	}
+	ma.store.alertsByID[a.ID] = *a
+	return nil
+}
+
+func (ma *MemoryAlerts) OpenByProduct(ctx context.Context, productID int64) (*domain.LowStockAlert, error) {
+	ma.store.rlock(ctx)
+	defer ma.store.runlock(ctx)
+	for _, a := range ma.store.alertsByID {
+		if a.ProductID == productID && a.Status == domain.AlertStatusOpen {
+			cp := a
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (ma *MemoryAlerts) List(ctx context.Context, f AlertFilter) ([]domain.LowStockAlert, error) {
+	ma.store.rlock(ctx)
+	defer ma.store.runlock(ctx)
+	out := make([]domain.LowStockAlert, 0)
+	for _, a := range ma.store.alertsByID {
+		if f.OpenOnly && a.Status != domain.AlertStatusOpen {
+			continue
+		}
+		out = append(out, a)
+	}
+	// новые оповещения первыми
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryAlerts_OpenByProductAndList(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	alerts := NewMemoryAlerts(store)
+
+	if _, err := alerts.OpenByProduct(ctx, 1); err != ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	a := domain.LowStockAlert{ProductID: 1, Stock: 2, ReorderPoint: 5, Status: domain.AlertStatusOpen}
+	if err := alerts.Create(ctx, &a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := alerts.OpenByProduct(ctx, 1)
+	if err != nil || got.ID != a.ID {
+		t.Fatalf("open by product: %v", err)
+	}
+
+	got.Status = domain.AlertStatusResolved
+	if err := alerts.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := alerts.OpenByProduct(ctx, 1); err != ErrNotFound {
+		t.Fatalf("expected resolved alert to be skipped, got %v", err)
+	}
+	open, _ := alerts.List(ctx, AlertFilter{OpenOnly: true})
+	all, _ := alerts.List(ctx, AlertFilter{})
+	if len(open) != 0 || len(all) != 1 {
+		t.Fatalf("unexpected list sizes open=%d all=%d", len(open), len(all))
+	}
+}
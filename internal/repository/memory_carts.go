@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryCarts реализация CartRepository поверх MemoryStore
+type MemoryCarts struct{ store *MemoryStore }
+
+func NewMemoryCarts(store *MemoryStore) *MemoryCarts {
+	return &MemoryCarts{store: store}
+}
+
+var _ CartRepository = (*MemoryCarts)(nil)
+
+// cloneCart копирует строки и промокоды корзины
+func cloneCart(c domain.Cart) domain.Cart {
+	items := make([]domain.CartItem, len(c.Items))
+	copy(items, c.Items)
+	c.Items = items
+	c.PromoCodes = append([]string(nil), c.PromoCodes...)
+	return c
+}
+
+func (mc *MemoryCarts) Create(ctx context.Context, c *domain.Cart) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	c.ID = mc.store.nextCartID
+	mc.store.nextCartID++
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+	mc.store.cartsByID[c.ID] = cloneCart(*c)
+	return nil
+}
+
+func (mc *MemoryCarts) GetByID(ctx context.Context, id int64) (*domain.Cart, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	c, ok := mc.store.cartsByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := cloneCart(c)
+	return &cp, nil
+}
+
+func (mc *MemoryCarts) Update(ctx context.Context, c *domain.Cart) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	prev, ok := mc.store.cartsByID[c.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.CreatedAt = prev.CreatedAt
+	c.UpdatedAt = time.Now().UTC()
+	mc.store.cartsByID[c.ID] = cloneCart(*c)
+	return nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryCarts_CopiesLines(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryCarts(NewMemoryStore())
+	c := domain.Cart{CustomerName: "a", Items: []domain.CartItem{{ProductID: 1, Quantity: 2}}, Status: domain.CartStatusOpen}
+	if err := repo.Create(ctx, &c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	c.Items[0].Quantity = 5
+	got, _ := repo.GetByID(ctx, c.ID)
+	if got.Items[0].Quantity != 2 {
+		t.Fatalf("stored cart shares lines with caller: %+v", got)
+	}
+	got.Items = nil
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, _ = repo.GetByID(ctx, c.ID)
+	if got.Items == nil || len(got.Items) != 0 {
+		t.Fatalf("expected empty lines, got %+v", got.Items)
+	}
+	if err := repo.Update(ctx, &domain.Cart{ID: 9}); err != ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"april/internal/domain"
+)
+
+// MemoryCategories реализация CategoryRepository поверх MemoryStore
+type MemoryCategories struct{ store *MemoryStore }
+
+func NewMemoryCategories(store *MemoryStore) *MemoryCategories {
+	return &MemoryCategories{store: store}
+}
+
+var _ CategoryRepository = (*MemoryCategories)(nil)
+
+// cloneCategory копирует схему атрибутов, чтобы хранилище не разделяло её с вызывающим
+func cloneCategory(c domain.Category) domain.Category {
+	defs := make([]domain.AttributeDef, len(c.Attributes))
+	for i, d := range c.Attributes {
+		d.Options = append([]string(nil), d.Options...)
+		defs[i] = d
+	}
+	c.Attributes = defs
+	return c
+}
+
+// slugTaken проверяет уникальность слага; вызывается под блокировкой
+func (mc *MemoryCategories) slugTaken(slug string, exceptID int64) bool {
+	for _, c := range mc.store.categoriesByID {
+		if c.Slug == slug && c.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (mc *MemoryCategories) Create(ctx context.Context, c *domain.Category) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	if mc.slugTaken(c.Slug, 0) {
+		return ErrConflict
+	}
+	c.ID = mc.store.nextCategoryID
+	mc.store.nextCategoryID++
+	mc.store.categoriesByID[c.ID] = cloneCategory(*c)
+	return nil
+}
+
+func (mc *MemoryCategories) GetByID(ctx context.Context, id int64) (*domain.Category, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	c, ok := mc.store.categoriesByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := cloneCategory(c)
+	return &cp, nil
+}
+
+func (mc *MemoryCategories) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	for _, c := range mc.store.categoriesByID {
+		if c.Slug == slug {
+			cp := cloneCategory(c)
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (mc *MemoryCategories) Update(ctx context.Context, c *domain.Category) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	if _, ok := mc.store.categoriesByID[c.ID]; !ok {
+		return ErrNotFound
+	}
+	if mc.slugTaken(c.Slug, c.ID) {
+		return ErrConflict
+	}
+	mc.store.categoriesByID[c.ID] = cloneCategory(*c)
+	return nil
+}
+
+func (mc *MemoryCategories) Delete(ctx context.Context, id int64) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	if _, ok := mc.store.categoriesByID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(mc.store.categoriesByID, id)
+	return nil
+}
+
+// List возвращает все категории, упорядоченные по родителю, позиции и id
+func (mc *MemoryCategories) List(ctx context.Context) ([]domain.Category, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	out := make([]domain.Category, 0, len(mc.store.categoriesByID))
+	for _, c := range mc.store.categoriesByID {
+		out = append(out, cloneCategory(c))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ParentID != out[j].ParentID {
+			return out[i].ParentID < out[j].ParentID
+		}
+		if out[i].Position != out[j].Position {
+			return out[i].Position < out[j].Position
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
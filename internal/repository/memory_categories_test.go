@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryCategories_SlugUniqueness(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	cats := NewMemoryCategories(store)
+
+	a := domain.Category{Name: "A", Slug: "a"}
+	b := domain.Category{Name: "B", Slug: "b"}
+	if err := cats.Create(ctx, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := cats.Create(ctx, &b); err != nil {
+		t.Fatal(err)
+	}
+	dup := domain.Category{Name: "A2", Slug: "a"}
+	if err := cats.Create(ctx, &dup); err != ErrConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	b.Slug = "a"
+	if err := cats.Update(ctx, &b); err != ErrConflict {
+		t.Fatalf("expected conflict on update, got %v", err)
+	}
+	got, err := cats.GetBySlug(ctx, "a")
+	if err != nil || got.ID != a.ID {
+		t.Fatalf("get by slug: %v", err)
+	}
+}
+
+func TestList_CategoryFilter(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	p1 := domain.Product{Name: "A", SKU: "A", CategoryIDs: []int64{1, 2}}
+	p2 := domain.Product{Name: "B", SKU: "B", CategoryIDs: []int64{3}}
+	_ = store.Create(ctx, &p1)
+	_ = store.Create(ctx, &p2)
+
+	list, _ := store.List(ctx, ProductFilter{CategoryIDs: []int64{2, 5}})
+	if len(list) != 1 || list[0].ID != p1.ID {
+		t.Fatalf("category filter: %+v", list)
+	}
+	// хранилище не разделяет слайс категорий с вызывающим
+	p1.CategoryIDs[0] = 42
+	got, _ := store.GetByID(ctx, p1.ID)
+	if got.CategoryIDs[0] != 1 {
+		t.Fatalf("stored categories aliased")
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryCredits реализация CreditRepository поверх MemoryStore
+type MemoryCredits struct{ store *MemoryStore }
+
+func NewMemoryCredits(store *MemoryStore) *MemoryCredits {
+	return &MemoryCredits{store: store}
+}
+
+var _ CreditRepository = (*MemoryCredits)(nil)
+
+func (mc *MemoryCredits) Create(ctx context.Context, a *domain.CreditAccount) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	for _, x := range mc.store.creditsByID {
+		if x.Code == a.Code {
+			return ErrConflict
+		}
+		if a.Kind == domain.CreditAccountStoreCredit && x.Kind == a.Kind && x.Customer == a.Customer {
+			return ErrConflict
+		}
+	}
+	a.ID = mc.store.nextCreditID
+	mc.store.nextCreditID++
+	a.Balance = 0
+	a.CreatedAt = time.Now().UTC()
+	a.UpdatedAt = a.CreatedAt
+	mc.store.creditsByID[a.ID] = *a
+	return nil
+}
+
+func (mc *MemoryCredits) GetByID(ctx context.Context, id int64) (*domain.CreditAccount, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	a, ok := mc.store.creditsByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &a, nil
+}
+
+func (mc *MemoryCredits) GetByCode(ctx context.Context, code string) (*domain.CreditAccount, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	for _, a := range mc.store.creditsByID {
+		if a.Code == code {
+			return &a, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (mc *MemoryCredits) List(ctx context.Context, f CreditAccountFilter) ([]domain.CreditAccount, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	out := make([]domain.CreditAccount, 0)
+	for _, a := range mc.store.creditsByID {
+		if f.Kind != "" && a.Kind != f.Kind {
+			continue
+		}
+		if f.Code != "" && a.Code != f.Code {
+			continue
+		}
+		if f.Customer != "" && a.Customer != f.Customer {
+			continue
+		}
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (mc *MemoryCredits) Post(ctx context.Context, t *domain.CreditTransaction) error {
+	mc.store.wlock(ctx)
+	defer mc.store.wunlock(ctx)
+	a, ok := mc.store.creditsByID[t.AccountID]
+	if !ok {
+		return ErrNotFound
+	}
+	t.ID = mc.store.nextCreditTxID
+	mc.store.nextCreditTxID++
+	t.CreatedAt = time.Now().UTC()
+	a.Balance = math.Round((a.Balance+t.Amount)*100) / 100
+	a.UpdatedAt = t.CreatedAt
+	t.Balance = a.Balance
+	mc.store.creditsByID[a.ID] = a
+	mc.store.creditTxs = append(mc.store.creditTxs, *t)
+	return nil
+}
+
+func (mc *MemoryCredits) Transactions(ctx context.Context, accountID int64) ([]domain.CreditTransaction, error) {
+	mc.store.rlock(ctx)
+	defer mc.store.runlock(ctx)
+	if _, ok := mc.store.creditsByID[accountID]; !ok {
+		return nil, ErrNotFound
+	}
+	out := make([]domain.CreditTransaction, 0)
+	for _, t := range mc.store.creditTxs {
+		if t.AccountID == accountID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryCredits_PostKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	repo := NewMemoryCredits(store)
+
+	a := domain.CreditAccount{Kind: domain.CreditAccountStoreCredit, Code: "SC-1", Customer: "bob"}
+	if err := repo.Create(ctx, &a); err != nil || a.ID == 0 {
+		t.Fatalf("create: %+v %v", a, err)
+	}
+	dup := domain.CreditAccount{Kind: domain.CreditAccountStoreCredit, Code: "SC-2", Customer: "bob"}
+	if err := repo.Create(ctx, &dup); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected second store credit conflict, got %v", err)
+	}
+
+	for _, amount := range []float64{10.1, -0.2} {
+		tx := domain.CreditTransaction{AccountID: a.ID, Kind: domain.CreditTransactionIssue, Amount: amount}
+		if err := repo.Post(ctx, &tx); err != nil {
+			t.Fatalf("post: %v", err)
+		}
+	}
+	got, _ := repo.GetByID(ctx, a.ID)
+	if got.Balance != 9.9 {
+		t.Fatalf("expected balance 9.9, got %v", got.Balance)
+	}
+	txs, _ := repo.Transactions(ctx, a.ID)
+	if len(txs) != 2 || txs[0].Balance != 10.1 || txs[1].Balance != 9.9 {
+		t.Fatalf("unexpected history: %+v", txs)
+	}
+	if err := repo.Post(ctx, &domain.CreditTransaction{AccountID: 99, Amount: 1}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryLoyalty реализация LoyaltyRepository поверх MemoryStore
+type MemoryLoyalty struct{ store *MemoryStore }
+
+func NewMemoryLoyalty(store *MemoryStore) *MemoryLoyalty {
+	return &MemoryLoyalty{store: store}
+}
+
+var _ LoyaltyRepository = (*MemoryLoyalty)(nil)
+
+func (ml *MemoryLoyalty) Rules(ctx context.Context) (domain.LoyaltyRules, error) {
+	ml.store.rlock(ctx)
+	defer ml.store.runlock(ctx)
+	return ml.store.loyaltyRules, nil
+}
+
+func (ml *MemoryLoyalty) SetRules(ctx context.Context, r domain.LoyaltyRules) error {
+	ml.store.wlock(ctx)
+	defer ml.store.wunlock(ctx)
+	ml.store.loyaltyRules = r
+	return nil
+}
+
+func (ml *MemoryLoyalty) Append(ctx context.Context, e *domain.LoyaltyEntry) error {
+	ml.store.wlock(ctx)
+	defer ml.store.wunlock(ctx)
+	e.ID = ml.store.nextLoyaltyID
+	ml.store.nextLoyaltyID++
+	e.Balance = ml.balance(e.Customer) + e.Points
+	e.CreatedAt = time.Now().UTC()
+	ml.store.loyaltyEntries = append(ml.store.loyaltyEntries, *e)
+	return nil
+}
+
+func (ml *MemoryLoyalty) Balance(ctx context.Context, customer string) (int64, error) {
+	ml.store.rlock(ctx)
+	defer ml.store.runlock(ctx)
+	return ml.balance(customer), nil
+}
+
+func (ml *MemoryLoyalty) Entries(ctx context.Context, f LoyaltyFilter) ([]domain.LoyaltyEntry, error) {
+	ml.store.rlock(ctx)
+	defer ml.store.runlock(ctx)
+	out := make([]domain.LoyaltyEntry, 0)
+	for _, e := range ml.store.loyaltyEntries {
+		if f.Customer != "" && e.Customer != f.Customer {
+			continue
+		}
+		if f.OrderID != 0 && e.OrderID != f.OrderID {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// balance баланс по последней записи покупателя; вызывать под локом
+func (ml *MemoryLoyalty) balance(customer string) int64 {
+	for i := len(ml.store.loyaltyEntries) - 1; i >= 0; i-- {
+		if e := ml.store.loyaltyEntries[i]; e.Customer == customer {
+			return e.Balance
+		}
+	}
+	return 0
+}
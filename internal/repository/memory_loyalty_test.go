@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryLoyalty_BalancePerCustomer(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryLoyalty(NewMemoryStore())
+	for _, e := range []domain.LoyaltyEntry{
+		{Customer: "a", Kind: domain.LoyaltyEntryEarn, Points: 10, OrderID: 1},
+		{Customer: "b", Kind: domain.LoyaltyEntryEarn, Points: 3, OrderID: 2},
+		{Customer: "a", Kind: domain.LoyaltyEntryRedeem, Points: -4, OrderID: 3},
+	} {
+		if err := repo.Append(ctx, &e); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if b, _ := repo.Balance(ctx, "a"); b != 6 {
+		t.Fatalf("expected 6, got %d", b)
+	}
+	list, _ := repo.Entries(ctx, LoyaltyFilter{Customer: "a"})
+	if len(list) != 2 || list[1].Balance != 6 {
+		t.Fatalf("unexpected entries: %+v", list)
+	}
+	list, _ = repo.Entries(ctx, LoyaltyFilter{OrderID: 2})
+	if len(list) != 1 || list[0].Customer != "b" {
+		t.Fatalf("unexpected order entries: %+v", list)
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryOrderEdits реализация OrderEditRepository поверх MemoryStore
+type MemoryOrderEdits struct{ store *MemoryStore }
+
+func NewMemoryOrderEdits(store *MemoryStore) *MemoryOrderEdits {
+	return &MemoryOrderEdits{store: store}
+}
+
+var _ OrderEditRepository = (*MemoryOrderEdits)(nil)
+
+func (me *MemoryOrderEdits) Create(ctx context.Context, e *domain.OrderEdit) error {
+	me.store.wlock(ctx)
+	defer me.store.wunlock(ctx)
+	e.ID = me.store.nextOrderEditID
+	me.store.nextOrderEditID++
+	e.CreatedAt = time.Now().UTC()
+	me.store.orderEdits = append(me.store.orderEdits, cloneOrderEdit(*e))
+	return nil
+}
+
+func (me *MemoryOrderEdits) ListByOrder(ctx context.Context, orderID int64) ([]domain.OrderEdit, error) {
+	me.store.rlock(ctx)
+	defer me.store.runlock(ctx)
+	out := make([]domain.OrderEdit, 0)
+	for _, e := range me.store.orderEdits {
+		if e.OrderID == orderID {
+			out = append(out, cloneOrderEdit(e))
+		}
+	}
+	return out, nil
+}
+
+func cloneOrderEdit(e domain.OrderEdit) domain.OrderEdit {
+	e.Changes = append([]domain.OrderLineChange(nil), e.Changes...)
+	return e
+}
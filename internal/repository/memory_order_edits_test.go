@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryOrderEdits_ListByOrder(t *testing.T) {
+	ctx := context.Background()
+	edits := NewMemoryOrderEdits(NewMemoryStore())
+	for _, e := range []domain.OrderEdit{
+		{OrderID: 1, Changes: []domain.OrderLineChange{{ProductID: 1, Before: 2, After: 1}}},
+		{OrderID: 2},
+		{OrderID: 1, Changes: []domain.OrderLineChange{{ProductID: 3, After: 1}}},
+	} {
+		if err := edits.Create(ctx, &e); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	list, _ := edits.ListByOrder(ctx, 1)
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 3 || list[0].CreatedAt.IsZero() {
+		t.Fatalf("unexpected edits %+v", list)
+	}
+	list[0].Changes[0].After = 9
+	if again, _ := edits.ListByOrder(ctx, 1); again[0].Changes[0].After != 1 {
+		t.Fatalf("listed edit must not be aliased")
+	}
+	if list, _ := edits.ListByOrder(ctx, 5); list == nil || len(list) != 0 {
+		t.Fatalf("expected empty list, got %+v", list)
+	}
+}
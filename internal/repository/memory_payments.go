@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryPayments реализация PaymentRepository поверх MemoryStore
+type MemoryPayments struct{ store *MemoryStore }
+
+func NewMemoryPayments(store *MemoryStore) *MemoryPayments {
+	return &MemoryPayments{store: store}
+}
+
+var _ PaymentRepository = (*MemoryPayments)(nil)
+
+func (mp *MemoryPayments) Create(ctx context.Context, p *domain.Payment) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	p.ID = mp.store.nextPaymentID
+	mp.store.nextPaymentID++
+	p.CreatedAt = time.Now().UTC()
+	p.UpdatedAt = p.CreatedAt
+	mp.store.paymentsByID[p.ID] = *p
+	return nil
+}
+
+func (mp *MemoryPayments) GetByID(ctx context.Context, id int64) (*domain.Payment, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	p, ok := mp.store.paymentsByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (mp *MemoryPayments) Update(ctx context.Context, p *domain.Payment) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	prev, ok := mp.store.paymentsByID[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	p.CreatedAt = prev.CreatedAt
+	p.UpdatedAt = time.Now().UTC()
+	mp.store.paymentsByID[p.ID] = *p
+	return nil
+}
+
+func (mp *MemoryPayments) List(ctx context.Context, f PaymentFilter) ([]domain.Payment, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	out := make([]domain.Payment, 0)
+	for _, p := range mp.store.paymentsByID {
+		if f.OrderID != 0 && p.OrderID != f.OrderID {
+			continue
+		}
+		if f.Status != "" && p.Status != f.Status {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
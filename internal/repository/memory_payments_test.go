@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryPayments_ListByOrderAndStatus(t *testing.T) {
+	ctx := context.Background()
+	payments := NewMemoryPayments(NewMemoryStore())
+	for _, p := range []domain.Payment{
+		{OrderID: 1, Amount: 10, Status: domain.PaymentStatusFailed},
+		{OrderID: 2, Amount: 20, Status: domain.PaymentStatusCaptured},
+		{OrderID: 1, Amount: 10, Status: domain.PaymentStatusCaptured},
+	} {
+		if err := payments.Create(ctx, &p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	list, _ := payments.List(ctx, PaymentFilter{OrderID: 1})
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 3 {
+		t.Fatalf("unexpected order payments %+v", list)
+	}
+	list, _ = payments.List(ctx, PaymentFilter{Status: domain.PaymentStatusCaptured})
+	if len(list) != 2 {
+		t.Fatalf("unexpected captured payments %+v", list)
+	}
+
+	p := list[0]
+	created := p.CreatedAt
+	p.Status, p.Refunded = domain.PaymentStatusRefunded, p.Captured
+	if err := payments.Update(ctx, &p); err != nil || !p.CreatedAt.Equal(created) {
+		t.Fatalf("update: %+v %v", p, err)
+	}
+	if err := payments.Update(ctx, &domain.Payment{ID: 99}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryPriceChanges реализация PriceChangeRepository поверх MemoryStore
+type MemoryPriceChanges struct{ store *MemoryStore }
+
+func NewMemoryPriceChanges(store *MemoryStore) *MemoryPriceChanges {
+	return &MemoryPriceChanges{store: store}
+}
+
+var _ PriceChangeRepository = (*MemoryPriceChanges)(nil)
+
+func (mp *MemoryPriceChanges) Create(ctx context.Context, c *domain.PriceChange) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	c.ID = mp.store.nextPriceID
+	mp.store.nextPriceID++
+	c.CreatedAt = time.Now().UTC()
+	mp.store.pricesByID[c.ID] = clonePriceChange(*c)
+	return nil
+}
+
+func (mp *MemoryPriceChanges) GetByID(ctx context.Context, id int64) (*domain.PriceChange, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	c, ok := mp.store.pricesByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := clonePriceChange(c)
+	return &cp, nil
+}
+
+func (mp *MemoryPriceChanges) Update(ctx context.Context, c *domain.PriceChange) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	if _, ok := mp.store.pricesByID[c.ID]; !ok {
+		return ErrNotFound
+	}
+	mp.store.pricesByID[c.ID] = clonePriceChange(*c)
+	return nil
+}
+
+func (mp *MemoryPriceChanges) List(ctx context.Context, f PriceChangeFilter) ([]domain.PriceChange, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	out := make([]domain.PriceChange, 0)
+	for _, c := range mp.store.pricesByID {
+		if f.ProductID != 0 && c.ProductID != f.ProductID {
+			continue
+		}
+		if f.Status != "" && c.Status != f.Status {
+			continue
+		}
+		if f.DueBy != nil && c.EffectiveFrom.After(*f.DueBy) {
+			continue
+		}
+		out = append(out, clonePriceChange(c))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].EffectiveFrom.Equal(out[j].EffectiveFrom) {
+			return out[i].EffectiveFrom.Before(out[j].EffectiveFrom)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func clonePriceChange(c domain.PriceChange) domain.PriceChange {
+	if c.AppliedAt != nil {
+		at := *c.AppliedAt
+		c.AppliedAt = &at
+	}
+	return c
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+)
+
+func TestMemoryPriceChanges_ListOrderAndFilters(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	prices := NewMemoryPriceChanges(store)
+	now := time.Now().UTC()
+	p := domain.Product{Name: "A", SKU: "S1"}
+	_ = store.Create(ctx, &p)
+	for _, c := range []domain.PriceChange{
+		{ProductID: p.ID, Price: 12, Status: domain.PriceChangeStatusScheduled, EffectiveFrom: now.Add(2 * time.Hour)},
+		{ProductID: p.ID, Price: 10, Status: domain.PriceChangeStatusApplied, EffectiveFrom: now},
+		{ProductID: p.ID, Price: 11, Status: domain.PriceChangeStatusScheduled, EffectiveFrom: now.Add(time.Hour)},
+		{ProductID: 99, Price: 1, Status: domain.PriceChangeStatusApplied, EffectiveFrom: now},
+	} {
+		if err := prices.Create(ctx, &c); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	list, _ := prices.List(ctx, PriceChangeFilter{ProductID: p.ID})
+	if len(list) != 3 || list[0].Price != 10 || list[1].Price != 11 || list[2].Price != 12 {
+		t.Fatalf("unexpected timeline %+v", list)
+	}
+	due := now.Add(90 * time.Minute)
+	list, _ = prices.List(ctx, PriceChangeFilter{Status: domain.PriceChangeStatusScheduled, DueBy: &due})
+	if len(list) != 1 || list[0].Price != 11 {
+		t.Fatalf("unexpected due changes %+v", list)
+	}
+
+	// история удаляется вместе с товаром
+	if err := store.Delete(ctx, p.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if list, _ = prices.List(ctx, PriceChangeFilter{ProductID: p.ID}); len(list) != 0 {
+		t.Fatalf("history left after delete: %+v", list)
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryPromotions реализация PromotionRepository поверх MemoryStore
+type MemoryPromotions struct{ store *MemoryStore }
+
+func NewMemoryPromotions(store *MemoryStore) *MemoryPromotions {
+	return &MemoryPromotions{store: store}
+}
+
+var _ PromotionRepository = (*MemoryPromotions)(nil)
+
+func (mp *MemoryPromotions) Create(ctx context.Context, p *domain.Promotion) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	if mp.codeTaken(p.Code, 0) {
+		return ErrConflict
+	}
+	p.ID = mp.store.nextPromoID
+	mp.store.nextPromoID++
+	p.CreatedAt = time.Now().UTC()
+	mp.store.promotionsByID[p.ID] = clonePromotion(*p)
+	return nil
+}
+
+func (mp *MemoryPromotions) GetByID(ctx context.Context, id int64) (*domain.Promotion, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	p, ok := mp.store.promotionsByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := clonePromotion(p)
+	return &cp, nil
+}
+
+func (mp *MemoryPromotions) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	for _, p := range mp.store.promotionsByID {
+		if code != "" && p.Code == code {
+			cp := clonePromotion(p)
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (mp *MemoryPromotions) Update(ctx context.Context, p *domain.Promotion) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	prev, ok := mp.store.promotionsByID[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if mp.codeTaken(p.Code, p.ID) {
+		return ErrConflict
+	}
+	p.CreatedAt = prev.CreatedAt
+	mp.store.promotionsByID[p.ID] = clonePromotion(*p)
+	return nil
+}
+
+func (mp *MemoryPromotions) Delete(ctx context.Context, id int64) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	if _, ok := mp.store.promotionsByID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(mp.store.promotionsByID, id)
+	return nil
+}
+
+func (mp *MemoryPromotions) List(ctx context.Context) ([]domain.Promotion, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	out := make([]domain.Promotion, 0, len(mp.store.promotionsByID))
+	for _, p := range mp.store.promotionsByID {
+		out = append(out, clonePromotion(p))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (mp *MemoryPromotions) codeTaken(code string, exceptID int64) bool {
+	if code == "" {
+		return false
+	}
+	for _, p := range mp.store.promotionsByID {
+		if p.ID != exceptID && p.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func clonePromotion(p domain.Promotion) domain.Promotion {
+	p.ProductIDs = append([]int64(nil), p.ProductIDs...)
+	p.CategoryIDs = append([]int64(nil), p.CategoryIDs...)
+	return p
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryPromotions_CodesAndIsolation(t *testing.T) {
+	ctx := context.Background()
+	promos := NewMemoryPromotions(NewMemoryStore())
+	a := domain.Promotion{Name: "a", Code: "SPRING", ProductIDs: []int64{1}}
+	if err := promos.Create(ctx, &a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	b := domain.Promotion{Name: "b", Code: "SPRING"}
+	if err := promos.Create(ctx, &b); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected conflict on taken code, got %v", err)
+	}
+	// акции без кода не конфликтуют между собой
+	for _, name := range []string{"c", "d"} {
+		p := domain.Promotion{Name: name}
+		if err := promos.Create(ctx, &p); err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+	}
+	// код можно оставить при обновлении самой акции
+	a.Name = "a2"
+	if err := promos.Update(ctx, &a); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	got, err := promos.GetByCode(ctx, "SPRING")
+	if err != nil || got.ID != a.ID || got.Name != "a2" {
+		t.Fatalf("get by code: %+v %v", got, err)
+	}
+	got.ProductIDs[0] = 99
+	again, _ := promos.GetByID(ctx, a.ID)
+	if again.ProductIDs[0] != 1 {
+		t.Fatalf("stored promotion must not be aliased")
+	}
+	if err := promos.Delete(ctx, a.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := promos.GetByCode(ctx, "SPRING"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+}
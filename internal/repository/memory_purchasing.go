@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemorySuppliers реализация SupplierRepository поверх MemoryStore
+type MemorySuppliers struct{ store *MemoryStore }
+
+func NewMemorySuppliers(store *MemoryStore) *MemorySuppliers { return &MemorySuppliers{store: store} }
+
+var _ SupplierRepository = (*MemorySuppliers)(nil)
+
+func (ms *MemorySuppliers) Create(ctx context.Context, s *domain.Supplier) error {
+	ms.store.wlock(ctx)
+	defer ms.store.wunlock(ctx)
+	s.ID = ms.store.nextSupplierID
+	ms.store.nextSupplierID++
+	s.CreatedAt = time.Now().UTC()
+	ms.store.suppliersByID[s.ID] = *s
+	return nil
+}
+
+func (ms *MemorySuppliers) GetByID(ctx context.Context, id int64) (*domain.Supplier, error) {
+	ms.store.rlock(ctx)
+	defer ms.store.runlock(ctx)
+	s, ok := ms.store.suppliersByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (ms *MemorySuppliers) Update(ctx context.Context, s *domain.Supplier) error {
+	ms.store.wlock(ctx)
+	defer ms.store.wunlock(ctx)
+	if _, ok := ms.store.suppliersByID[s.ID]; !ok {
+		return ErrNotFound
+	}
+	ms.store.suppliersByID[s.ID] = *s
+	return nil
+}
+
+func (ms *MemorySuppliers) List(ctx context.Context) ([]domain.Supplier, error) {
+	ms.store.rlock(ctx)
+	defer ms.store.runlock(ctx)
+	out := make([]domain.Supplier, 0, len(ms.store.suppliersByID))
+	for _, s := range ms.store.suppliersByID {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// MemoryPurchaseOrders реализация PurchaseOrderRepository поверх MemoryStore
+type MemoryPurchaseOrders struct{ store *MemoryStore }
+
+func NewMemoryPurchaseOrders(store *MemoryStore) *MemoryPurchaseOrders {
+	return &MemoryPurchaseOrders{store: store}
+}
+
+var _ PurchaseOrderRepository = (*MemoryPurchaseOrders)(nil)
+
+// clonePurchaseOrder копирует строки, чтобы правки вызывающего не меняли хранилище до Update
+func clonePurchaseOrder(po domain.PurchaseOrder) domain.PurchaseOrder {
+	po.Lines = append([]domain.PurchaseOrderLine(nil), po.Lines...)
+	return po
+}
+
+func (mp *MemoryPurchaseOrders) Create(ctx context.Context, po *domain.PurchaseOrder) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	po.ID = mp.store.nextPurchaseID
+	mp.store.nextPurchaseID++
+	po.CreatedAt = time.Now().UTC()
+	po.UpdatedAt = po.CreatedAt
+	mp.store.purchasesByID[po.ID] = clonePurchaseOrder(*po)
+	return nil
+}
+
+func (mp *MemoryPurchaseOrders) GetByID(ctx context.Context, id int64) (*domain.PurchaseOrder, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	po, ok := mp.store.purchasesByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := clonePurchaseOrder(po)
+	return &cp, nil
+}
+
+func (mp *MemoryPurchaseOrders) Update(ctx context.Context, po *domain.PurchaseOrder) error {
+	mp.store.wlock(ctx)
+	defer mp.store.wunlock(ctx)
+	if _, ok := mp.store.purchasesByID[po.ID]; !ok {
+		return ErrNotFound
+	}
+	po.UpdatedAt = time.Now().UTC()
+	mp.store.purchasesByID[po.ID] = clonePurchaseOrder(*po)
+	return nil
+}
+
+func (mp *MemoryPurchaseOrders) List(ctx context.Context, f PurchaseOrderFilter) ([]domain.PurchaseOrder, error) {
+	mp.store.rlock(ctx)
+	defer mp.store.runlock(ctx)
+	out := make([]domain.PurchaseOrder, 0)
+	for _, po := range mp.store.purchasesByID {
+		if f.SupplierID != 0 && po.SupplierID != f.SupplierID {
+			continue
+		}
+		if f.Status != "" && po.Status != f.Status {
+			continue
+		}
+		out = append(out, clonePurchaseOrder(po))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
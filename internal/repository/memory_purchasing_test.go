@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryPurchaseOrders_CopiesLines(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	pos := NewMemoryPurchaseOrders(store)
+
+	po := domain.PurchaseOrder{SupplierID: 1, Status: domain.PurchaseOrderStatusDraft,
+		Lines: []domain.PurchaseOrderLine{{ProductID: 1, Quantity: 10}}}
+	if err := pos.Create(ctx, &po); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, _ := pos.GetByID(ctx, po.ID)
+	got.Lines[0].Received = 5
+	again, _ := pos.GetByID(ctx, po.ID)
+	if again.Lines[0].Received != 0 {
+		t.Fatalf("stored line mutated without Update")
+	}
+	if err := pos.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	again, _ = pos.GetByID(ctx, po.ID)
+	if again.Lines[0].Received != 5 {
+		t.Fatalf("update not persisted")
+	}
+}
+
+func TestMemoryPurchaseOrders_ListFilter(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	suppliers := NewMemorySuppliers(store)
+	pos := NewMemoryPurchaseOrders(store)
+	s1 := domain.Supplier{Name: "Farm"}
+	_ = suppliers.Create(ctx, &s1)
+	for _, st := range []domain.PurchaseOrderStatus{domain.PurchaseOrderStatusDraft, domain.PurchaseOrderStatusSent} {
+		po := domain.PurchaseOrder{SupplierID: s1.ID, Status: st}
+		_ = pos.Create(ctx, &po)
+	}
+	other := domain.PurchaseOrder{SupplierID: 99, Status: domain.PurchaseOrderStatusSent}
+	_ = pos.Create(ctx, &other)
+
+	list, _ := pos.List(ctx, PurchaseOrderFilter{SupplierID: s1.ID})
+	if len(list) != 2 {
+		t.Fatalf("supplier filter: %d", len(list))
+	}
+	list, _ = pos.List(ctx, PurchaseOrderFilter{Status: domain.PurchaseOrderStatusSent})
+	if len(list) != 2 {
+		t.Fatalf("status filter: %d", len(list))
+	}
+	all, _ := suppliers.List(ctx)
+	if len(all) != 1 || all[0].CreatedAt.IsZero() {
+		t.Fatalf("suppliers list: %+v", all)
+	}
+}
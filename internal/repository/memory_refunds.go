@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryRefunds реализация RefundRepository поверх MemoryStore
+type MemoryRefunds struct{ store *MemoryStore }
+
+func NewMemoryRefunds(store *MemoryStore) *MemoryRefunds {
+	return &MemoryRefunds{store: store}
+}
+
+var _ RefundRepository = (*MemoryRefunds)(nil)
+
+func (mr *MemoryRefunds) Create(ctx context.Context, r *domain.Refund) error {
+	mr.store.wlock(ctx)
+	defer mr.store.wunlock(ctx)
+	r.ID = mr.store.nextRefundID
+	mr.store.nextRefundID++
+	r.CreatedAt = time.Now().UTC()
+	r.UpdatedAt = r.CreatedAt
+	mr.store.refundsByID[r.ID] = cloneRefund(*r)
+	return nil
+}
+
+func (mr *MemoryRefunds) GetByID(ctx context.Context, id int64) (*domain.Refund, error) {
+	mr.store.rlock(ctx)
+	defer mr.store.runlock(ctx)
+	r, ok := mr.store.refundsByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := cloneRefund(r)
+	return &cp, nil
+}
+
+func (mr *MemoryRefunds) Update(ctx context.Context, r *domain.Refund) error {
+	mr.store.wlock(ctx)
+	defer mr.store.wunlock(ctx)
+	prev, ok := mr.store.refundsByID[r.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	r.CreatedAt = prev.CreatedAt
+	r.UpdatedAt = time.Now().UTC()
+	mr.store.refundsByID[r.ID] = cloneRefund(*r)
+	return nil
+}
+
+func (mr *MemoryRefunds) ListByOrder(ctx context.Context, orderID int64) ([]domain.Refund, error) {
+	mr.store.rlock(ctx)
+	defer mr.store.runlock(ctx)
+	out := make([]domain.Refund, 0)
+	for _, r := range mr.store.refundsByID {
+		if r.OrderID == orderID {
+			out = append(out, cloneRefund(r))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// List возвращает возвраты по фильтру по возрастанию ID
+func (mr *MemoryRefunds) List(ctx context.Context, f RefundFilter) ([]domain.Refund, error) {
+	mr.store.rlock(ctx)
+	defer mr.store.runlock(ctx)
+	out := make([]domain.Refund, 0)
+	for _, r := range mr.store.refundsByID {
+		if f.Kind != "" && r.Kind != f.Kind {
+			continue
+		}
+		if !inRange(r.CreatedAt, f.CreatedFrom, f.CreatedTo) {
+			continue
+		}
+		out = append(out, cloneRefund(r))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func cloneRefund(r domain.Refund) domain.Refund {
+	r.Lines = append([]domain.RefundLine(nil), r.Lines...)
+	return r
+}
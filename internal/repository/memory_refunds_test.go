@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+)
+
+func TestMemoryRefunds_ListByOrder(t *testing.T) {
+	ctx := context.Background()
+	refunds := NewMemoryRefunds(NewMemoryStore())
+	for _, r := range []domain.Refund{
+		{OrderID: 1, Kind: domain.RefundKindReturn, Lines: []domain.RefundLine{{ProductID: 1, Quantity: 1}}},
+		{OrderID: 2, Kind: domain.RefundKindCancel},
+		{OrderID: 1, Kind: domain.RefundKindCancel},
+	} {
+		if err := refunds.Create(ctx, &r); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	list, _ := refunds.ListByOrder(ctx, 1)
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 3 {
+		t.Fatalf("unexpected refunds %+v", list)
+	}
+	list[0].Lines[0].Quantity = 9
+	if r, _ := refunds.GetByID(ctx, 1); r.Lines[0].Quantity != 1 {
+		t.Fatalf("listed refund must not be aliased")
+	}
+	r := list[1]
+	r.Status = domain.RefundStatusCompleted
+	if err := refunds.Update(ctx, &r); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, _ := refunds.GetByID(ctx, 3); got.Status != domain.RefundStatusCompleted {
+		t.Fatalf("status not updated: %+v", got)
+	}
+	if err := refunds.Update(ctx, &domain.Refund{ID: 99}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestMemoryRefunds_List(t *testing.T) {
+	ctx := context.Background()
+	refunds := NewMemoryRefunds(NewMemoryStore())
+	for _, r := range []domain.Refund{
+		{OrderID: 1, Kind: domain.RefundKindReturn},
+		{OrderID: 2, Kind: domain.RefundKindPartialCancel, Reason: "out_of_stock"},
+		{OrderID: 1, Kind: domain.RefundKindPartialCancel, Reason: "damaged"},
+	} {
+		if err := refunds.Create(ctx, &r); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	list, _ := refunds.List(ctx, RefundFilter{Kind: domain.RefundKindPartialCancel})
+	if len(list) != 2 || list[0].ID != 2 || list[1].Reason != "damaged" {
+		t.Fatalf("unexpected refunds %+v", list)
+	}
+	from := time.Now().Add(time.Minute)
+	if list, _ := refunds.List(ctx, RefundFilter{CreatedFrom: &from}); len(list) != 0 {
+		t.Fatalf("expected no refunds after %v, got %+v", from, list)
+	}
+	if list, _ := refunds.List(ctx, RefundFilter{}); len(list) != 3 {
+		t.Fatalf("expected all refunds, got %+v", list)
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryBatches реализация BatchRepository поверх MemoryStore
+type MemoryBatches struct{ store *MemoryStore }
+
+func NewMemoryBatches(store *MemoryStore) *MemoryBatches { return &MemoryBatches{store: store} }
+
+var _ BatchRepository = (*MemoryBatches)(nil)
+
+func (mb *MemoryBatches) Create(ctx context.Context, b *domain.Batch) error {
+	mb.store.wlock(ctx)
+	defer mb.store.wunlock(ctx)
+	for _, existing := range mb.store.batchesByID {
+		if existing.ProductID == b.ProductID && existing.Number == b.Number {
+			return ErrConflict
+		}
+	}
+	b.ID = mb.store.nextBatchID
+	mb.store.nextBatchID++
+	b.CreatedAt = time.Now().UTC()
+	mb.store.batchesByID[b.ID] = *b
+	return nil
+}
+
+func (mb *MemoryBatches) GetByID(ctx context.Context, id int64) (*domain.Batch, error) {
+	mb.store.rlock(ctx)
+	defer mb.store.runlock(ctx)
+	b, ok := mb.store.batchesByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &b, nil
+}
+
+func (mb *MemoryBatches) FindByNumber(ctx context.Context, productID int64, number string) (*domain.Batch, error) {
+	mb.store.rlock(ctx)
+	defer mb.store.runlock(ctx)
+	for _, b := range mb.store.batchesByID {
+		if b.ProductID == productID && b.Number == number {
+			return &b, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (mb *MemoryBatches) List(ctx context.Context, productID int64) ([]domain.Batch, error) {
+	mb.store.rlock(ctx)
+	defer mb.store.runlock(ctx)
+	out := make([]domain.Batch, 0)
+	for _, b := range mb.store.batchesByID {
+		if productID != 0 && b.ProductID != productID {
+			continue
+		}
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// MemorySerials реализация SerialRepository поверх MemoryStore
+type MemorySerials struct{ store *MemoryStore }
+
+func NewMemorySerials(store *MemoryStore) *MemorySerials { return &MemorySerials{store: store} }
+
+var _ SerialRepository = (*MemorySerials)(nil)
+
+func (ms *MemorySerials) Create(ctx context.Context, u *domain.SerialUnit) error {
+	ms.store.wlock(ctx)
+	defer ms.store.wunlock(ctx)
+	if _, ok := ms.store.serialsByCode[u.Code]; ok {
+		return ErrConflict
+	}
+	u.CreatedAt = time.Now().UTC()
+	u.UpdatedAt = u.CreatedAt
+	ms.store.serialsByCode[u.Code] = *u
+	return nil
+}
+
+func (ms *MemorySerials) Get(ctx context.Context, code string) (*domain.SerialUnit, error) {
+	ms.store.rlock(ctx)
+	defer ms.store.runlock(ctx)
+	u, ok := ms.store.serialsByCode[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (ms *MemorySerials) Update(ctx context.Context, u *domain.SerialUnit) error {
+	ms.store.wlock(ctx)
+	defer ms.store.wunlock(ctx)
+	if _, ok := ms.store.serialsByCode[u.Code]; !ok {
+		return ErrNotFound
+	}
+	u.UpdatedAt = time.Now().UTC()
+	ms.store.serialsByCode[u.Code] = *u
+	return nil
+}
+
+func (ms *MemorySerials) List(ctx context.Context, f SerialFilter) ([]domain.SerialUnit, error) {
+	ms.store.rlock(ctx)
+	defer ms.store.runlock(ctx)
+	out := make([]domain.SerialUnit, 0)
+	for _, u := range ms.store.serialsByCode {
+		if f.ProductID != 0 && u.ProductID != f.ProductID {
+			continue
+		}
+		if f.BatchID != 0 && u.BatchID != f.BatchID {
+			continue
+		}
+		if f.OrderID != 0 && u.OrderID != f.OrderID {
+			continue
+		}
+		if f.Status != "" && u.Status != f.Status {
+			continue
+		}
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemorySerials_UniqueCodesAndFilter(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	batches := NewMemoryBatches(store)
+	serials := NewMemorySerials(store)
+
+	b := domain.Batch{ProductID: 1, Number: "B1"}
+	if err := batches.Create(ctx, &b); err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	if err := batches.Create(ctx, &domain.Batch{ProductID: 1, Number: "B1"}); err != ErrConflict {
+		t.Fatalf("expected batch conflict, got %v", err)
+	}
+	if got, err := batches.FindByNumber(ctx, 1, "B1"); err != nil || got.ID != b.ID {
+		t.Fatalf("find batch: %v", err)
+	}
+	if _, err := batches.FindByNumber(ctx, 2, "B1"); err != ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	for _, code := range []string{"b", "a"} {
+		u := domain.SerialUnit{Code: code, ProductID: 1, BatchID: b.ID, Status: domain.SerialStatusInStock}
+		if err := serials.Create(ctx, &u); err != nil {
+			t.Fatalf("create unit: %v", err)
+		}
+	}
+	if err := serials.Create(ctx, &domain.SerialUnit{Code: "a", ProductID: 2}); err != ErrConflict {
+		t.Fatalf("expected code conflict, got %v", err)
+	}
+	u, _ := serials.Get(ctx, "a")
+	u.Status = domain.SerialStatusSold
+	u.OrderID = 7
+	if err := serials.Update(ctx, u); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	list, _ := serials.List(ctx, SerialFilter{ProductID: 1})
+	if len(list) != 2 || list[0].Code != "a" {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	list, _ = serials.List(ctx, SerialFilter{OrderID: 7, Status: domain.SerialStatusSold})
+	if len(list) != 1 || list[0].Code != "a" {
+		t.Fatalf("unexpected sold list %+v", list)
+	}
+}
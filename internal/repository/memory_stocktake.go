@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+)
+
+// MemoryStocktakes реализация StocktakeRepository поверх MemoryStore
+type MemoryStocktakes struct{ store *MemoryStore }
+
+func NewMemoryStocktakes(store *MemoryStore) *MemoryStocktakes {
+	return &MemoryStocktakes{store: store}
+}
+
+var _ StocktakeRepository = (*MemoryStocktakes)(nil)
+
+// cloneStocktake копирует строки и указатели на посчитанное количество
+func cloneStocktake(st domain.Stocktake) domain.Stocktake {
+	lines := make([]domain.StocktakeLine, len(st.Lines))
+	for i, l := range st.Lines {
+		if l.Counted != nil {
+			v := *l.Counted
+			l.Counted = &v
+		}
+		lines[i] = l
+	}
+	st.Lines = lines
+	return st
+}
+
+func (ms *MemoryStocktakes) Create(ctx context.Context, st *domain.Stocktake) error {
+	ms.store.wlock(ctx)
+	defer ms.store.wunlock(ctx)
+	st.ID = ms.store.nextStocktakeID
+	ms.store.nextStocktakeID++
+	st.CreatedAt = time.Now().UTC()
+	st.UpdatedAt = st.CreatedAt
+	ms.store.stocktakesByID[st.ID] = cloneStocktake(*st)
+	return nil
+}
+
+func (ms *MemoryStocktakes) GetByID(ctx context.Context, id int64) (*domain.Stocktake, error) {
+	ms.store.rlock(ctx)
+	defer ms.store.runlock(ctx)
+	st, ok := ms.store.stocktakesByID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := cloneStocktake(st)
+	return &cp, nil
+}
+
+func (ms *MemoryStocktakes) Update(ctx context.Context, st *domain.Stocktake) error {
+	ms.store.wlock(ctx)
+	defer ms.store.wunlock(ctx)
+	if _, ok := ms.store.stocktakesByID[st.ID]; !ok {
+		return ErrNotFound
+	}
+	st.UpdatedAt = time.Now().UTC()
+	ms.store.stocktakesByID[st.ID] = cloneStocktake(*st)
+	return nil
+}
+
+func (ms *MemoryStocktakes) List(ctx context.Context) ([]domain.Stocktake, error) {
+	ms.store.rlock(ctx)
+	defer ms.store.runlock(ctx)
+	out := make([]domain.Stocktake, 0, len(ms.store.stocktakesByID))
+	for _, st := range ms.store.stocktakesByID {
+		out = append(out, cloneStocktake(st))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestMemoryStocktakes_CopiesCounts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	sts := NewMemoryStocktakes(store)
+
+	n := int64(3)
+	st := domain.Stocktake{Status: domain.StocktakeStatusOpen, Lines: []domain.StocktakeLine{{ProductID: 1, Expected: 5, Counted: &n}}}
+	if err := sts.Create(ctx, &st); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	n = 100
+	got, _ := sts.GetByID(ctx, st.ID)
+	if *got.Lines[0].Counted != 3 {
+		t.Fatalf("stored count aliased caller pointer")
+	}
+	*got.Lines[0].Counted = 7
+	again, _ := sts.GetByID(ctx, st.ID)
+	if *again.Lines[0].Counted != 3 {
+		t.Fatalf("stored count mutated without Update")
+	}
+	list, _ := sts.List(ctx)
+	if len(list) != 1 {
+		t.Fatalf("list: %d", len(list))
+	}
+}
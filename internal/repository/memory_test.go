@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"april/internal/domain"
 )
@@ -76,6 +77,27 @@ func TestMemoryTx_TransactionalUpdate(t *testing.T) {
 	}
 }
 
+func TestMemoryTx_Nested(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	tx := NewMemoryTx(store)
+	orders := NewMemoryOrders(store)
+
+	// вложенная транзакция не должна брать блокировку повторно
+	err := tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return tx.WithTransaction(ctx, func(ctx context.Context) error {
+			o := domain.Order{CustomerName: "John", Status: domain.OrderStatusConfirmed}
+			return orders.Create(ctx, &o)
+		})
+	})
+	if err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+	if _, err := orders.GetByID(ctx, 1); err != nil {
+		t.Fatalf("expected order after nested tx: %v", err)
+	}
+}
+
 func TestList_Filtering(t *testing.T) {
 	ctx := context.Background()
 	store := NewMemoryStore()
@@ -113,3 +135,194 @@ func TestList_Filtering(t *testing.T) {
 		}
 	}
 }
+
+func TestList_StockSKUAndDates(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, p := range []domain.Product{
+		{Name: "Aspirin", SKU: "ASP-1", Stock: 5},
+		{Name: "Aspirin C", SKU: "asp-2"},
+		{Name: "Ibuprofen", SKU: "IBU-1", Stock: 1},
+	} {
+		if err := store.Create(ctx, &p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func(f ProductFilter) int {
+		list, err := store.List(ctx, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(list)
+	}
+	yes, no := true, false
+	minStock := int64(2)
+	if count(ProductFilter{InStock: &yes}) != 2 || count(ProductFilter{InStock: &no}) != 1 {
+		t.Fatalf("in_stock filter")
+	}
+	if count(ProductFilter{MinStock: &minStock}) != 1 {
+		t.Fatalf("min_stock filter")
+	}
+	if count(ProductFilter{SKUPrefix: "Asp-"}) != 2 {
+		t.Fatalf("sku prefix filter")
+	}
+	if count(ProductFilter{IDs: []int64{1, 3, 42}}) != 2 {
+		t.Fatalf("ids filter")
+	}
+	if count(ProductFilter{Archived: &yes}) != 0 || count(ProductFilter{Archived: &no}) != 3 {
+		t.Fatalf("archived filter")
+	}
+
+	p, _ := store.GetByID(ctx, 1)
+	if p.CreatedAt.IsZero() || !p.UpdatedAt.Equal(p.CreatedAt) {
+		t.Fatalf("timestamps not set: %+v", p)
+	}
+	cut := time.Now().UTC()
+	time.Sleep(time.Millisecond)
+	p.Stock = 4
+	if err := store.Update(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.UpdatedAt.After(p.CreatedAt) {
+		t.Fatalf("updated_at not bumped: %+v", p)
+	}
+	if count(ProductFilter{UpdatedFrom: &cut}) != 1 || count(ProductFilter{UpdatedTo: &cut}) != 2 || count(ProductFilter{CreatedFrom: &cut}) != 0 {
+		t.Fatalf("date range filter")
+	}
+}
+
+func TestMemoryStore_DeleteReferencedProduct(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	p := domain.Product{Name: "A", SKU: "S1", Stock: 1}
+	_ = store.Create(ctx, &p)
+	o := domain.Order{Items: []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}}
+	_ = NewMemoryOrders(store).Create(ctx, &o)
+	if err := store.Delete(ctx, p.ID); err != ErrConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if _, err := store.GetByID(ctx, p.ID); err != nil {
+		t.Fatalf("referenced product removed: %v", err)
+	}
+}
+
+func TestMemoryStore_DeleteProductInCartOrPromotion(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	inCart := domain.Product{Name: "A", SKU: "S1"}
+	inPromo := domain.Product{Name: "B", SKU: "S2"}
+	_ = store.Create(ctx, &inCart)
+	_ = store.Create(ctx, &inPromo)
+	c := domain.Cart{Items: []domain.CartItem{{ProductID: inCart.ID, Quantity: 1}}, Status: domain.CartStatusOpen}
+	_ = NewMemoryCarts(store).Create(ctx, &c)
+	pr := domain.Promotion{Name: "P", ProductIDs: []int64{inPromo.ID}}
+	_ = NewMemoryPromotions(store).Create(ctx, &pr)
+	for _, id := range []int64{inCart.ID, inPromo.ID} {
+		if err := store.Delete(ctx, id); err != ErrConflict {
+			t.Fatalf("product %d: expected conflict, got %v", id, err)
+		}
+	}
+}
+
+func TestMemoryStore_BarcodeUniqueness(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	a := domain.Product{Name: "A", SKU: "S1", Barcodes: []string{"4006381333931"}}
+	if err := store.Create(ctx, &a); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	b := domain.Product{Name: "B", SKU: "S2", Barcodes: []string{"96385074", "4006381333931"}}
+	if err := store.Create(ctx, &b); err != ErrConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	b.Barcodes = []string{"96385074"}
+	if err := store.Create(ctx, &b); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// a product may keep its own codes on update
+	if err := store.Update(ctx, &a); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	b.Barcodes = append(b.Barcodes, "4006381333931")
+	if err := store.Update(ctx, &b); err != ErrConflict {
+		t.Fatalf("expected conflict on update, got %v", err)
+	}
+
+	got, err := store.GetByBarcode(ctx, "96385074")
+	if err != nil || got.ID != b.ID {
+		t.Fatalf("get by barcode: %v %+v", err, got)
+	}
+	if _, err := store.GetByBarcode(ctx, "036000291452"); err != ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestFacets_ComputedOverFilter(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for _, p := range []domain.Product{
+		{Name: "A", Price: 50, Stock: 1, CategoryIDs: []int64{2}, Attributes: map[string]string{"manufacturer": "Bayer"}},
+		{Name: "B", Price: 150, Stock: 0, CategoryIDs: []int64{2, 3}, Attributes: map[string]string{"manufacturer": "Bayer"}},
+		{Name: "C", Price: 700, Stock: 5, CategoryIDs: []int64{3}, Attributes: map[string]string{"manufacturer": "Teva"}},
+		{Name: "D", Price: 2000, Stock: 5},
+	} {
+		p := p
+		_ = store.Create(ctx, &p)
+	}
+	// 2 и 3 — подкатегории 1
+	opts := FacetOptions{PriceEdges: []float64{500, 100}, CategoryAncestors: map[int64][]int64{2: {1}, 3: {1}}}
+
+	f, err := store.Facets(ctx, ProductFilter{}, opts)
+	if err != nil {
+		t.Fatalf("facets: %v", err)
+	}
+	if f.Total != 4 || f.InStock != 3 || f.OutOfStock != 1 {
+		t.Fatalf("unexpected totals %+v", f)
+	}
+	if len(f.Price) != 3 || f.Price[0].Count != 1 || f.Price[1].Count != 1 || f.Price[2].Count != 2 || f.Price[2].To != nil || *f.Price[0].To != 100 {
+		t.Fatalf("unexpected price buckets %+v", f.Price)
+	}
+	want := map[int64]int{1: 3, 2: 2, 3: 2}
+	if len(f.Categories) != len(want) {
+		t.Fatalf("unexpected categories %+v", f.Categories)
+	}
+	for _, c := range f.Categories {
+		if want[c.CategoryID] != c.Count {
+			t.Fatalf("category %d: got %d, want %d", c.CategoryID, c.Count, want[c.CategoryID])
+		}
+	}
+	if len(f.Manufacturers) != 2 || f.Manufacturers[0].Value != "Bayer" || f.Manufacturers[0].Count != 2 {
+		t.Fatalf("unexpected manufacturers %+v", f.Manufacturers)
+	}
+
+	minPrice := 100.0
+	f, _ = store.Facets(ctx, ProductFilter{MinPrice: &minPrice, CategoryIDs: []int64{3}}, opts)
+	if f.Total != 2 || f.InStock != 1 || len(f.Manufacturers) != 2 {
+		t.Fatalf("facets ignore filter: %+v", f)
+	}
+}
+
+func TestMemoryOrders_List(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	orders := NewMemoryOrders(store)
+	for _, st := range []domain.OrderStatus{domain.OrderStatusConfirmed, domain.OrderStatusCancelled, domain.OrderStatusConfirmed} {
+		o := domain.Order{CustomerName: "c", Status: st, Items: []domain.OrderItem{{ProductID: 1, Quantity: 1}}}
+		if err := orders.Create(ctx, &o); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	list, _ := orders.List(ctx, OrderFilter{Status: domain.OrderStatusConfirmed})
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 3 {
+		t.Fatalf("unexpected orders %+v", list)
+	}
+	list[0].Items[0].Quantity = 9
+	if o, _ := orders.GetByID(ctx, 1); o.Items[0].Quantity != 1 {
+		t.Fatalf("listed order must not be aliased")
+	}
+	future := time.Now().Add(time.Hour)
+	if list, _ = orders.List(ctx, OrderFilter{CreatedFrom: &future}); len(list) != 0 {
+		t.Fatalf("expected no orders after %v, got %d", future, len(list))
+	}
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
+	"time"
 
 	"april/internal/domain"
 )
@@ -11,20 +13,55 @@ import (
 // ErrNotFound возвращается, когда сущность не найдена
 var ErrNotFound = errors.New("not found")
 
+// ErrConflict возвращается при нарушении уникальности (слаг, штрихкод и т.п.)
+var ErrConflict = errors.New("already exists")
+
 // ProductFilter параметры фильтрации списка товаров
 type ProductFilter struct {
 	NameSubstring string
 	MinPrice      *float64
 	MaxPrice      *float64
+	// CategoryID категория вместе с подкатегориями; сервис раскрывает её в CategoryIDs
+	CategoryID int64
+	// CategoryIDs товар подходит, если отнесён хотя бы к одной из категорий
+	CategoryIDs []int64
+	// Attributes значения атрибутов по коду без учёта регистра; несколько значений — любое из них
+	Attributes map[string][]string
+	// IDs ограничивает выборку перечисленными товарами (например, найденными поиском)
+	IDs []int64
+	// InStock true — только товары в наличии, false — только отсутствующие
+	InStock *bool
+	// MinStock минимальный остаток
+	MinStock *int64
+	// SKUPrefix начало артикула без учёта регистра
+	SKUPrefix string
+	// CreatedFrom/CreatedTo и UpdatedFrom/UpdatedTo полуинтервалы [from, to) по времени
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	// Archived true — только архивные товары, false — только активные, nil — все
+	Archived *bool
 }
 
 // ProductRepository интерфейс репозитория товаров
 type ProductRepository interface {
 	Create(ctx context.Context, p *domain.Product) error
 	GetByID(ctx context.Context, id int64) (*domain.Product, error)
+	GetByBarcode(ctx context.Context, code string) (*domain.Product, error)
 	Update(ctx context.Context, p *domain.Product) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, f ProductFilter) ([]domain.Product, error)
+	// Facets считает агрегаты по тем же товарам, что вернул бы List(f)
+	Facets(ctx context.Context, f ProductFilter, opts FacetOptions) (*domain.ProductFacets, error)
+}
+
+// FacetOptions параметры агрегатов по товарам
+type FacetOptions struct {
+	// PriceEdges возрастающие границы ценовых диапазонов
+	PriceEdges []float64
+	// CategoryAncestors предки категории; товар засчитывается и в них, но один раз
+	CategoryAncestors map[int64][]int64
 }
 
 // OrderRepository интерфейс репозитория заказов
@@ -32,6 +69,207 @@ type OrderRepository interface {
 	Create(ctx context.Context, o *domain.Order) error
 	GetByID(ctx context.Context, id int64) (*domain.Order, error)
 	Update(ctx context.Context, o *domain.Order) error
+	// List возвращает заказы по возрастанию ID
+	List(ctx context.Context, f OrderFilter) ([]domain.Order, error)
+}
+
+// PaymentFilter параметры выборки оплат
+type PaymentFilter struct {
+	OrderID int64
+	Status  domain.PaymentStatus
+}
+
+// PaymentRepository интерфейс репозитория оплат заказов
+type PaymentRepository interface {
+	Create(ctx context.Context, p *domain.Payment) error
+	GetByID(ctx context.Context, id int64) (*domain.Payment, error)
+	Update(ctx context.Context, p *domain.Payment) error
+	// List возвращает оплаты по возрастанию ID
+	List(ctx context.Context, f PaymentFilter) ([]domain.Payment, error)
+}
+
+// RefundRepository интерфейс репозитория возвратов денег
+type RefundRepository interface {
+	Create(ctx context.Context, r *domain.Refund) error
+	GetByID(ctx context.Context, id int64) (*domain.Refund, error)
+	Update(ctx context.Context, r *domain.Refund) error
+	// ListByOrder возвращает возвраты заказа по возрастанию ID
+	ListByOrder(ctx context.Context, orderID int64) ([]domain.Refund, error)
+	List(ctx context.Context, f RefundFilter) ([]domain.Refund, error)
+}
+
+// OrderEditRepository журнал изменений заказов; записи не меняются и не удаляются
+type OrderEditRepository interface {
+	Create(ctx context.Context, e *domain.OrderEdit) error
+	// ListByOrder возвращает изменения заказа по возрастанию ID
+	ListByOrder(ctx context.Context, orderID int64) ([]domain.OrderEdit, error)
+}
+
+// CreditAccountFilter параметры выборки предоплаченных счетов
+type CreditAccountFilter struct {
+	Kind     domain.CreditAccountKind
+	Code     string
+	Customer string
+}
+
+// CreditRepository интерфейс репозитория подарочных карт и депозитов. Баланс
+// меняется только через Post вместе с записью в истории; записи не меняются и не удаляются.
+type CreditRepository interface {
+	// Create возвращает ErrConflict, если код занят или у покупателя уже есть депозит
+	Create(ctx context.Context, a *domain.CreditAccount) error
+	GetByID(ctx context.Context, id int64) (*domain.CreditAccount, error)
+	GetByCode(ctx context.Context, code string) (*domain.CreditAccount, error)
+	List(ctx context.Context, f CreditAccountFilter) ([]domain.CreditAccount, error)
+	// Post меняет баланс счёта на t.Amount и добавляет запись; заполняет t.ID, t.Balance и t.CreatedAt
+	Post(ctx context.Context, t *domain.CreditTransaction) error
+	// Transactions возвращает историю счёта в порядке операций
+	Transactions(ctx context.Context, accountID int64) ([]domain.CreditTransaction, error)
+}
+
+// LoyaltyFilter параметры выборки журнала баллов
+type LoyaltyFilter struct {
+	Customer string
+	OrderID  int64
+}
+
+// LoyaltyRepository правила и журнал программы лояльности. Баланс покупателя
+// складывается из записей журнала; записи не меняются и не удаляются.
+type LoyaltyRepository interface {
+	Rules(ctx context.Context) (domain.LoyaltyRules, error)
+	SetRules(ctx context.Context, r domain.LoyaltyRules) error
+	// Append добавляет запись; заполняет e.ID, e.Balance и e.CreatedAt
+	Append(ctx context.Context, e *domain.LoyaltyEntry) error
+	Balance(ctx context.Context, customer string) (int64, error)
+	// Entries возвращает записи в порядке операций
+	Entries(ctx context.Context, f LoyaltyFilter) ([]domain.LoyaltyEntry, error)
+}
+
+// CartRepository интерфейс репозитория корзин
+type CartRepository interface {
+	Create(ctx context.Context, c *domain.Cart) error
+	GetByID(ctx context.Context, id int64) (*domain.Cart, error)
+	Update(ctx context.Context, c *domain.Cart) error
+}
+
+// OrderFilter параметры выборки заказов; период создания [CreatedFrom, CreatedTo)
+type OrderFilter struct {
+	Status      domain.OrderStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// RefundFilter параметры выборки возвратов денег
+type RefundFilter struct {
+	Kind        domain.RefundKind
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+}
+
+// PriceChangeFilter параметры выборки изменений цены
+type PriceChangeFilter struct {
+	ProductID int64
+	Status    domain.PriceChangeStatus
+	// DueBy изменения, вступающие в силу не позже этого момента
+	DueBy *time.Time
+}
+
+// PriceChangeRepository интерфейс истории и расписания цен
+type PriceChangeRepository interface {
+	Create(ctx context.Context, c *domain.PriceChange) error
+	GetByID(ctx context.Context, id int64) (*domain.PriceChange, error)
+	Update(ctx context.Context, c *domain.PriceChange) error
+	// List возвращает изменения по возрастанию EffectiveFrom
+	List(ctx context.Context, f PriceChangeFilter) ([]domain.PriceChange, error)
+}
+
+// PromotionRepository интерфейс репозитория акций
+type PromotionRepository interface {
+	// Create и Update возвращают ErrConflict, если промокод уже занят
+	Create(ctx context.Context, p *domain.Promotion) error
+	GetByID(ctx context.Context, id int64) (*domain.Promotion, error)
+	GetByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	Update(ctx context.Context, p *domain.Promotion) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]domain.Promotion, error)
+}
+
+// AlertFilter параметры выборки оповещений о низком запасе
+type AlertFilter struct {
+	OpenOnly bool
+}
+
+// AlertRepository интерфейс репозитория оповещений о низком запасе
+type AlertRepository interface {
+	Create(ctx context.Context, a *domain.LowStockAlert) error
+	Update(ctx context.Context, a *domain.LowStockAlert) error
+	// OpenByProduct возвращает открытое оповещение по товару или ErrNotFound
+	OpenByProduct(ctx context.Context, productID int64) (*domain.LowStockAlert, error)
+	List(ctx context.Context, f AlertFilter) ([]domain.LowStockAlert, error)
+}
+
+// SupplierRepository интерфейс репозитория поставщиков
+type SupplierRepository interface {
+	Create(ctx context.Context, s *domain.Supplier) error
+	GetByID(ctx context.Context, id int64) (*domain.Supplier, error)
+	Update(ctx context.Context, s *domain.Supplier) error
+	List(ctx context.Context) ([]domain.Supplier, error)
+}
+
+// PurchaseOrderFilter параметры выборки заказов поставщикам
+type PurchaseOrderFilter struct {
+	SupplierID int64
+	Status     domain.PurchaseOrderStatus
+}
+
+// PurchaseOrderRepository интерфейс репозитория заказов поставщикам
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, po *domain.PurchaseOrder) error
+	GetByID(ctx context.Context, id int64) (*domain.PurchaseOrder, error)
+	Update(ctx context.Context, po *domain.PurchaseOrder) error
+	List(ctx context.Context, f PurchaseOrderFilter) ([]domain.PurchaseOrder, error)
+}
+
+// BatchRepository интерфейс репозитория серий товара
+type BatchRepository interface {
+	Create(ctx context.Context, b *domain.Batch) error
+	GetByID(ctx context.Context, id int64) (*domain.Batch, error)
+	// FindByNumber ищет серию товара по номеру; ErrNotFound если её нет
+	FindByNumber(ctx context.Context, productID int64, number string) (*domain.Batch, error)
+	List(ctx context.Context, productID int64) ([]domain.Batch, error)
+}
+
+// SerialFilter параметры выборки маркированных упаковок
+type SerialFilter struct {
+	ProductID int64
+	BatchID   int64
+	OrderID   int64
+	Status    domain.SerialStatus
+}
+
+// SerialRepository интерфейс репозитория маркированных упаковок; код уникален (ErrConflict)
+type SerialRepository interface {
+	Create(ctx context.Context, u *domain.SerialUnit) error
+	Get(ctx context.Context, code string) (*domain.SerialUnit, error)
+	Update(ctx context.Context, u *domain.SerialUnit) error
+	List(ctx context.Context, f SerialFilter) ([]domain.SerialUnit, error)
+}
+
+// StocktakeRepository интерфейс репозитория инвентаризаций
+type StocktakeRepository interface {
+	Create(ctx context.Context, st *domain.Stocktake) error
+	GetByID(ctx context.Context, id int64) (*domain.Stocktake, error)
+	Update(ctx context.Context, st *domain.Stocktake) error
+	List(ctx context.Context) ([]domain.Stocktake, error)
+}
+
+// CategoryRepository интерфейс репозитория категорий; слаг уникален
+type CategoryRepository interface {
+	Create(ctx context.Context, c *domain.Category) error
+	GetByID(ctx context.Context, id int64) (*domain.Category, error)
+	GetBySlug(ctx context.Context, slug string) (*domain.Category, error)
+	Update(ctx context.Context, c *domain.Category) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context) ([]domain.Category, error)
 }
 
 // TxManager абстракция транзакции. Для in-memory — глобальная блокировка записи.
@@ -46,3 +284,136 @@ func containsIgnoreCase(s, substr string) bool {
 	}
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
+
+// matchesProduct проверяет товар по всем условиям фильтра, кроме IDs
+func matchesProduct(p domain.Product, f ProductFilter) bool {
+	if !containsIgnoreCase(p.Name, f.NameSubstring) {
+		return false
+	}
+	if f.MinPrice != nil && p.Price < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && p.Price > *f.MaxPrice {
+		return false
+	}
+	if len(f.CategoryIDs) > 0 && !hasAny(p.CategoryIDs, f.CategoryIDs) {
+		return false
+	}
+	if len(f.Attributes) > 0 && !matchesAttributes(p.Attributes, f.Attributes) {
+		return false
+	}
+	if f.InStock != nil && (p.Stock > 0) != *f.InStock {
+		return false
+	}
+	if f.MinStock != nil && p.Stock < *f.MinStock {
+		return false
+	}
+	if f.SKUPrefix != "" && !strings.HasPrefix(strings.ToLower(p.SKU), strings.ToLower(f.SKUPrefix)) {
+		return false
+	}
+	if !inRange(p.CreatedAt, f.CreatedFrom, f.CreatedTo) || !inRange(p.UpdatedAt, f.UpdatedFrom, f.UpdatedTo) {
+		return false
+	}
+	if f.Archived != nil && (p.ArchivedAt != nil) != *f.Archived {
+		return false
+	}
+	return true
+}
+
+// inRange проверяет попадание t в полуинтервал [from, to); nil — без границы
+func inRange(t time.Time, from, to *time.Time) bool {
+	if from != nil && t.Before(*from) {
+		return false
+	}
+	return to == nil || t.Before(*to)
+}
+
+// computeFacets считает агрегаты по уже отфильтрованным товарам
+func computeFacets(products []domain.Product, opts FacetOptions) *domain.ProductFacets {
+	out := &domain.ProductFacets{Total: len(products)}
+	edges := append([]float64(nil), opts.PriceEdges...)
+	sort.Float64s(edges)
+	buckets := make([]domain.PriceBucket, len(edges)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].From = edges[i-1]
+		}
+		if i < len(edges) {
+			to := edges[i]
+			buckets[i].To = &to
+		}
+	}
+	categories := make(map[int64]int)
+	manufacturers := make(map[string]int)
+	for _, p := range products {
+		buckets[sort.Search(len(edges), func(i int) bool { return edges[i] > p.Price })].Count++
+		if p.Stock > 0 {
+			out.InStock++
+		} else {
+			out.OutOfStock++
+		}
+		seen := make(map[int64]bool)
+		for _, id := range p.CategoryIDs {
+			for _, c := range append([]int64{id}, opts.CategoryAncestors[id]...) {
+				if !seen[c] {
+					seen[c] = true
+					categories[c]++
+				}
+			}
+		}
+		if m := p.Attributes[domain.AttrManufacturer]; m != "" {
+			manufacturers[m]++
+		}
+	}
+	out.Price = buckets
+	out.Categories = make([]domain.CategoryFacet, 0, len(categories))
+	for id, n := range categories {
+		out.Categories = append(out.Categories, domain.CategoryFacet{CategoryID: id, Count: n})
+	}
+	sort.Slice(out.Categories, func(i, j int) bool { return out.Categories[i].CategoryID < out.Categories[j].CategoryID })
+	out.Manufacturers = make([]domain.FacetCount, 0, len(manufacturers))
+	for v, n := range manufacturers {
+		out.Manufacturers = append(out.Manufacturers, domain.FacetCount{Value: v, Count: n})
+	}
+	sort.Slice(out.Manufacturers, func(i, j int) bool {
+		a, b := out.Manufacturers[i], out.Manufacturers[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Value < b.Value
+	})
+	return out
+}
+
+// helper: пересекаются ли множества значений
+func hasAny[T comparable](have, want []T) bool {
+	for _, v := range have {
+		for _, w := range want {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// helper: значение атрибута совпадает с одним из искомых без учёта регистра
+func matchesAttributes(attrs map[string]string, want map[string][]string) bool {
+	for code, values := range want {
+		v, ok := attrs[code]
+		if !ok {
+			return false
+		}
+		found := false
+		for _, w := range values {
+			if strings.EqualFold(v, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
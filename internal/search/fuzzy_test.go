@@ -0,0 +1,23 @@
+package search
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"aspirin", "aspirin", 2, 0},
+		{"aspirin", "asprin", 2, 1},
+		{"aspirin", "aspirni", 2, 1}, // перестановка соседних букв
+		{"nurofen", "nurafin", 2, 2},
+		{"nurofen", "paracetamol", 2, 3},
+		{"аспирин", "оспирин", 1, 1},
+	}
+	for _, c := range cases {
+		if got := Distance(c.a, c.b, c.limit); got != c.want {
+			t.Fatalf("Distance(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
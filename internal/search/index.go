@@ -0,0 +1,159 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Field текст документа с весом; совпадение в названии важнее, чем в атрибутах
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Hit найденный документ и его релевантность
+type Hit struct {
+	ID    int64
+	Score float64
+}
+
+// Качество совпадения терма запроса с термом индекса
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.7
+	fuzzyMatch  = 0.5 // за одну правку; за две — вдвое меньше
+)
+
+// Index инвертированный индекс в памяти; безопасен для конкурентного использования
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int64]float64 // терм -> документ -> суммарный вес полей
+	docs     map[int64][]string           // документ -> его термы, для удаления
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int64]float64),
+		docs:     make(map[int64][]string),
+	}
+}
+
+// Put индексирует документ, заменяя предыдущую версию
+func (ix *Index) Put(id int64, fields ...Field) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+	weights := make(map[string]float64)
+	for _, f := range fields {
+		for _, t := range Terms(f.Text) {
+			weights[t] += f.Weight
+		}
+	}
+	terms := make([]string, 0, len(weights))
+	for t, w := range weights {
+		if ix.postings[t] == nil {
+			ix.postings[t] = make(map[int64]float64)
+		}
+		ix.postings[t][id] = w
+		terms = append(terms, t)
+	}
+	ix.docs[id] = terms
+}
+
+// Remove убирает документ из индекса
+func (ix *Index) Remove(id int64) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id int64) {
+	for _, t := range ix.docs[id] {
+		delete(ix.postings[t], id)
+		if len(ix.postings[t]) == 0 {
+			delete(ix.postings, t)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Len число проиндексированных документов
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Search находит документы, в которых встречается каждое слово запроса (точно,
+// как начало слова или с опечаткой), и сортирует их по убыванию релевантности
+func (ix *Index) Search(query string) []Hit {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var scores map[int64]float64
+	for _, w := range words {
+		termScores := make(map[int64]float64)
+		for _, qt := range wordTerms(w) {
+			for id, sc := range ix.match(qt) {
+				termScores[id] = max(termScores[id], sc)
+			}
+		}
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, sc := range scores {
+			if ts, ok := termScores[id]; ok {
+				scores[id] = sc + ts
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	hits := make([]Hit, 0, len(scores))
+	for id, sc := range scores {
+		hits = append(hits, Hit{ID: id, Score: sc})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// match оценивает документы по одному терму запроса: для каждого берётся лучшее
+// совпадение с учётом веса полей и редкости терма (idf)
+func (ix *Index) match(qt string) map[int64]float64 {
+	out := make(map[int64]float64)
+	edits := maxEdits(qt)
+	n := float64(len(ix.docs))
+	for t, docs := range ix.postings {
+		var quality float64
+		switch {
+		case t == qt:
+			quality = exactMatch
+		case len([]rune(qt)) >= 3 && strings.HasPrefix(t, qt):
+			quality = prefixMatch
+		case edits > 0:
+			d := Distance(qt, t, edits)
+			if d > edits {
+				continue
+			}
+			quality = fuzzyMatch / float64(d)
+		default:
+			continue
+		}
+		idf := math.Log(1 + n/float64(len(docs)))
+		for id, w := range docs {
+			out[id] = max(out[id], quality*w*idf)
+		}
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func ids(hits []Hit) []int64 {
+	out := make([]int64, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.ID)
+	}
+	return out
+}
+
+func TestIndex_SearchAndRanking(t *testing.T) {
+	ix := NewIndex()
+	ix.Put(1, Field{Text: "Аспирин Кардио", Weight: 3}, Field{Text: "Bayer", Weight: 1})
+	ix.Put(2, Field{Text: "Aspirin C шипучие таблетки", Weight: 3})
+	ix.Put(3, Field{Text: "Кардиомагнил", Weight: 3}, Field{Text: "аспирин", Weight: 1})
+	ix.Put(4, Field{Text: "Нурофен", Weight: 3})
+
+	cases := map[string][]int64{
+		"aspirin":          {1, 2, 3},
+		"аспирина":         {1, 2, 3},
+		"asprin":           {1, 2, 3}, // опечатка
+		"аспирин таблетки": {2},
+		"кардио":           {1, 3}, // начало слова
+		"nurofen":          {4},
+		"bayer":            {1},
+		"парацетамол":      {},
+	}
+	for q, want := range cases {
+		got := ids(ix.Search(q))
+		sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+		if !slices.Equal(got, want) {
+			t.Fatalf("Search(%q) = %v, want %v", q, got, want)
+		}
+	}
+	// совпадение в названии выше совпадения в атрибуте
+	hits := ix.Search("аспирин")
+	if hits[len(hits)-1].ID != 3 {
+		t.Fatalf("attribute match should rank last: %v", ids(hits))
+	}
+
+	ix.Put(4, Field{Text: "Нурофен Экспресс", Weight: 3})
+	if got := ids(ix.Search("экспресс")); len(got) != 1 || got[0] != 4 {
+		t.Fatalf("reindex failed: %v", got)
+	}
+	ix.Remove(4)
+	if got := ix.Search("nurofen"); len(got) != 0 || ix.Len() != 3 {
+		t.Fatalf("remove failed: %v", got)
+	}
+}
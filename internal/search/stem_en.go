@@ -0,0 +1,102 @@
+package search
+
+import "strings"
+
+// Облегчённый стеммер Портера для английского: шаги 1a–1c (множественное число,
+// -ed/-ing, конечная y). Словообразовательные суффиксы не отсекаются — для названий
+// товаров этого достаточно, а опечатки покрывает нечёткое сравнение.
+
+func isEnConsonant(w string, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isEnConsonant(w, i-1)
+	}
+	return true
+}
+
+// enMeasure число последовательностей «гласные-согласные» (m в описании Портера)
+func enMeasure(w string) int {
+	m, i := 0, 0
+	for i < len(w) && isEnConsonant(w, i) {
+		i++
+	}
+	for i < len(w) {
+		for i < len(w) && !isEnConsonant(w, i) {
+			i++
+		}
+		if i == len(w) {
+			break
+		}
+		for i < len(w) && isEnConsonant(w, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func enHasVowel(w string) bool {
+	for i := range w {
+		if !isEnConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// enCVC основа оканчивается на согласная-гласная-согласная, последняя не w, x, y
+func enCVC(w string) bool {
+	n := len(w)
+	if n < 3 || !isEnConsonant(w, n-1) || isEnConsonant(w, n-2) || !isEnConsonant(w, n-3) {
+		return false
+	}
+	switch w[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func stemEnglish(w string) string {
+	if len(w) <= 2 {
+		return w
+	}
+	// 1a
+	switch {
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "ies"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"):
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+	// 1b
+	fixup := false
+	switch {
+	case strings.HasSuffix(w, "eed"):
+		if enMeasure(w[:len(w)-3]) > 0 {
+			w = w[:len(w)-1]
+		}
+	case strings.HasSuffix(w, "ed") && enHasVowel(w[:len(w)-2]):
+		w, fixup = w[:len(w)-2], true
+	case strings.HasSuffix(w, "ing") && enHasVowel(w[:len(w)-3]):
+		w, fixup = w[:len(w)-3], true
+	}
+	if fixup {
+		n := len(w)
+		switch {
+		case strings.HasSuffix(w, "at"), strings.HasSuffix(w, "bl"), strings.HasSuffix(w, "iz"):
+			w += "e"
+		case n >= 2 && w[n-1] == w[n-2] && isEnConsonant(w, n-1) && !strings.ContainsAny(w[n-1:], "lsz"):
+			w = w[:n-1]
+		case enMeasure(w) == 1 && enCVC(w):
+			w += "e"
+		}
+	}
+	// 1c
+	if strings.HasSuffix(w, "y") && enHasVowel(w[:len(w)-1]) {
+		w = w[:len(w)-1] + "i"
+	}
+	return w
+}
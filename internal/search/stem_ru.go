@@ -0,0 +1,145 @@
+package search
+
+// Стеммер Портера (Snowball) для русского языка. Работает с нижним регистром, ё заменяется на е.
+
+var (
+	ruPerfectiveGerund1 = []string{"вшись", "вши", "в"}
+	ruPerfectiveGerund2 = []string{"ившись", "ывшись", "ивши", "ывши", "ив", "ыв"}
+	ruAdjective         = []string{"ими", "ыми", "его", "ого", "ему", "ому", "ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею"}
+	ruParticiple1       = []string{"ем", "нн", "вш", "ющ", "щ"}
+	ruParticiple2       = []string{"ивш", "ывш", "ующ"}
+	ruReflexive         = []string{"ся", "сь"}
+	ruVerb1             = []string{"ете", "йте", "ешь", "нно", "ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть", "й", "л", "н"}
+	ruVerb2             = []string{"ейте", "уйте", "ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют", "ены", "ить", "ыть", "ишь", "ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую", "ю"}
+	ruNoun              = []string{"иями", "ями", "ами", "ией", "иям", "ием", "иях", "ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом", "ах", "ях", "ию", "ью", "ия", "ья", "а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я"}
+	ruSuperlative       = []string{"ейше", "ейш"}
+	ruDerivational      = []string{"ость", "ост"}
+)
+
+func isRuVowel(r rune) bool {
+	switch r {
+	case 'а', 'е', 'и', 'о', 'у', 'ы', 'э', 'ю', 'я':
+		return true
+	}
+	return false
+}
+
+// ruRegions возвращает начала областей RV и R2
+func ruRegions(w []rune) (rv, r2 int) {
+	rv = len(w)
+	for i, r := range w {
+		if isRuVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	return rv, nextRegion(w, nextRegion(w, 0))
+}
+
+// nextRegion позиция после первой согласной, следующей за гласной, начиная с from
+func nextRegion(w []rune, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isRuVowel(w[i]) && isRuVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+// ruSuffix ищет самое длинное из окончаний в области [limit:]; если requirePrev,
+// окончанию должна предшествовать а или я в той же области. Возвращает длину окончания.
+func ruSuffix(w []rune, limit int, suffixes []string, requirePrev bool) int {
+	best := 0
+	for _, s := range suffixes {
+		suf := []rune(s)
+		n := len(suf)
+		if n <= best || len(w)-n < limit || !hasRuneSuffix(w, suf) {
+			continue
+		}
+		if requirePrev {
+			i := len(w) - n - 1
+			if i < limit || (w[i] != 'а' && w[i] != 'я') {
+				continue
+			}
+		}
+		best = n
+	}
+	return best
+}
+
+func hasRuneSuffix(w, suf []rune) bool {
+	if len(suf) > len(w) {
+		return false
+	}
+	off := len(w) - len(suf)
+	for i, r := range suf {
+		if w[off+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// ruGroup удаляет окончание из пары групп (первая требует а/я перед собой)
+func ruGroup(w []rune, rv int, group1, group2 []string) ([]rune, bool) {
+	n1 := ruSuffix(w, rv, group1, true)
+	n2 := ruSuffix(w, rv, group2, false)
+	if n1 == 0 && n2 == 0 {
+		return w, false
+	}
+	return w[:len(w)-max(n1, n2)], true
+}
+
+func stemRussian(word string) string {
+	w := []rune(word)
+	for i, r := range w {
+		if r == 'ё' {
+			w[i] = 'е'
+		}
+	}
+	rv, r2 := ruRegions(w)
+	if rv >= len(w) {
+		return string(w)
+	}
+
+	// Шаг 1
+	if out, ok := ruGroup(w, rv, ruPerfectiveGerund1, ruPerfectiveGerund2); ok {
+		w = out
+	} else {
+		if n := ruSuffix(w, rv, ruReflexive, false); n > 0 {
+			w = w[:len(w)-n]
+		}
+		if n := ruSuffix(w, rv, ruAdjective, false); n > 0 {
+			w = w[:len(w)-n]
+			w, _ = ruGroup(w, rv, ruParticiple1, ruParticiple2)
+		} else if out, ok := ruGroup(w, rv, ruVerb1, ruVerb2); ok {
+			w = out
+		} else if n := ruSuffix(w, rv, ruNoun, false); n > 0 {
+			w = w[:len(w)-n]
+		}
+	}
+
+	// Шаг 2
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Шаг 3
+	if n := ruSuffix(w, r2, ruDerivational, false); n > 0 {
+		w = w[:len(w)-n]
+	}
+
+	// Шаг 4
+	superlative := false
+	if n := ruSuffix(w, rv, ruSuperlative, false); n > 0 {
+		w = w[:len(w)-n]
+		superlative = true
+	}
+	switch {
+	case hasRuneSuffix(w, []rune("нн")) && len(w)-2 >= rv:
+		w = w[:len(w)-1]
+	case !superlative && len(w) > rv && w[len(w)-1] == 'ь':
+		w = w[:len(w)-1]
+	}
+	return string(w)
+}
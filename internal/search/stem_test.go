@@ -0,0 +1,52 @@
+package search
+
+import "testing"
+
+func TestStemRussian(t *testing.T) {
+	cases := map[string]string{
+		"аспирина":       "аспирин",
+		"витамины":       "витамин",
+		"таблетки":       "таблетк",
+		"таблеткой":      "таблетк",
+		"обезболивающие": "обезболива",
+		"красивейший":    "красив",
+		"лекарственные":  "лекарствен",
+		"бегавшая":       "бега",
+		"ёлки":           "елк",
+	}
+	for in, want := range cases {
+		if got := Stem(in); got != want {
+			t.Fatalf("Stem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	cases := map[string]string{
+		"tablets":  "tablet",
+		"vitamins": "vitamin",
+		"caresses": "caress",
+		"ponies":   "poni",
+		"running":  "run",
+		"agreed":   "agree",
+		"c":        "c",
+	}
+	for in, want := range cases {
+		if got := Stem(in); got != want {
+			t.Fatalf("Stem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := Terms("Аспирин-C, таблетки 500мг")
+	want := []string{"aspirin", "c", "tabletk", "tabletki", "500mg"}
+	if len(got) != len(want) {
+		t.Fatalf("Terms = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Terms = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package search — полнотекстовый поиск товаров: токенизация, стемминг русского и
+// английского, приведение к латинице, нечёткое сравнение и ранжирование
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"april/internal/translit"
+)
+
+// Tokenize разбивает текст на слова из букв и цифр в нижнем регистре
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Stem отсекает окончание слова стеммером его алфавита
+func Stem(word string) string {
+	for _, r := range word {
+		if r >= 'а' && r <= 'я' || r == 'ё' {
+			return stemRussian(word)
+		}
+	}
+	return stemEnglish(word)
+}
+
+// Terms переводит текст в термы индекса: основа и полная форма каждого слова в
+// латинице, так что «Аспирина» и «aspirin» дают общий терм. Полная форма нужна
+// для названий, которые стеммер укорачивает как обычные слова (Нурофен -> нуроф).
+func Terms(text string) []string {
+	var out []string
+	for _, w := range Tokenize(text) {
+		out = append(out, wordTerms(w)...)
+	}
+	return out
+}
+
+func wordTerms(word string) []string {
+	stem, full := translit.ToLatin(Stem(word)), translit.ToLatin(word)
+	if stem == full {
+		return []string{stem}
+	}
+	return []string{stem, full}
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"april/internal/translit"
+)
+
+// Trie префиксный индекс слов документов для автодополнения. В каждом узле хранится
+// множество документов поддерева, поэтому поиск стоит O(длина префикса + ответ).
+type Trie struct {
+	mu   sync.RWMutex
+	root *trieNode
+	keys map[int64][]string // документ -> его ключи, для удаления
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	docs     map[int64]int // документ -> число его ключей в поддереве
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode), docs: make(map[int64]int)}
+}
+
+func NewTrie() *Trie {
+	return &Trie{root: newTrieNode(), keys: make(map[int64][]string)}
+}
+
+// trieKeys слова текста в нижнем регистре и их латинская запись
+func trieKeys(word string) []string {
+	word = strings.ReplaceAll(word, "ё", "е")
+	if lat := translit.ToLatin(word); lat != word {
+		return []string{word, lat}
+	}
+	return []string{word}
+}
+
+// Put индексирует слова текста документа, заменяя предыдущую версию
+func (t *Trie) Put(id int64, text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.remove(id)
+	var keys []string
+	for _, w := range Tokenize(text) {
+		keys = append(keys, trieKeys(w)...)
+	}
+	for _, k := range keys {
+		n := t.root
+		for _, r := range k {
+			child := n.children[r]
+			if child == nil {
+				child = newTrieNode()
+				n.children[r] = child
+			}
+			n = child
+			n.docs[id]++
+		}
+	}
+	t.keys[id] = keys
+}
+
+// Remove убирает документ из индекса
+func (t *Trie) Remove(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.remove(id)
+}
+
+func (t *Trie) remove(id int64) {
+	for _, k := range t.keys[id] {
+		n := t.root
+		for _, r := range k {
+			child := n.children[r]
+			if child.docs[id]--; child.docs[id] == 0 {
+				delete(child.docs, id)
+			}
+			if len(child.docs) == 0 {
+				delete(n.children, r)
+				break
+			}
+			n = child
+		}
+	}
+	delete(t.keys, id)
+}
+
+// Match возвращает документы, в которых каждое слово запроса — начало какого-либо
+// слова документа (в исходной записи или латиницей); id по возрастанию
+func (t *Trie) Match(query string) []int64 {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var found map[int64]bool
+	for _, w := range words {
+		docs := make(map[int64]bool)
+		for _, k := range trieKeys(w) {
+			if n := t.find(k); n != nil {
+				for id := range n.docs {
+					docs[id] = true
+				}
+			}
+		}
+		if found == nil {
+			found = docs
+			continue
+		}
+		for id := range found {
+			if !docs[id] {
+				delete(found, id)
+			}
+		}
+	}
+	out := make([]int64, 0, len(found))
+	for id := range found {
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func (t *Trie) find(prefix string) *trieNode {
+	n := t.root
+	for _, r := range prefix {
+		if n = n.children[r]; n == nil {
+			return nil
+		}
+	}
+	return n
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTrie_Match(t *testing.T) {
+	tr := NewTrie()
+	tr.Put(1, "Аспирин Кардио")
+	tr.Put(2, "Aspirin C")
+	tr.Put(3, "Ацикловир")
+	tr.Put(4, "Нурофен Экспресс")
+
+	cases := map[string][]int64{
+		"ас":         {1, 2},
+		"ац":         {3},
+		"asp":        {1, 2}, // «Аспирин» индексирован и латиницей
+		"аспирин к":  {1},
+		"нур экс":    {4},
+		"нур кардио": {},
+		"x":          {},
+	}
+	for q, want := range cases {
+		if got := tr.Match(q); !slices.Equal(got, want) {
+			t.Fatalf("Match(%q) = %v, want %v", q, got, want)
+		}
+	}
+
+	tr.Put(4, "Нурофен")
+	if got := tr.Match("экс"); len(got) != 0 {
+		t.Fatalf("stale words after reindex: %v", got)
+	}
+	tr.Remove(1)
+	if got := tr.Match("кар"); len(got) != 0 {
+		t.Fatalf("removed document still matches: %v", got)
+	}
+	if got := tr.Match("asp"); !slices.Equal(got, []int64{2}) {
+		t.Fatalf("unexpected match after remove: %v", got)
+	}
+}
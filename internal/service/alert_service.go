@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// AlertService отслеживает точки дозаказа и ведёт оповещения о низком запасе.
+// Проверка запускается по доменным событиям заказов и изменений товаров.
+type AlertService struct {
+	products repository.ProductRepository
+	alerts   repository.AlertRepository
+	notifier Notifier
+	// mu сериализует проверки, чтобы по товару не открывалось двух оповещений
+	mu sync.Mutex
+}
+
+func NewAlertService(products repository.ProductRepository, alerts repository.AlertRepository, notifier Notifier) *AlertService {
+	return &AlertService{products: products, alerts: alerts, notifier: notifier}
+}
+
+// Subscribe подписывает проверку запаса на события, меняющие остатки.
+// Обработчики асинхронные: доставка оповещений не задерживает заказ.
+func (s *AlertService) Subscribe(bus *events.Bus) {
+	events.OnAsync(bus, func(ctx context.Context, e events.OrderCreated) {
+		s.checkLogged(ctx, itemProductIDs(e.Order.Items)...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.OrderCancelled) {
+		s.checkLogged(ctx, itemProductIDs(e.Order.Items)...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.OrderReturned) {
+		s.checkLogged(ctx, itemProductIDs(e.Returned)...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.OrderLinesCancelled) {
+		s.checkLogged(ctx, itemProductIDs(e.Cancelled)...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.OrderEdited) {
+		ids := make([]int64, 0, len(e.Edit.Changes))
+		for _, c := range e.Edit.Changes {
+			ids = append(ids, c.ProductID)
+		}
+		s.checkLogged(ctx, ids...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.GoodsReceived) {
+		ids := make([]int64, 0, len(e.Lines))
+		for _, l := range e.Lines {
+			ids = append(ids, l.ProductID)
+		}
+		s.checkLogged(ctx, ids...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.StocktakeApproved) {
+		ids := make([]int64, 0, len(e.Stocktake.Lines))
+		for _, l := range e.Stocktake.Lines {
+			if l.Adjusted != 0 {
+				ids = append(ids, l.ProductID)
+			}
+		}
+		s.checkLogged(ctx, ids...)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.ProductCreated) {
+		s.checkLogged(ctx, e.Product.ID)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.ProductUpdated) {
+		s.checkLogged(ctx, e.Product.ID)
+	})
+	events.OnAsync(bus, func(ctx context.Context, e events.ProductDeleted) {
+		s.checkLogged(ctx, e.ProductID)
+	})
+}
+
+func (s *AlertService) checkLogged(ctx context.Context, ids ...int64) {
+	if err := s.Check(ctx, ids...); err != nil {
+		log.Printf("low-stock check failed: %v", err)
+	}
+}
+
+// Check сверяет запас товаров с точкой дозаказа: открывает оповещение,
+// когда запас опустился до порога, и закрывает его после пополнения или удаления товара.
+// Уведомления отправляются после снятия блокировки: медленный вебхук не задерживает
+// другие проверки.
+func (s *AlertService) Check(ctx context.Context, productIDs ...int64) error {
+	created, err := s.check(ctx, productIDs)
+	if s.notifier != nil {
+		for _, a := range created {
+			if err := s.notifier.Notify(ctx, a); err != nil {
+				log.Printf("low-stock notify product %d: %v", a.ProductID, err)
+			}
+		}
+	}
+	return err
+}
+
+// check обновляет оповещения под блокировкой и возвращает новые открытые
+func (s *AlertService) check(ctx context.Context, productIDs []int64) ([]domain.LowStockAlert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var created []domain.LowStockAlert
+	for _, id := range productIDs {
+		open, err := s.alerts.OpenByProduct(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return created, err
+		}
+		p, err := s.products.GetByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			// товар удалён — открытое оповещение больше не к чему относить
+			if open != nil {
+				if err := s.resolve(ctx, open, open.Stock); err != nil {
+					return created, err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+		switch {
+		case p.LowStock() && open == nil:
+			a := domain.LowStockAlert{
+				ProductID:    p.ID,
+				ProductName:  p.Name,
+				Stock:        p.Stock,
+				ReorderPoint: p.ReorderPoint,
+				ReorderQty:   p.ReorderQty,
+				Status:       domain.AlertStatusOpen,
+			}
+			if err := s.alerts.Create(ctx, &a); err != nil {
+				return created, err
+			}
+			created = append(created, a)
+		case p.LowStock() && open != nil:
+			// оповещение уже открыто — только актуализируем остаток
+			open.Stock = p.Stock
+			if err := s.alerts.Update(ctx, open); err != nil {
+				return created, err
+			}
+		case !p.LowStock() && open != nil:
+			if err := s.resolve(ctx, open, p.Stock); err != nil {
+				return created, err
+			}
+		}
+	}
+	return created, nil
+}
+
+func (s *AlertService) resolve(ctx context.Context, a *domain.LowStockAlert, stock int64) error {
+	now := time.Now().UTC()
+	a.Stock = stock
+	a.Status = domain.AlertStatusResolved
+	a.ResolvedAt = &now
+	return s.alerts.Update(ctx, a)
+}
+
+// ListLowStock возвращает оповещения; openOnly — только незакрытые
+func (s *AlertService) ListLowStock(ctx context.Context, openOnly bool) ([]domain.LowStockAlert, error) {
+	return s.alerts.List(ctx, repository.AlertFilter{OpenOnly: openOnly})
+}
+
+func itemProductIDs(items []domain.OrderItem) []int64 {
+	seen := make(map[int64]bool, len(items))
+	ids := make([]int64, 0, len(items))
+	for _, it := range items {
+		if !seen[it.ProductID] {
+			seen[it.ProductID] = true
+			ids = append(ids, it.ProductID)
+		}
+	}
+	return ids
+}
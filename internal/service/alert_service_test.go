@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []domain.LowStockAlert
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, a domain.LowStockAlert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, a)
+	return nil
+}
+
+func TestAlertService_OpenAndResolveOnEvents(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	os := NewOrderService(store, repository.NewMemoryOrders(store), repository.NewMemoryTx(store))
+	bus := events.NewBus()
+	ps.SetEvents(bus)
+	os.SetEvents(bus)
+	notifier := &recordingNotifier{}
+	as := NewAlertService(store, repository.NewMemoryAlerts(store), notifier)
+	as.Subscribe(bus)
+
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 10, ReorderPoint: 3, ReorderQty: 20})
+	o, err := os.CreateOrder(ctx, "John", []domain.OrderItem{{ProductID: p.ID, Quantity: 7}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	bus.Wait()
+	open, _ := as.ListLowStock(ctx, true)
+	if len(open) != 1 || open[0].ProductID != p.ID || open[0].Stock != 3 || open[0].ReorderQty != 20 {
+		t.Fatalf("expected one open alert, got %+v", open)
+	}
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("expected one notification, got %d", len(notifier.alerts))
+	}
+
+	// повторное снижение не открывает второе оповещение
+	if _, err := os.CreateOrder(ctx, "John", []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	bus.Wait()
+	open, _ = as.ListLowStock(ctx, true)
+	if len(open) != 1 || open[0].Stock != 2 || len(notifier.alerts) != 1 {
+		t.Fatalf("expected single refreshed alert, got %+v", open)
+	}
+
+	// отмена возвращает запас выше порога и закрывает оповещение
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	bus.Wait()
+	open, _ = as.ListLowStock(ctx, true)
+	all, _ := as.ListLowStock(ctx, false)
+	if len(open) != 0 || len(all) != 1 || all[0].Status != domain.AlertStatusResolved || all[0].ResolvedAt == nil {
+		t.Fatalf("expected resolved alert, got %+v", all)
+	}
+}
+
+func TestAlertService_IgnoresUntrackedProducts(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	as := NewAlertService(store, repository.NewMemoryAlerts(store), nil)
+	p := domain.Product{Name: "A", SKU: "S1", Price: 1, Stock: 0}
+	_ = store.Create(ctx, &p)
+	if err := as.Check(ctx, p.ID, 999); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	all, _ := as.ListLowStock(ctx, false)
+	if len(all) != 0 {
+		t.Fatalf("expected no alerts without reorder point, got %+v", all)
+	}
+}
+
+// blockingNotifier задерживает уведомление по одному товару, пока тест его не отпустит
+type blockingNotifier struct {
+	productID int64
+	started   chan struct{}
+	release   chan struct{}
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, a domain.LowStockAlert) error {
+	if a.ProductID == n.productID {
+		close(n.started)
+		<-n.release
+	}
+	return nil
+}
+
+func TestAlertService_SlowNotifierDoesNotBlockChecks(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	slow := domain.Product{Name: "A", SKU: "S1", Stock: 1, ReorderPoint: 3}
+	other := domain.Product{Name: "B", SKU: "S2", Stock: 1, ReorderPoint: 3}
+	_ = store.Create(ctx, &slow)
+	_ = store.Create(ctx, &other)
+	notifier := &blockingNotifier{productID: slow.ID, started: make(chan struct{}), release: make(chan struct{})}
+	as := NewAlertService(store, repository.NewMemoryAlerts(store), notifier)
+
+	go func() { _ = as.Check(ctx, slow.ID) }()
+	<-notifier.started
+	done := make(chan error, 1)
+	go func() { done <- as.Check(ctx, other.ID) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("check blocked by a pending notification")
+	}
+	close(notifier.release)
+	open, _ := as.ListLowStock(ctx, true)
+	if len(open) != 2 {
+		t.Fatalf("expected two open alerts, got %+v", open)
+	}
+}
+
+func TestAlertService_ResolveOnPurge(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	bus := events.NewBus()
+	ps.SetEvents(bus)
+	as := NewAlertService(store, repository.NewMemoryAlerts(store), nil)
+	as.Subscribe(bus)
+
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 1, Stock: 1, ReorderPoint: 3})
+	bus.Wait()
+	if open, _ := as.ListLowStock(ctx, true); len(open) != 1 {
+		t.Fatalf("expected open alert, got %+v", open)
+	}
+	if _, err := ps.Archive(ctx, p.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if err := ps.Purge(ctx, p.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	bus.Wait()
+	all, _ := as.ListLowStock(ctx, false)
+	if len(all) != 1 || all[0].Status != domain.AlertStatusResolved || all[0].ResolvedAt == nil {
+		t.Fatalf("expected alert of purged product resolved, got %+v", all)
+	}
+}
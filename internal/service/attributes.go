@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"april/internal/domain"
+)
+
+var attrCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// validateSchema проверяет схему атрибутов категории
+func validateSchema(defs []domain.AttributeDef) error {
+	seen := make(map[string]bool, len(defs))
+	for _, d := range defs {
+		field := "attributes." + d.Code
+		if !attrCodeRe.MatchString(d.Code) {
+			return invalidField("attributes", "invalid code %q", d.Code)
+		}
+		if seen[d.Code] {
+			return invalidField(field, "duplicate code")
+		}
+		seen[d.Code] = true
+		switch d.Type {
+		case domain.AttributeTypeString, domain.AttributeTypeNumber, domain.AttributeTypeInteger, domain.AttributeTypeBoolean:
+			if len(d.Options) > 0 {
+				return invalidField(field, "options are allowed only for enum")
+			}
+		case domain.AttributeTypeEnum:
+			if len(d.Options) == 0 {
+				return invalidField(field, "enum requires options")
+			}
+		default:
+			return invalidField(field, "unknown type %q", d.Type)
+		}
+	}
+	return nil
+}
+
+// effectiveSchema собирает схему атрибутов для набора категорий с учётом предков.
+// Ближайшее к товару определение кода перекрывает унаследованное.
+func effectiveSchema(all []domain.Category, categoryIDs []int64) []domain.AttributeDef {
+	byID := make(map[int64]domain.Category, len(all))
+	for _, c := range all {
+		byID[c.ID] = c
+	}
+	out := make([]domain.AttributeDef, 0)
+	seen := make(map[string]bool)
+	for _, id := range categoryIDs {
+		for c, ok := byID[id]; ok; c, ok = byID[c.ParentID] {
+			for _, d := range c.Attributes {
+				if !seen[d.Code] {
+					seen[d.Code] = true
+					out = append(out, d)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// normalizeAttributes проверяет значения по схеме и приводит их к каноническому виду
+func normalizeAttributes(schema []domain.AttributeDef, values map[string]string) (map[string]string, error) {
+	defs := make(map[string]domain.AttributeDef, len(schema))
+	for _, d := range schema {
+		defs[d.Code] = d
+	}
+	for code := range values {
+		if _, ok := defs[code]; !ok {
+			return nil, invalidField("attributes."+code, "not defined for product categories")
+		}
+	}
+	var out map[string]string
+	for _, d := range schema {
+		field := "attributes." + d.Code
+		raw, ok := values[d.Code]
+		raw = strings.TrimSpace(raw)
+		if !ok || raw == "" {
+			if d.Required {
+				return nil, invalidField(field, "required")
+			}
+			continue
+		}
+		v, err := normalizeValue(d, raw)
+		if err != nil {
+			return nil, invalidField(field, "%s", err.Error())
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[d.Code] = v
+	}
+	return out, nil
+}
+
+func normalizeValue(d domain.AttributeDef, raw string) (string, error) {
+	switch d.Type {
+	case domain.AttributeTypeNumber:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "", errors.New("must be a number")
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case domain.AttributeTypeInteger:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return "", errors.New("must be an integer")
+		}
+		return strconv.FormatInt(n, 10), nil
+	case domain.AttributeTypeBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", errors.New("must be a boolean")
+		}
+		return strconv.FormatBool(b), nil
+	case domain.AttributeTypeEnum:
+		for _, o := range d.Options {
+			if o == raw {
+				return raw, nil
+			}
+		}
+		return "", errors.New("must be one of " + strings.Join(d.Options, ", "))
+	default:
+		return raw, nil
+	}
+}
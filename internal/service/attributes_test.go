@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func pharmacySchema() []domain.AttributeDef {
+	return []domain.AttributeDef{
+		{Code: domain.AttrManufacturer, Name: "Производитель", Type: domain.AttributeTypeString, Required: true},
+		{Code: domain.AttrActiveSubstance, Name: "МНН", Type: domain.AttributeTypeString},
+		{Code: domain.AttrDosageForm, Name: "Форма", Type: domain.AttributeTypeEnum, Options: []string{"tablets", "syrup"}},
+		{Code: domain.AttrPackSize, Name: "В упаковке", Type: domain.AttributeTypeInteger},
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	if err := validateSchema(pharmacySchema()); err != nil {
+		t.Fatalf("valid schema rejected: %v", err)
+	}
+	bad := [][]domain.AttributeDef{
+		{{Code: "Bad Code", Type: domain.AttributeTypeString}},
+		{{Code: "a", Type: "date"}},
+		{{Code: "a", Type: domain.AttributeTypeEnum}},
+		{{Code: "a", Type: domain.AttributeTypeString, Options: []string{"x"}}},
+		{{Code: "a", Type: domain.AttributeTypeString}, {Code: "a", Type: domain.AttributeTypeNumber}},
+	}
+	for i, defs := range bad {
+		if err := validateSchema(defs); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("case %d: expected invalid input, got %v", i, err)
+		}
+	}
+}
+
+func TestProduct_AttributesValidatedByInheritedSchema(t *testing.T) {
+	ctx := context.Background()
+	ps, cs := setupCatalog(t)
+	meds, err := cs.Create(ctx, domain.Category{Name: "Meds", Attributes: pharmacySchema()})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	pain, _ := cs.Create(ctx, domain.Category{Name: "Pain", ParentID: meds.ID,
+		Attributes: []domain.AttributeDef{{Code: "otc", Type: domain.AttributeTypeBoolean}}})
+
+	schema, _ := cs.Schema(ctx, pain.ID)
+	if len(schema) != 5 {
+		t.Fatalf("expected inherited schema of 5 attributes, got %d", len(schema))
+	}
+
+	p, err := ps.Create(ctx, domain.Product{Name: "Aspirin", SKU: "S1", Price: 1, CategoryIDs: []int64{pain.ID},
+		Attributes: map[string]string{"manufacturer": " Bayer ", "pack_size": "20", "otc": "1", "dosage_form": "tablets"}})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if p.Attributes["manufacturer"] != "Bayer" || p.Attributes["otc"] != "true" {
+		t.Fatalf("attributes not normalized: %v", p.Attributes)
+	}
+
+	cases := []struct {
+		attrs map[string]string
+		field string
+	}{
+		{map[string]string{}, "attributes.manufacturer"},
+		{map[string]string{"manufacturer": "X", "pack_size": "2.5"}, "attributes.pack_size"},
+		{map[string]string{"manufacturer": "X", "dosage_form": "powder"}, "attributes.dosage_form"},
+		{map[string]string{"manufacturer": "X", "color": "red"}, "attributes.color"},
+	}
+	for _, c := range cases {
+		_, err := ps.Create(ctx, domain.Product{Name: "N", SKU: "S2", Price: 1, CategoryIDs: []int64{pain.ID}, Attributes: c.attrs})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("attrs %v: expected error on %s, got %v", c.attrs, c.field, err)
+		}
+	}
+
+	_, _ = ps.Create(ctx, domain.Product{Name: "Nurofen", SKU: "S3", Price: 1, CategoryIDs: []int64{meds.ID},
+		Attributes: map[string]string{"manufacturer": "Reckitt"}})
+	list, _ := ps.List(ctx, repository.ProductFilter{Attributes: map[string][]string{"manufacturer": {"bayer"}}})
+	if len(list) != 1 || list[0].ID != p.ID {
+		t.Fatalf("attribute filter: %+v", list)
+	}
+	list, _ = ps.List(ctx, repository.ProductFilter{Attributes: map[string][]string{"manufacturer": {"Bayer", "Reckitt"}}})
+	if len(list) != 2 {
+		t.Fatalf("any-of attribute filter: %d", len(list))
+	}
+}
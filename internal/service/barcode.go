@@ -0,0 +1,63 @@
+package service
+
+import (
+	"strings"
+)
+
+// validGTIN проверяет длину (GTIN-8/12/13/14) и контрольную цифру штрихкода
+func validGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := code[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		// веса 3 и 1 чередуются справа налево, начиная с цифры перед контрольной
+		w := 1
+		if (len(code)-2-i)%2 == 0 {
+			w = 3
+		}
+		sum += int(d-'0') * w
+	}
+	check := code[len(code)-1]
+	if check < '0' || check > '9' {
+		return false
+	}
+	return int(check-'0') == (10-sum%10)%10
+}
+
+// normalizeGTIN проверяет штрихкод и приводит его к GTIN-14, дополняя нулями слева:
+// UPC-A 012345678905 и EAN-13 0012345678905 — один и тот же код
+func normalizeGTIN(raw string) (string, bool) {
+	code := strings.TrimSpace(raw)
+	if !validGTIN(code) {
+		return "", false
+	}
+	return strings.Repeat("0", 14-len(code)) + code, true
+}
+
+// normalizeBarcodes приводит коды к GTIN-14, убирает дубли, проверяет контрольные цифры
+func normalizeBarcodes(codes []string) ([]string, error) {
+	if len(codes) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, raw := range codes {
+		code, ok := normalizeGTIN(raw)
+		if !ok {
+			return nil, invalidField("barcodes", "%q is not a valid GTIN", raw)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		out = append(out, code)
+	}
+	return out, nil
+}
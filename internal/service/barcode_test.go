@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func TestValidGTIN(t *testing.T) {
+	valid := []string{"96385074", "036000291452", "4006381333931", "10012345678902"}
+	for _, code := range valid {
+		if !validGTIN(code) {
+			t.Fatalf("%s should be valid", code)
+		}
+	}
+	invalid := []string{"", "4006381333932", "400638133393", "40063813339311", "400638133393a", "123"}
+	for _, code := range invalid {
+		if validGTIN(code) {
+			t.Fatalf("%s should be invalid", code)
+		}
+	}
+}
+
+func TestBarcodes_ProductAndOrderLines(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+
+	if _, err := ps.Create(ctx, domain.Product{Name: "A", SKU: "A1", Barcodes: []string{"4006381333932"}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid checksum, got %v", err)
+	}
+	p, err := ps.Create(ctx, domain.Product{Name: "A", SKU: "A1", Price: 1, Stock: 10,
+		Barcodes: []string{" 4006381333931", "4006381333931", "96385074"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(p.Barcodes) != 2 || p.Barcodes[0] != "04006381333931" || p.Barcodes[1] != "00000096385074" {
+		t.Fatalf("barcodes not normalized: %v", p.Barcodes)
+	}
+	if _, err := ps.Create(ctx, domain.Product{Name: "B", SKU: "B1", Barcodes: []string{"96385074"}}); err != repository.ErrConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	got, err := ps.GetByBarcode(ctx, "96385074")
+	if err != nil || got.ID != p.ID {
+		t.Fatalf("get by barcode: %v", err)
+	}
+	if _, err := ps.GetByBarcode(ctx, "12345"); err != ErrInvalidInput {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+
+	o, err := os.CreateOrder(ctx, "X", []domain.OrderItem{{Barcode: "4006381333931", Quantity: 2}, {ProductID: p.ID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if o.Items[0].ProductID != p.ID {
+		t.Fatalf("barcode not resolved: %+v", o.Items[0])
+	}
+	got, _ = ps.GetByID(ctx, p.ID)
+	if got.Stock != 7 {
+		t.Fatalf("expected stock 7, got %d", got.Stock)
+	}
+	if _, err := os.CreateOrder(ctx, "X", []domain.OrderItem{{Barcode: "036000291452", Quantity: 1}}); err != repository.ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if _, err := os.CreateOrder(ctx, "X", []domain.OrderItem{{ProductID: 99, Barcode: "96385074", Quantity: 1}}); err != ErrInvalidInput {
+		t.Fatalf("expected mismatch to be invalid, got %v", err)
+	}
+}
+
+func TestBarcodes_SameGTINInDifferentLengths(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+	// UPC-A и EAN-13 с ведущим нулём — один GTIN
+	p, err := ps.Create(ctx, domain.Product{Name: "A", SKU: "A1", Price: 1, Stock: 10, Barcodes: []string{"036000291452", "0036000291452"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if len(p.Barcodes) != 1 || p.Barcodes[0] != "00036000291452" {
+		t.Fatalf("expected one GTIN-14 code, got %v", p.Barcodes)
+	}
+	if _, err := ps.Create(ctx, domain.Product{Name: "B", SKU: "B1", Barcodes: []string{"0036000291452"}}); err != repository.ErrConflict {
+		t.Fatalf("expected conflict for the same GTIN, got %v", err)
+	}
+	for _, code := range []string{"036000291452", "0036000291452", "00036000291452"} {
+		if got, err := ps.GetByBarcode(ctx, code); err != nil || got.ID != p.ID {
+			t.Fatalf("get by %s: %+v %v", code, got, err)
+		}
+	}
+	o, err := os.CreateOrder(ctx, "X", []domain.OrderItem{{Barcode: "0036000291452", Quantity: 1}})
+	if err != nil || o.Items[0].ProductID != p.ID || o.Items[0].Barcode != "00036000291452" {
+		t.Fatalf("order by EAN-13: %+v %v", o, err)
+	}
+	if _, err := os.CreateOrder(ctx, "X", []domain.OrderItem{{Barcode: "0036000291453", Quantity: 1}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid barcode rejected, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// CartService ведёт корзины покупателей и оформляет их в заказы. Цены, скидки и
+// наличие не фиксируются в корзине, а рассчитываются при каждом чтении.
+type CartService struct {
+	carts    repository.CartRepository
+	products repository.ProductRepository
+	orders   *OrderService
+	tx       repository.TxManager
+}
+
+func NewCartService(carts repository.CartRepository, products repository.ProductRepository, orders *OrderService, tx repository.TxManager) *CartService {
+	return &CartService{carts: carts, products: products, orders: orders, tx: tx}
+}
+
+func (s *CartService) Create(ctx context.Context, customer string) (*domain.CartView, error) {
+	if customer == "" {
+		return nil, invalidField("customer_name", "is required")
+	}
+	c := domain.Cart{CustomerName: customer, Items: []domain.CartItem{}, Status: domain.CartStatusOpen}
+	if err := s.carts.Create(ctx, &c); err != nil {
+		return nil, err
+	}
+	return s.view(ctx, &c)
+}
+
+// Get возвращает корзину с текущими итогами и предупреждениями о наличии
+func (s *CartService) Get(ctx context.Context, id int64) (*domain.CartView, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	c, err := s.carts.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(ctx, c)
+}
+
+// AddItem добавляет qty единиц товара; повторное добавление увеличивает количество в строке
+func (s *CartService) AddItem(ctx context.Context, id, productID, qty int64) (*domain.CartView, error) {
+	if qty <= 0 {
+		return nil, invalidField("quantity", "must be positive")
+	}
+	return s.change(ctx, id, func(ctx context.Context, c *domain.Cart) error {
+		if err := s.checkProduct(ctx, productID); err != nil {
+			return err
+		}
+		for i := range c.Items {
+			if c.Items[i].ProductID == productID {
+				c.Items[i].Quantity += qty
+				return nil
+			}
+		}
+		c.Items = append(c.Items, domain.CartItem{ProductID: productID, Quantity: qty})
+		return nil
+	})
+}
+
+// SetItem задаёт количество товара в корзине, добавляя строку при необходимости
+func (s *CartService) SetItem(ctx context.Context, id, productID, qty int64) (*domain.CartView, error) {
+	if qty <= 0 {
+		return nil, invalidField("quantity", "must be positive")
+	}
+	return s.change(ctx, id, func(ctx context.Context, c *domain.Cart) error {
+		for i := range c.Items {
+			if c.Items[i].ProductID == productID {
+				c.Items[i].Quantity = qty
+				return nil
+			}
+		}
+		if err := s.checkProduct(ctx, productID); err != nil {
+			return err
+		}
+		c.Items = append(c.Items, domain.CartItem{ProductID: productID, Quantity: qty})
+		return nil
+	})
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, id, productID int64) (*domain.CartView, error) {
+	return s.change(ctx, id, func(ctx context.Context, c *domain.Cart) error {
+		for i := range c.Items {
+			if c.Items[i].ProductID == productID {
+				c.Items = append(c.Items[:i], c.Items[i+1:]...)
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+}
+
+// AddPromoCode применяет промокод к корзине; неизвестный или неактивный код не сохраняется
+func (s *CartService) AddPromoCode(ctx context.Context, id int64, code string) (*domain.CartView, error) {
+	code = normalizePromoCode(code)
+	if code == "" {
+		return nil, invalidField("code", "is required")
+	}
+	return s.change(ctx, id, func(ctx context.Context, c *domain.Cart) error {
+		c.PromoCodes = normalizePromoCodes(append(c.PromoCodes, code))
+		return nil
+	})
+}
+
+func (s *CartService) RemovePromoCode(ctx context.Context, id int64, code string) (*domain.CartView, error) {
+	code = normalizePromoCode(code)
+	return s.change(ctx, id, func(ctx context.Context, c *domain.Cart) error {
+		for i, cc := range c.PromoCodes {
+			if cc == code {
+				c.PromoCodes = append(c.PromoCodes[:i], c.PromoCodes[i+1:]...)
+				return nil
+			}
+		}
+		return repository.ErrNotFound
+	})
+}
+
+// Checkout оформляет корзину в заказ через CreateOrder в одной транзакции с
+// закрытием корзины. События заказа публикуются после её фиксации.
+func (s *CartService) Checkout(ctx context.Context, id int64, opts ...CreateOrderOption) (*domain.Order, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	txCtx, flush := events.Defer(ctx)
+	var created *domain.Order
+	err := s.tx.WithTransaction(txCtx, func(ctx context.Context) error {
+		c, err := s.carts.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.Status != domain.CartStatusOpen {
+			return ErrInvalidState
+		}
+		if len(c.Items) == 0 {
+			return invalidField("items", "cart is empty")
+		}
+		items := make([]domain.OrderItem, len(c.Items))
+		for i, it := range c.Items {
+			items[i] = domain.OrderItem{ProductID: it.ProductID, Quantity: it.Quantity}
+		}
+		opts = append([]CreateOrderOption{WithPromoCodes(c.PromoCodes...)}, opts...)
+		o, err := s.orders.CreateOrder(ctx, c.CustomerName, items, opts...)
+		if err != nil {
+			return err
+		}
+		c.Status, c.OrderID = domain.CartStatusCheckedOut, o.ID
+		if err := s.carts.Update(ctx, c); err != nil {
+			return err
+		}
+		created = o
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	flush()
+	return created, nil
+}
+
+// change меняет открытую корзину и сохраняет её, если итоги по-прежнему считаются
+func (s *CartService) change(ctx context.Context, id int64, fn func(ctx context.Context, c *domain.Cart) error) (*domain.CartView, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var out *domain.CartView
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		c, err := s.carts.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.Status != domain.CartStatusOpen {
+			return ErrInvalidState
+		}
+		if err := fn(ctx, c); err != nil {
+			return err
+		}
+		if out, err = s.view(ctx, c); err != nil {
+			return err
+		}
+		if err := s.carts.Update(ctx, c); err != nil {
+			return err
+		}
+		out.Cart = *c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// checkProduct проверяет, что товар можно положить в корзину; наличие не проверяется
+func (s *CartService) checkProduct(ctx context.Context, productID int64) error {
+	if productID <= 0 {
+		return invalidField("product_id", "is required")
+	}
+	p, err := s.products.GetByID(ctx, productID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return invalidField("product_id", "product %d not found", productID)
+	}
+	if err != nil {
+		return err
+	}
+	if p.ArchivedAt != nil {
+		return invalidField("product_id", "product %d is archived", productID)
+	}
+	if p.Serialized {
+		return invalidField("product_id", "product %d is sold by serial codes and cannot be added to a cart", productID)
+	}
+	return nil
+}
+
+func (s *CartService) view(ctx context.Context, c *domain.Cart) (*domain.CartView, error) {
+	items := make([]domain.OrderItem, len(c.Items))
+	for i, it := range c.Items {
+		items[i] = domain.OrderItem{ProductID: it.ProductID, Quantity: it.Quantity}
+	}
+	q, warnings, err := s.orders.Quote(ctx, c.CustomerName, items, c.PromoCodes)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.CartView{
+		Cart: *c, Lines: q.Items,
+		Subtotal: q.Subtotal, Discount: q.Discount, Total: q.Total, Tax: q.Tax,
+		Warnings: warnings,
+	}, nil
+}
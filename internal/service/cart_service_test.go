@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+func TestCart_LinesTotalsAndCheckout(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	orders := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	orders.SetEvents(bus)
+	promos := NewPromotionService(repository.NewMemoryPromotions(store), store)
+	orders.SetDiscounts(promos)
+	carts := NewCartService(repository.NewMemoryCarts(store), store, orders, tx)
+
+	products := NewProductService(store, tx)
+	a, _ := products.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 10, Stock: 5})
+	b, _ := products.Create(ctx, domain.Product{Name: "B", SKU: "B", Price: 20, Stock: 1})
+	if _, err := promos.Create(ctx, domain.Promotion{Name: "Ten", Kind: domain.PromotionKindPercent, Percent: 10, Code: "TEN"}); err != nil {
+		t.Fatalf("promotion: %v", err)
+	}
+
+	cart, err := carts.Create(ctx, "bob")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := cart.ID
+	_, _ = carts.AddItem(ctx, id, a.ID, 2)
+	_, _ = carts.AddItem(ctx, id, a.ID, 1)
+	view, err := carts.AddItem(ctx, id, b.ID, 2)
+	if err != nil || len(view.Items) != 2 || view.Items[0].Quantity != 3 || view.Total != 70 {
+		t.Fatalf("add: %+v %v", view, err)
+	}
+	if len(view.Warnings) != 1 || view.Warnings[0].ProductID != b.ID || view.Warnings[0].Available != 1 {
+		t.Fatalf("expected stock warning for B, got %+v", view.Warnings)
+	}
+	if _, err := carts.AddItem(ctx, id, 99, 1); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected unknown product rejected, got %v", err)
+	}
+	if _, err := carts.AddPromoCode(ctx, id, "nope"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected unknown code rejected, got %v", err)
+	}
+	view, err = carts.AddPromoCode(ctx, id, "ten")
+	if err != nil || view.Discount != 7 || view.Total != 63 {
+		t.Fatalf("promo: %+v %v", view, err)
+	}
+	view, _ = carts.Get(ctx, id)
+	if len(view.PromoCodes) != 1 || view.PromoCodes[0] != "TEN" {
+		t.Fatalf("expected code kept, got %+v", view.PromoCodes)
+	}
+
+	if _, err := carts.Checkout(ctx, id); !errors.Is(err, ErrNotEnoughStock) {
+		t.Fatalf("expected checkout to fail on stock, got %v", err)
+	}
+	if _, err := carts.SetItem(ctx, id, b.ID, 1); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	// подписчик видит событие только после фиксации: корзина уже закрыта
+	var status domain.CartStatus
+	events.On(bus, func(ctx context.Context, e events.OrderCreated) {
+		c, _ := carts.Get(ctx, id)
+		status = c.Status
+	})
+	o, err := carts.Checkout(ctx, id)
+	if err != nil || o.Total != 45 || len(o.PromoCodes) != 1 {
+		t.Fatalf("checkout: %+v %v", o, err)
+	}
+	if status != domain.CartStatusCheckedOut {
+		t.Fatalf("expected event after commit, cart status %q", status)
+	}
+	view, _ = carts.Get(ctx, id)
+	if view.OrderID != o.ID {
+		t.Fatalf("expected cart linked to order, got %+v", view.Cart)
+	}
+	if _, err := carts.RemoveItem(ctx, id, a.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected closed cart, got %v", err)
+	}
+	if _, err := carts.Checkout(ctx, id); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected second checkout rejected, got %v", err)
+	}
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/translit"
+)
+
+// CategoryService управляет деревом категорий каталога
+type CategoryService struct {
+	categories repository.CategoryRepository
+	products   repository.ProductRepository
+	tx         repository.TxManager
+}
+
+func NewCategoryService(categories repository.CategoryRepository, products repository.ProductRepository, tx repository.TxManager) *CategoryService {
+	return &CategoryService{categories: categories, products: products, tx: tx}
+}
+
+// Create добавляет категорию в конец списка детей родителя.
+// Пустой слаг строится из названия транслитерацией.
+func (s *CategoryService) Create(ctx context.Context, c domain.Category) (*domain.Category, error) {
+	if c.Name == "" || c.ParentID < 0 {
+		return nil, ErrInvalidInput
+	}
+	if c.Slug == "" {
+		c.Slug = translit.Slug(c.Name)
+	}
+	if !translit.ValidSlug(c.Slug) {
+		return nil, ErrInvalidInput
+	}
+	if err := validateSchema(c.Attributes); err != nil {
+		return nil, err
+	}
+	var created *domain.Category
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if c.ParentID != 0 {
+			if _, err := s.categories.GetByID(ctx, c.ParentID); err != nil {
+				return err
+			}
+		}
+		all, err := s.categories.List(ctx)
+		if err != nil {
+			return err
+		}
+		cp := domain.Category{
+			ParentID: c.ParentID, Name: c.Name, Slug: c.Slug,
+			Position: len(children(all, c.ParentID)), Attributes: c.Attributes,
+		}
+		if err := s.categories.Create(ctx, &cp); err != nil {
+			return err
+		}
+		created = &cp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *CategoryService) Get(ctx context.Context, id int64) (*domain.Category, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.categories.GetByID(ctx, id)
+}
+
+func (s *CategoryService) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	if slug == "" {
+		return nil, ErrInvalidInput
+	}
+	return s.categories.GetBySlug(ctx, slug)
+}
+
+// Update меняет название и слаг; положение в дереве меняется через Move
+func (s *CategoryService) Update(ctx context.Context, c domain.Category) (*domain.Category, error) {
+	if c.ID <= 0 || c.Name == "" || !translit.ValidSlug(c.Slug) {
+		return nil, ErrInvalidInput
+	}
+	var updated *domain.Category
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := s.categories.GetByID(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		cur.Name = c.Name
+		cur.Slug = c.Slug
+		if err := s.categories.Update(ctx, cur); err != nil {
+			return err
+		}
+		updated = cur
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetAttributes заменяет схему атрибутов категории. Уже сохранённые товары
+// не перепроверяются: новая схема действует при следующем сохранении товара.
+func (s *CategoryService) SetAttributes(ctx context.Context, id int64, defs []domain.AttributeDef) (*domain.Category, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if err := validateSchema(defs); err != nil {
+		return nil, err
+	}
+	var updated *domain.Category
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := s.categories.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		cur.Attributes = defs
+		if err := s.categories.Update(ctx, cur); err != nil {
+			return err
+		}
+		updated = cur
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Schema возвращает действующую схему атрибутов категории с унаследованными от предков
+func (s *CategoryService) Schema(ctx context.Context, id int64) ([]domain.AttributeDef, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.categories.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	all, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveSchema(all, []int64{id}), nil
+}
+
+// Tree возвращает дерево категорий, дети упорядочены по позиции
+func (s *CategoryService) Tree(ctx context.Context) ([]domain.CategoryNode, error) {
+	all, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(all, 0), nil
+}
+
+func buildTree(all []domain.Category, parentID int64) []domain.CategoryNode {
+	nodes := make([]domain.CategoryNode, 0)
+	for _, c := range children(all, parentID) {
+		nodes = append(nodes, domain.CategoryNode{Category: c, Children: buildTree(all, c.ID)})
+	}
+	return nodes
+}
+
+// children дети родителя в порядке позиций (List уже отсортирован)
+func children(all []domain.Category, parentID int64) []domain.Category {
+	out := make([]domain.Category, 0)
+	for _, c := range all {
+		if c.ParentID == parentID {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// SubtreeIDs возвращает id категории и всех её потомков
+func (s *CategoryService) SubtreeIDs(ctx context.Context, id int64) ([]int64, error) {
+	if _, err := s.categories.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	all, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return subtree(all, id), nil
+}
+
+func subtree(all []domain.Category, id int64) []int64 {
+	ids := []int64{id}
+	for i := 0; i < len(ids); i++ {
+		for _, c := range all {
+			if c.ParentID == ids[i] {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// ancestors строит для каждой категории список её предков от родителя к корню
+func ancestors(all []domain.Category) map[int64][]int64 {
+	parent := make(map[int64]int64, len(all))
+	for _, c := range all {
+		parent[c.ID] = c.ParentID
+	}
+	out := make(map[int64][]int64, len(all))
+	for _, c := range all {
+		var chain []int64
+		for p := c.ParentID; p != 0 && len(chain) < len(all); p = parent[p] {
+			chain = append(chain, p)
+		}
+		out[c.ID] = chain
+	}
+	return out
+}
+
+// Move переносит категорию под нового родителя (0 — в корень) на позицию position
+// среди его детей; позиции соседей перенумеровываются
+func (s *CategoryService) Move(ctx context.Context, id, parentID int64, position int) (*domain.Category, error) {
+	if id <= 0 || parentID < 0 || position < 0 || id == parentID {
+		return nil, ErrInvalidInput
+	}
+	var moved *domain.Category
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := s.categories.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if parentID != 0 {
+			if _, err := s.categories.GetByID(ctx, parentID); err != nil {
+				return err
+			}
+		}
+		all, err := s.categories.List(ctx)
+		if err != nil {
+			return err
+		}
+		// нельзя перенести категорию внутрь собственного поддерева
+		for _, sub := range subtree(all, id) {
+			if sub == parentID {
+				return ErrInvalidInput
+			}
+		}
+		oldParent := cur.ParentID
+		siblings := make([]int64, 0)
+		for _, c := range children(all, parentID) {
+			if c.ID != id {
+				siblings = append(siblings, c.ID)
+			}
+		}
+		if position > len(siblings) {
+			position = len(siblings)
+		}
+		ordered := append(append(append([]int64(nil), siblings[:position]...), id), siblings[position:]...)
+		if err := s.renumber(ctx, parentID, ordered); err != nil {
+			return err
+		}
+		if oldParent != parentID {
+			rest := make([]int64, 0)
+			for _, c := range children(all, oldParent) {
+				if c.ID != id {
+					rest = append(rest, c.ID)
+				}
+			}
+			if err := s.renumber(ctx, oldParent, rest); err != nil {
+				return err
+			}
+		}
+		moved, err = s.categories.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// Reorder задаёт порядок детей родителя; ids должны совпадать с набором его детей
+func (s *CategoryService) Reorder(ctx context.Context, parentID int64, ids []int64) ([]domain.CategoryNode, error) {
+	if parentID < 0 || len(ids) == 0 {
+		return nil, ErrInvalidInput
+	}
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		all, err := s.categories.List(ctx)
+		if err != nil {
+			return err
+		}
+		current := children(all, parentID)
+		if len(current) != len(ids) {
+			return ErrInvalidInput
+		}
+		want := make(map[int64]bool, len(ids))
+		for _, id := range ids {
+			want[id] = true
+		}
+		for _, c := range current {
+			if !want[c.ID] {
+				return ErrInvalidInput
+			}
+		}
+		return s.renumber(ctx, parentID, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Tree(ctx)
+}
+
+// renumber записывает родителя и позиции 0..n-1 в порядке ids
+func (s *CategoryService) renumber(ctx context.Context, parentID int64, ids []int64) error {
+	for pos, id := range ids {
+		c, err := s.categories.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.ParentID == parentID && c.Position == pos {
+			continue
+		}
+		c.ParentID = parentID
+		c.Position = pos
+		if err := s.categories.Update(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete удаляет пустую категорию: без подкатегорий и без товаров
+func (s *CategoryService) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrInvalidInput
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := s.categories.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		all, err := s.categories.List(ctx)
+		if err != nil {
+			return err
+		}
+		if len(children(all, id)) > 0 {
+			return ErrInvalidState
+		}
+		assigned, err := s.products.List(ctx, repository.ProductFilter{CategoryIDs: []int64{id}})
+		if err != nil {
+			return err
+		}
+		if len(assigned) > 0 {
+			return ErrInvalidState
+		}
+		if err := s.categories.Delete(ctx, id); err != nil {
+			return err
+		}
+		rest := make([]int64, 0)
+		for _, c := range children(all, cur.ParentID) {
+			if c.ID != id {
+				rest = append(rest, c.ID)
+			}
+		}
+		return s.renumber(ctx, cur.ParentID, rest)
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func setupCatalog(t *testing.T) (*ProductService, *CategoryService) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	categories := repository.NewMemoryCategories(store)
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	ps.SetCategories(categories)
+	return ps, NewCategoryService(categories, store, repository.NewMemoryTx(store))
+}
+
+func TestCategory_TreeAndDescendantFilter(t *testing.T) {
+	ctx := context.Background()
+	ps, cs := setupCatalog(t)
+	meds, err := cs.Create(ctx, domain.Category{Name: "Лекарства"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if meds.Slug != "lekarstva" {
+		t.Fatalf("unexpected slug %q", meds.Slug)
+	}
+	pain, _ := cs.Create(ctx, domain.Category{Name: "Pain relief", ParentID: meds.ID})
+	cold, _ := cs.Create(ctx, domain.Category{Name: "Cold", ParentID: meds.ID})
+	vit, _ := cs.Create(ctx, domain.Category{Name: "Vitamins"})
+	if pain.Position != 0 || cold.Position != 1 {
+		t.Fatalf("unexpected positions %d %d", pain.Position, cold.Position)
+	}
+
+	_, _ = ps.Create(ctx, domain.Product{Name: "Aspirin", SKU: "S1", Price: 1, CategoryIDs: []int64{pain.ID}})
+	_, _ = ps.Create(ctx, domain.Product{Name: "Theraflu", SKU: "S2", Price: 1, CategoryIDs: []int64{cold.ID, cold.ID}})
+	_, _ = ps.Create(ctx, domain.Product{Name: "Vitamin C", SKU: "S3", Price: 1, CategoryIDs: []int64{vit.ID}})
+
+	list, err := ps.List(ctx, repository.ProductFilter{CategoryID: meds.ID})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 products in subtree, got %d", len(list))
+	}
+	list, _ = ps.List(ctx, repository.ProductFilter{CategoryID: cold.ID})
+	if len(list) != 1 || len(list[0].CategoryIDs) != 1 {
+		t.Fatalf("expected deduplicated single product in cold, got %+v", list)
+	}
+
+	tree, _ := cs.Tree(ctx)
+	if len(tree) != 2 || tree[0].ID != meds.ID || len(tree[0].Children) != 2 || tree[0].Children[0].ID != pain.ID {
+		t.Fatalf("unexpected tree %+v", tree)
+	}
+
+	if _, err := cs.Create(ctx, domain.Category{Name: "Vitamins"}); err != repository.ErrConflict {
+		t.Fatalf("expected slug conflict, got %v", err)
+	}
+	if _, err := ps.Create(ctx, domain.Product{Name: "X", SKU: "S4", Price: 1, CategoryIDs: []int64{999}}); err != ErrInvalidInput {
+		t.Fatalf("expected invalid input for unknown category, got %v", err)
+	}
+}
+
+func TestCategory_MoveReorderDelete(t *testing.T) {
+	ctx := context.Background()
+	ps, cs := setupCatalog(t)
+	root, _ := cs.Create(ctx, domain.Category{Name: "Root"})
+	a, _ := cs.Create(ctx, domain.Category{Name: "A", ParentID: root.ID})
+	b, _ := cs.Create(ctx, domain.Category{Name: "B", ParentID: root.ID})
+	c, _ := cs.Create(ctx, domain.Category{Name: "C", ParentID: root.ID})
+
+	// цикл запрещён
+	if _, err := cs.Move(ctx, root.ID, a.ID, 0); err != ErrInvalidInput {
+		t.Fatalf("expected invalid input on cycle, got %v", err)
+	}
+	// c в начало
+	if _, err := cs.Move(ctx, c.ID, root.ID, 0); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	tree, _ := cs.Tree(ctx)
+	kids := tree[0].Children
+	if kids[0].ID != c.ID || kids[1].ID != a.ID || kids[2].ID != b.ID {
+		t.Fatalf("unexpected order after move: %+v", kids)
+	}
+	// b под a, соседи перенумерованы
+	if _, err := cs.Move(ctx, b.ID, a.ID, 5); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	tree, _ = cs.Tree(ctx)
+	if len(tree[0].Children) != 2 || tree[0].Children[1].Position != 1 || len(tree[0].Children[1].Children) != 1 {
+		t.Fatalf("unexpected tree after reparent: %+v", tree)
+	}
+
+	if _, err := cs.Reorder(ctx, root.ID, []int64{a.ID}); err != ErrInvalidInput {
+		t.Fatalf("expected invalid input for partial reorder, got %v", err)
+	}
+	tree, err := cs.Reorder(ctx, root.ID, []int64{a.ID, c.ID})
+	if err != nil || tree[0].Children[0].ID != a.ID {
+		t.Fatalf("reorder: %v %+v", err, tree)
+	}
+
+	// удалить можно только пустую категорию
+	if err := cs.Delete(ctx, a.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state deleting category with children, got %v", err)
+	}
+	_, _ = ps.Create(ctx, domain.Product{Name: "P", SKU: "S1", Price: 1, CategoryIDs: []int64{c.ID}})
+	if err := cs.Delete(ctx, c.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state deleting category with products, got %v", err)
+	}
+	if err := cs.Delete(ctx, b.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+func TestProduct_ListWithFacets(t *testing.T) {
+	ctx := context.Background()
+	ps, cs := setupCatalog(t)
+	meds, _ := cs.Create(ctx, domain.Category{Name: "Meds", Attributes: pharmacySchema()})
+	pain, _ := cs.Create(ctx, domain.Category{Name: "Pain", ParentID: meds.ID})
+	searchSvc := NewSearchService(ps.repo)
+	ps.SetSearch(searchSvc)
+
+	for _, p := range []domain.Product{
+		{Name: "Аспирин", SKU: "S1", Price: 90, Stock: 3, CategoryIDs: []int64{pain.ID}, Attributes: map[string]string{"manufacturer": "Bayer"}},
+		{Name: "Аспирин Кардио", SKU: "S2", Price: 250, CategoryIDs: []int64{meds.ID}, Attributes: map[string]string{"manufacturer": "Bayer"}},
+		{Name: "Нурофен", SKU: "S3", Price: 300, Stock: 1, CategoryIDs: []int64{pain.ID}, Attributes: map[string]string{"manufacturer": "Reckitt"}},
+	} {
+		if _, err := ps.Create(ctx, p); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if err := searchSvc.Rebuild(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+
+	list, facets, err := ps.ListWithFacets(ctx, repository.ProductFilter{NameSubstring: "aspirin"}, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 || facets.Total != 2 || facets.InStock != 1 || facets.OutOfStock != 1 {
+		t.Fatalf("facets not computed over search results: %d %+v", len(list), facets)
+	}
+	if len(facets.Price) != len(DefaultPriceEdges)+1 || facets.Price[0].Count != 1 || facets.Price[1].Count != 1 {
+		t.Fatalf("unexpected price buckets %+v", facets.Price)
+	}
+	// товар из подкатегории засчитывается и в родительской
+	if len(facets.Categories) != 2 || facets.Categories[0].CategoryID != meds.ID || facets.Categories[0].Count != 2 || facets.Categories[1].Count != 1 {
+		t.Fatalf("unexpected categories %+v", facets.Categories)
+	}
+	if len(facets.Manufacturers) != 1 || facets.Manufacturers[0].Count != 2 {
+		t.Fatalf("unexpected manufacturers %+v", facets.Manufacturers)
+	}
+
+	_, facets, _ = ps.ListWithFacets(ctx, repository.ProductFilter{CategoryID: pain.ID}, []float64{200})
+	if facets.Total != 2 || len(facets.Price) != 2 || facets.Price[0].Count != 1 || facets.Price[1].Count != 1 {
+		t.Fatalf("unexpected facets for category %+v", facets)
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+// CreditService ведёт подарочные карты и депозиты покупателей. Баланс меняется
+// только проводками, история которых не редактируется.
+type CreditService struct {
+	credits repository.CreditRepository
+	tx      repository.TxManager
+}
+
+func NewCreditService(credits repository.CreditRepository, tx repository.TxManager) *CreditService {
+	return &CreditService{credits: credits, tx: tx}
+}
+
+// IssueGiftCard выпускает подарочную карту на amount; пустой code генерируется
+func (s *CreditService) IssueGiftCard(ctx context.Context, code string, amount float64, note string) (*domain.CreditAccount, error) {
+	code = normalizeCreditCode(code)
+	if code == "" {
+		code = newCreditCode("GC")
+	}
+	if err := checkCreditAmount(amount); err != nil {
+		return nil, err
+	}
+	a := domain.CreditAccount{Kind: domain.CreditAccountGiftCard, Code: code}
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.credits.Create(ctx, &a); err != nil {
+			return err
+		}
+		_, err := s.post(ctx, &a, domain.CreditTransaction{Kind: domain.CreditTransactionIssue, Amount: amount, Note: note})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// AddStoreCredit начисляет amount на депозит покупателя, открывая его при первом начислении
+func (s *CreditService) AddStoreCredit(ctx context.Context, customer string, amount float64, note string) (*domain.CreditAccount, error) {
+	if strings.TrimSpace(customer) == "" {
+		return nil, invalidField("customer", "is required")
+	}
+	if err := checkCreditAmount(amount); err != nil {
+		return nil, err
+	}
+	var out *domain.CreditAccount
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		a, err := s.storeCredit(ctx, customer)
+		if err != nil {
+			return err
+		}
+		if _, err := s.post(ctx, a, domain.CreditTransaction{Kind: domain.CreditTransactionIssue, Amount: amount, Note: note}); err != nil {
+			return err
+		}
+		out = a
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *CreditService) Get(ctx context.Context, id int64) (*domain.CreditAccount, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.credits.GetByID(ctx, id)
+}
+
+func (s *CreditService) List(ctx context.Context, f repository.CreditAccountFilter) ([]domain.CreditAccount, error) {
+	switch f.Kind {
+	case "", domain.CreditAccountGiftCard, domain.CreditAccountStoreCredit:
+	default:
+		return nil, invalidField("kind", "unknown kind %q", f.Kind)
+	}
+	f.Code = normalizeCreditCode(f.Code)
+	f.Customer = strings.TrimSpace(f.Customer)
+	return s.credits.List(ctx, f)
+}
+
+// Transactions возвращает историю операций счёта
+func (s *CreditService) Transactions(ctx context.Context, id int64) ([]domain.CreditTransaction, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.credits.Transactions(ctx, id)
+}
+
+// accountByCode находит счёт по нормализованному коду; вызывать в транзакции
+func (s *CreditService) accountByCode(ctx context.Context, code string) (*domain.CreditAccount, error) {
+	return s.credits.GetByCode(ctx, code)
+}
+
+// accountByID находит счёт по id; вызывать в транзакции
+func (s *CreditService) accountByID(ctx context.Context, id int64) (*domain.CreditAccount, error) {
+	return s.credits.GetByID(ctx, id)
+}
+
+// storeCredit находит депозит покупателя или открывает новый; вызывать в транзакции
+func (s *CreditService) storeCredit(ctx context.Context, customer string) (*domain.CreditAccount, error) {
+	customer = strings.TrimSpace(customer)
+	list, err := s.credits.List(ctx, repository.CreditAccountFilter{Kind: domain.CreditAccountStoreCredit, Customer: customer})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) > 0 {
+		return &list[0], nil
+	}
+	a := domain.CreditAccount{Kind: domain.CreditAccountStoreCredit, Code: newCreditCode("SC"), Customer: customer}
+	if err := s.credits.Create(ctx, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// post проводит операцию по счёту a и обновляет его баланс; списание больше
+// баланса — ErrInvalidState. Вызывать в транзакции.
+func (s *CreditService) post(ctx context.Context, a *domain.CreditAccount, t domain.CreditTransaction) (*domain.CreditTransaction, error) {
+	t.AccountID = a.ID
+	t.Amount = roundMoney(t.Amount)
+	if roundMoney(a.Balance+t.Amount) < 0 {
+		return nil, ErrInvalidState
+	}
+	if err := s.credits.Post(ctx, &t); err != nil {
+		return nil, err
+	}
+	a.Balance, a.UpdatedAt = t.Balance, t.CreatedAt
+	return &t, nil
+}
+
+func checkCreditAmount(amount float64) error {
+	if amount <= 0 || roundMoney(amount) != amount {
+		return invalidField("amount", "must be positive with at most 2 decimals")
+	}
+	return nil
+}
+
+func normalizeCreditCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// newCreditCode случайный код счёта вида PREFIX-XXXXXXXXXXXX
+func newCreditCode(prefix string) string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return prefix + "-" + strings.ToUpper(hex.EncodeToString(b))
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func TestCredits_IssueAndHistory(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	s := NewCreditService(repository.NewMemoryCredits(store), repository.NewMemoryTx(store))
+
+	gc, err := s.IssueGiftCard(ctx, " gift-1 ", 50, "birthday")
+	if err != nil || gc.Code != "GIFT-1" || gc.Balance != 50 || gc.Kind != domain.CreditAccountGiftCard {
+		t.Fatalf("issue: %+v %v", gc, err)
+	}
+	if _, err := s.IssueGiftCard(ctx, "GIFT-1", 10, ""); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected duplicate code conflict, got %v", err)
+	}
+	if _, err := s.IssueGiftCard(ctx, "", 0, ""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected zero amount rejected, got %v", err)
+	}
+	auto, err := s.IssueGiftCard(ctx, "", 10, "")
+	if err != nil || auto.Code == "" {
+		t.Fatalf("expected generated code, got %+v %v", auto, err)
+	}
+
+	sc, err := s.AddStoreCredit(ctx, "bob", 5, "")
+	if err != nil {
+		t.Fatalf("store credit: %v", err)
+	}
+	again, err := s.AddStoreCredit(ctx, "bob", 2.5, "goodwill")
+	if err != nil || again.ID != sc.ID || again.Balance != 7.5 {
+		t.Fatalf("expected same account topped up, got %+v %v", again, err)
+	}
+	txs, _ := s.Transactions(ctx, sc.ID)
+	if len(txs) != 2 || txs[1].Balance != 7.5 || txs[1].Note != "goodwill" {
+		t.Fatalf("unexpected history: %+v", txs)
+	}
+
+	list, _ := s.List(ctx, repository.CreditAccountFilter{Kind: domain.CreditAccountStoreCredit})
+	if len(list) != 1 || list[0].Customer != "bob" {
+		t.Fatalf("unexpected store credit list: %+v", list)
+	}
+	list, _ = s.List(ctx, repository.CreditAccountFilter{Code: "gift-1"})
+	if len(list) != 1 || list[0].ID != gc.ID {
+		t.Fatalf("expected lookup by code, got %+v", list)
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import "fmt"
+
+// ValidationError ошибка проверки конкретного поля; errors.Is(err, ErrInvalidInput) == true
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidInput, e.Field, e.Message)
+}
+
+func (e *ValidationError) Unwrap() error { return ErrInvalidInput }
+
+func invalidField(field, format string, args ...any) error {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// loyaltyDiscountName название скидки за баллы в строках заказа
+const loyaltyDiscountName = "loyalty points"
+
+// LoyaltyService начисляет баллы за заказы, отзывает их при отмене и возврате
+// и списывает баллы в счёт скидки на заказ
+type LoyaltyService struct {
+	loyalty repository.LoyaltyRepository
+	tx      repository.TxManager
+}
+
+func NewLoyaltyService(loyalty repository.LoyaltyRepository, tx repository.TxManager) *LoyaltyService {
+	return &LoyaltyService{loyalty: loyalty, tx: tx}
+}
+
+func (s *LoyaltyService) Rules(ctx context.Context) (domain.LoyaltyRules, error) {
+	return s.loyalty.Rules(ctx)
+}
+
+// SetRules меняет правила; уже начисленные и списанные баллы не пересчитываются
+func (s *LoyaltyService) SetRules(ctx context.Context, r domain.LoyaltyRules) (*domain.LoyaltyRules, error) {
+	switch {
+	case r.EarnRate < 0:
+		return nil, invalidField("earn_rate", "must be non-negative")
+	case r.MinOrderTotal < 0:
+		return nil, invalidField("min_order_total", "must be non-negative")
+	case r.PointValue < 0:
+		return nil, invalidField("point_value", "must be non-negative")
+	case r.MaxRedeemPercent < 0 || r.MaxRedeemPercent > 100:
+		return nil, invalidField("max_redeem_percent", "must be in [0, 100]")
+	}
+	if err := s.loyalty.SetRules(ctx, r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *LoyaltyService) Balance(ctx context.Context, customer string) (*domain.LoyaltyBalance, error) {
+	customer = strings.TrimSpace(customer)
+	if customer == "" {
+		return nil, invalidField("customer", "is required")
+	}
+	b, err := s.loyalty.Balance(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoyaltyBalance{Customer: customer, Balance: b}, nil
+}
+
+// Entries возвращает журнал баллов покупателя
+func (s *LoyaltyService) Entries(ctx context.Context, customer string) ([]domain.LoyaltyEntry, error) {
+	customer = strings.TrimSpace(customer)
+	if customer == "" {
+		return nil, invalidField("customer", "is required")
+	}
+	return s.loyalty.Entries(ctx, repository.LoyaltyFilter{Customer: customer})
+}
+
+// Subscribe начисляет баллы за созданные заказы и отзывает их при отмене, частичной отмене и возврате
+// пропорционально возвращённой сумме; списанные на заказ баллы возвращаются так же.
+// После изменения заказа начисленное пересчитывается пропорционально новой сумме.
+func (s *LoyaltyService) Subscribe(bus *events.Bus) {
+	events.On(bus, func(ctx context.Context, e events.OrderCreated) {
+		if err := s.accrue(ctx, e.Order); err != nil {
+			log.Printf("loyalty for order %d: %v", e.Order.ID, err)
+		}
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderCancelled) {
+		if err := s.reverse(ctx, e.Order, 1); err != nil {
+			log.Printf("loyalty for order %d: %v", e.Order.ID, err)
+		}
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderReturned) {
+		s.reverseRefund(ctx, e.Order, e.Refund)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderLinesCancelled) {
+		s.reverseRefund(ctx, e.Order, e.Refund)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderEdited) {
+		if err := s.adjust(ctx, e.Order, e.Edit); err != nil {
+			log.Printf("loyalty for order %d: %v", e.Order.ID, err)
+		}
+	})
+}
+
+// reverseRefund отзывает баллы заказа o в доле возвращённой суммы от суммы до возврата
+func (s *LoyaltyService) reverseRefund(ctx context.Context, o domain.Order, r domain.Refund) {
+	before := o.Total + r.Amount
+	if before <= 0 {
+		return
+	}
+	if err := s.reverse(ctx, o, r.Amount/before); err != nil {
+		log.Printf("loyalty for order %d: %v", o.ID, err)
+	}
+}
+
+// PointsValue проверяет баланс покупателя и правила и возвращает скидку за points баллов
+// на заказ o. Вызывается в транзакции создания заказа.
+func (s *LoyaltyService) PointsValue(ctx context.Context, o *domain.Order, points int64) (float64, error) {
+	rules, err := s.loyalty.Rules(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if rules.PointValue <= 0 || rules.MaxRedeemPercent <= 0 {
+		return 0, invalidField("loyalty_points", "redemption is disabled")
+	}
+	balance, err := s.loyalty.Balance(ctx, strings.TrimSpace(o.CustomerName))
+	if err != nil {
+		return 0, err
+	}
+	if points > balance {
+		return 0, invalidField("loyalty_points", "not enough points: balance %d", balance)
+	}
+	value := roundMoney(float64(points) * rules.PointValue)
+	if limit := roundMoney(o.Total * rules.MaxRedeemPercent / 100); value > limit {
+		return 0, invalidField("loyalty_points", "at most %d points can be redeemed on this order", int64(limit/rules.PointValue))
+	}
+	return value, nil
+}
+
+// RedeemPoints записывает списание баллов на сохранённый заказ o; вызывается в той же транзакции
+func (s *LoyaltyService) RedeemPoints(ctx context.Context, o *domain.Order, points int64) error {
+	e := domain.LoyaltyEntry{Customer: strings.TrimSpace(o.CustomerName), Kind: domain.LoyaltyEntryRedeem, Points: -points, OrderID: o.ID}
+	return s.loyalty.Append(ctx, &e)
+}
+
+// accrue начисляет баллы за сумму заказа к оплате
+func (s *LoyaltyService) accrue(ctx context.Context, o domain.Order) error {
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		rules, err := s.loyalty.Rules(ctx)
+		if err != nil {
+			return err
+		}
+		if rules.EarnRate <= 0 || o.Total < rules.MinOrderTotal {
+			return nil
+		}
+		points := int64(math.Floor(o.Total * rules.EarnRate))
+		if points <= 0 {
+			return nil
+		}
+		e := domain.LoyaltyEntry{Customer: strings.TrimSpace(o.CustomerName), Kind: domain.LoyaltyEntryEarn, Points: points, OrderID: o.ID}
+		return s.loyalty.Append(ctx, &e)
+	})
+}
+
+// adjust приводит начисленные за заказ баллы к его сумме после изменения edit:
+// доначисляет или отзывает пропорционально изменению суммы, как при возврате, и
+// возвращает списанные баллы, скидка за которые не поместилась в заказ
+func (s *LoyaltyService) adjust(ctx context.Context, o domain.Order, edit domain.OrderEdit) error {
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		customer := strings.TrimSpace(o.CustomerName)
+		if edit.PointsRestored > 0 {
+			e := domain.LoyaltyEntry{Customer: customer, Kind: domain.LoyaltyEntryRestore, Points: edit.PointsRestored, OrderID: o.ID}
+			if err := s.loyalty.Append(ctx, &e); err != nil {
+				return err
+			}
+		}
+		if edit.TotalBefore <= 0 {
+			return nil
+		}
+		entries, err := s.loyalty.Entries(ctx, repository.LoyaltyFilter{OrderID: o.ID})
+		if err != nil {
+			return err
+		}
+		var earned int64
+		for _, e := range entries {
+			if e.Kind == domain.LoyaltyEntryEarn || e.Kind == domain.LoyaltyEntryRevoke {
+				earned += e.Points
+			}
+		}
+		diff := int64(math.Round(float64(earned)*edit.TotalAfter/edit.TotalBefore)) - earned
+		switch {
+		case diff > 0:
+			e := domain.LoyaltyEntry{Customer: customer, Kind: domain.LoyaltyEntryEarn, Points: diff, OrderID: o.ID}
+			return s.loyalty.Append(ctx, &e)
+		case diff < 0:
+			e := domain.LoyaltyEntry{Customer: customer, Kind: domain.LoyaltyEntryRevoke, Points: diff, OrderID: o.ID}
+			return s.loyalty.Append(ctx, &e)
+		}
+		return nil
+	})
+}
+
+// reverse отзывает долю share начисленных за заказ баллов и возвращает ту же долю
+// списанных на него. Отзыв может увести баланс в минус, если баллы уже потрачены.
+func (s *LoyaltyService) reverse(ctx context.Context, o domain.Order, share float64) error {
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		entries, err := s.loyalty.Entries(ctx, repository.LoyaltyFilter{OrderID: o.ID})
+		if err != nil {
+			return err
+		}
+		var earned, redeemed int64
+		for _, e := range entries {
+			switch e.Kind {
+			case domain.LoyaltyEntryEarn, domain.LoyaltyEntryRevoke:
+				earned += e.Points
+			case domain.LoyaltyEntryRedeem, domain.LoyaltyEntryRestore:
+				redeemed -= e.Points
+			}
+		}
+		customer := strings.TrimSpace(o.CustomerName)
+		if n := int64(math.Round(float64(redeemed) * share)); n > 0 {
+			e := domain.LoyaltyEntry{Customer: customer, Kind: domain.LoyaltyEntryRestore, Points: n, OrderID: o.ID}
+			if err := s.loyalty.Append(ctx, &e); err != nil {
+				return err
+			}
+		}
+		if n := int64(math.Round(float64(earned) * share)); n > 0 {
+			e := domain.LoyaltyEntry{Customer: customer, Kind: domain.LoyaltyEntryRevoke, Points: -n, OrderID: o.ID}
+			if err := s.loyalty.Append(ctx, &e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
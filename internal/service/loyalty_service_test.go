@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+func TestLoyalty_AccrueRedeemAndReverse(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	orders := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	orders.SetEvents(bus)
+	loyalty := NewLoyaltyService(repository.NewMemoryLoyalty(store), tx)
+	orders.SetLoyalty(loyalty)
+	loyalty.Subscribe(bus)
+	if _, err := loyalty.SetRules(ctx, domain.LoyaltyRules{EarnRate: 0.1, PointValue: 1, MaxRedeemPercent: 50}); err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	if _, err := loyalty.SetRules(ctx, domain.LoyaltyRules{MaxRedeemPercent: 120}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid percent rejected, got %v", err)
+	}
+	p, _ := NewProductService(store, tx).Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 25, Stock: 100})
+
+	first, err := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: p.ID, Quantity: 4}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if b, _ := loyalty.Balance(ctx, "bob"); b.Balance != 10 {
+		t.Fatalf("expected 10 points earned, got %+v", b)
+	}
+
+	if _, err := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: p.ID, Quantity: 2}}, WithLoyaltyPoints(11)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected not enough points, got %v", err)
+	}
+	if cur, _ := store.GetByID(ctx, p.ID); cur.Stock != 96 {
+		t.Fatalf("expected rejected order to keep stock, got %d", cur.Stock)
+	}
+	if _, err := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: p.ID, Quantity: 0}}, WithLoyaltyPoints(-1)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected negative points rejected, got %v", err)
+	}
+	second, err := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: p.ID, Quantity: 2}}, WithLoyaltyPoints(10))
+	if err != nil || second.Total != 40 || second.PointsRedeemed != 10 || second.Items[0].Discounts[0].Name != loyaltyDiscountName {
+		t.Fatalf("redeem: %+v %v", second, err)
+	}
+	if b, _ := loyalty.Balance(ctx, "bob"); b.Balance != 4 {
+		t.Fatalf("expected 10 - 10 + 4 points, got %+v", b)
+	}
+
+	// половина заказа возвращена: половина списанных баллов возвращается, половина начисленных отзывается
+	if _, _, err := orders.PartialReturn(ctx, second.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	if b, _ := loyalty.Balance(ctx, "bob"); b.Balance != 7 {
+		t.Fatalf("expected 4 + 5 - 2 points, got %+v", b)
+	}
+	if _, _, err := orders.CancelOrder(ctx, first.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	entries, _ := loyalty.Entries(ctx, "bob")
+	last := entries[len(entries)-1]
+	if len(entries) != 6 || last.Kind != domain.LoyaltyEntryRevoke || last.Points != -10 || last.Balance != -3 {
+		t.Fatalf("unexpected ledger: %+v", entries)
+	}
+
+	if _, err := loyalty.SetRules(ctx, domain.LoyaltyRules{EarnRate: 0.1}); err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	if _, err := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}, WithLoyaltyPoints(1)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected redemption disabled, got %v", err)
+	}
+}
+
+func TestLoyalty_AdjustOnEdit(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	orders := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	orders.SetEvents(bus)
+	loyalty := NewLoyaltyService(repository.NewMemoryLoyalty(store), tx)
+	orders.SetLoyalty(loyalty)
+	loyalty.Subscribe(bus)
+	if _, err := loyalty.SetRules(ctx, domain.LoyaltyRules{EarnRate: 0.1, PointValue: 1, MaxRedeemPercent: 50}); err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	ps := NewProductService(store, tx)
+	a, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 25, Stock: 100})
+	b, _ := ps.Create(ctx, domain.Product{Name: "B", SKU: "B", Price: 4, Stock: 100})
+	balance := func(want int64) {
+		t.Helper()
+		if got, _ := loyalty.Balance(ctx, "bob"); got.Balance != want {
+			entries, _ := loyalty.Entries(ctx, "bob")
+			t.Fatalf("expected balance %d, got %d: %+v", want, got.Balance, entries)
+		}
+	}
+
+	first, _ := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: a.ID, Quantity: 4}})
+	balance(10)
+	if _, _, err := orders.EditOrder(ctx, first.ID, []domain.OrderItem{{ProductID: a.ID, Quantity: 6}}, ""); err != nil {
+		t.Fatalf("edit up: %v", err)
+	}
+	balance(15)
+	if _, _, err := orders.EditOrder(ctx, first.ID, []domain.OrderItem{{ProductID: a.ID, Quantity: 2}}, ""); err != nil {
+		t.Fatalf("edit down: %v", err)
+	}
+	balance(5)
+
+	// скидка за 4 балла помещается в новую строку, за остальные 6 — возвращается
+	second, err := orders.CreateOrder(ctx, "bob", []domain.OrderItem{{ProductID: a.ID, Quantity: 2}}, WithLoyaltyPoints(5))
+	if err != nil || second.Total != 45 {
+		t.Fatalf("redeem: %+v %v", second, err)
+	}
+	balance(4)
+	o, edit, err := orders.EditOrder(ctx, second.ID, []domain.OrderItem{{ProductID: a.ID, Quantity: 0}, {ProductID: b.ID, Quantity: 1}}, "")
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if o.Total != 0 || edit.PointsRestored != 1 || o.PointsRedeemed != 4 {
+		t.Fatalf("unexpected edit %+v %+v", o, edit)
+	}
+	balance(1)
+	if _, _, err := orders.CancelOrder(ctx, second.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	balance(5)
+}
+
+func TestAllocateDiscount(t *testing.T) {
+	items := []domain.OrderItem{
+		{Quantity: 1, UnitPrice: 10},
+		{Quantity: 1, UnitPrice: 20, Discount: 10},
+		{Quantity: 1, UnitPrice: 10},
+	}
+	if left := allocateDiscount(items, 10, domain.LineDiscount{Name: "x"}); left != 0 {
+		t.Fatalf("unexpected rest %v", left)
+	}
+	if items[0].Discount != 3.33 || items[1].Discount != 13.33 || items[2].Discount != 3.34 {
+		t.Fatalf("unexpected allocation: %+v", items)
+	}
+	if left := allocateDiscount(items, 25, domain.LineDiscount{Name: "y"}); left != 5 {
+		t.Fatalf("expected 5 not allocated, got %v: %+v", left, items)
+	}
+}
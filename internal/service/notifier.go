@@ -0,0 +1,58 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"april/internal/domain"
+)
+
+// Notifier доставляет оповещения о низком запасе
+type Notifier interface {
+	Notify(ctx context.Context, a domain.LowStockAlert) error
+}
+
+// LogNotifier пишет оповещения в лог
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, a domain.LowStockAlert) error {
+	log.Printf("low stock: product %d %q stock=%d reorder_point=%d reorder_quantity=%d",
+		a.ProductID, a.ProductName, a.Stock, a.ReorderPoint, a.ReorderQty)
+	return nil
+}
+
+// WebhookNotifier отправляет оповещение POST-запросом с JSON-телом
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, a domain.LowStockAlert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"april/internal/domain"
+)
+
+func TestWebhookNotifier_PostsAlert(t *testing.T) {
+	var got domain.LowStockAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL)
+	if err := n.Notify(context.Background(), domain.LowStockAlert{ID: 1, ProductID: 2, Stock: 1}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if got.ProductID != 2 {
+		t.Fatalf("webhook got %+v", got)
+	}
+}
+
+func TestWebhookNotifier_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	if err := NewWebhookNotifier(srv.URL).Notify(context.Background(), domain.LowStockAlert{}); err == nil {
+		t.Fatalf("expected error on 500")
+	}
+}
@@ -3,37 +3,157 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 
 	"april/internal/domain"
+	"april/internal/events"
 	"april/internal/repository"
 )
 
 // OrderService реализует логику заказов: создание, отмена, частичный возврат
 type OrderService struct {
-	products repository.ProductRepository
-	orders   repository.OrderRepository
-	tx       repository.TxManager
+	products  repository.ProductRepository
+	orders    repository.OrderRepository
+	tx        repository.TxManager
+	events    *events.Bus
+	serials   repository.SerialRepository
+	discounts Discounter
+	refunds   repository.RefundRepository
+	loyalty   PointsRedeemer
+	edits     repository.OrderEditRepository
+}
+
+// Discounter рассчитывает скидки строк заказа по действующим акциям и промокодам
+type Discounter interface {
+	ApplyDiscounts(ctx context.Context, items []domain.OrderItem, products map[int64]domain.Product, codes []string) error
+}
+
+// PointsRedeemer списывает баллы лояльности покупателя заказа в счёт скидки
+type PointsRedeemer interface {
+	// PointsValue проверяет, что на заказ можно списать points баллов, и возвращает сумму скидки
+	PointsValue(ctx context.Context, o *domain.Order, points int64) (float64, error)
+	// RedeemPoints записывает списание на сохранённый заказ
+	RedeemPoints(ctx context.Context, o *domain.Order, points int64) error
 }
 
 func NewOrderService(products repository.ProductRepository, orders repository.OrderRepository, tx repository.TxManager) *OrderService {
 	return &OrderService{products: products, orders: orders, tx: tx}
 }
 
+// SetEvents подключает шину событий; события публикуются после фиксации транзакции
+func (s *OrderService) SetEvents(bus *events.Bus) { s.events = bus }
+
+// SetSerials подключает учёт маркированных упаковок; без него маркированный товар не продаётся
+func (s *OrderService) SetSerials(serials repository.SerialRepository) { s.serials = serials }
+
+// SetRefunds подключает хранение возвратов денег; без него возврат только рассчитывается
+func (s *OrderService) SetRefunds(refunds repository.RefundRepository) { s.refunds = refunds }
+
+// SetDiscounts подключает расчёт скидок; без него заказ оформляется по ценам каталога
+func (s *OrderService) SetDiscounts(discounts Discounter) { s.discounts = discounts }
+
+// SetEdits подключает журнал изменений заказов; без него изменение только возвращается
+func (s *OrderService) SetEdits(edits repository.OrderEditRepository) { s.edits = edits }
+
+// SetLoyalty подключает оплату заказа баллами лояльности
+func (s *OrderService) SetLoyalty(loyalty PointsRedeemer) { s.loyalty = loyalty }
+
 var (
 	ErrNotEnoughStock = errors.New("not enough stock")
 	ErrInvalidState   = errors.New("invalid state")
 )
 
-// CreateOrder проверяет наличие товара и атомарно списывает запас
-func (s *OrderService) CreateOrder(ctx context.Context, customer string, items []domain.OrderItem) (*domain.Order, error) {
+// StockError подробности нехватки запаса с предложенными аналогами;
+// errors.Is(err, ErrNotEnoughStock) == true
+type StockError struct {
+	ProductID   int64
+	Requested   int64
+	Available   int64
+	Substitutes []domain.Product
+}
+
+func (e *StockError) Error() string {
+	return fmt.Sprintf("%s: product %d requested %d available %d", ErrNotEnoughStock, e.ProductID, e.Requested, e.Available)
+}
+
+func (e *StockError) Unwrap() error { return ErrNotEnoughStock }
+
+// CreateOrderOption настраивает CreateOrder
+type CreateOrderOption func(*createOrderOptions)
+
+type createOrderOptions struct {
+	suggestSubstitutes bool
+	promoCodes         []string
+	points             int64
+}
+
+// WithSubstituteSuggestions при нехватке запаса возвращает *StockError с аналогами,
+// которых хватает на запрошенное количество
+func WithSubstituteSuggestions() CreateOrderOption {
+	return func(o *createOrderOptions) { o.suggestSubstitutes = true }
+}
+
+// WithPromoCodes применяет к заказу акции по промокодам
+func WithPromoCodes(codes ...string) CreateOrderOption {
+	return func(o *createOrderOptions) { o.promoCodes = append(o.promoCodes, codes...) }
+}
+
+// WithLoyaltyPoints списывает баллы лояльности покупателя в счёт скидки на заказ
+func WithLoyaltyPoints(points int64) CreateOrderOption {
+	return func(o *createOrderOptions) { o.points = points }
+}
+
+// CreateOrder проверяет наличие товара, фиксирует цены и скидки строк и атомарно списывает запас
+func (s *OrderService) CreateOrder(ctx context.Context, customer string, items []domain.OrderItem, opts ...CreateOrderOption) (*domain.Order, error) {
 	if customer == "" || len(items) == 0 {
 		return nil, ErrInvalidInput
 	}
-	// validate items
-	for _, it := range items {
-		if it.ProductID <= 0 || it.Quantity <= 0 {
+	var options createOrderOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	codes := normalizePromoCodes(options.promoCodes)
+	if len(codes) > 0 && s.discounts == nil {
+		return nil, invalidField("promo_codes", "promotions are not configured")
+	}
+	if options.points < 0 {
+		return nil, invalidField("loyalty_points", "must be non-negative")
+	}
+	if options.points > 0 && s.loyalty == nil {
+		return nil, invalidField("loyalty_points", "loyalty program is not configured")
+	}
+	// validate items; a line names the product by id or by barcode
+	items = append([]domain.OrderItem(nil), items...)
+	seenSerials := make(map[string]bool)
+	for i, it := range items {
+		items[i].Barcode = strings.TrimSpace(it.Barcode)
+		if it.ProductID < 0 || (it.ProductID == 0 && items[i].Barcode == "") || it.Quantity <= 0 {
 			return nil, ErrInvalidInput
 		}
+		if items[i].Barcode != "" {
+			code, ok := normalizeGTIN(items[i].Barcode)
+			if !ok {
+				return nil, invalidField("items.barcode", "%q is not a valid GTIN", it.Barcode)
+			}
+			items[i].Barcode = code
+		}
+		if len(it.Serials) == 0 {
+			continue
+		}
+		codes, err := normalizeSerials("items.serials", it.Serials)
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range codes {
+			if seenSerials[code] {
+				return nil, invalidField("items.serials", "duplicate code %q", code)
+			}
+			seenSerials[code] = true
+		}
+		items[i].Serials = codes
 	}
 
 	var created *domain.Order
@@ -41,43 +161,150 @@ func (s *OrderService) CreateOrder(ctx context.Context, customer string, items [
 		// load and check stock
 		// accumulate updates to avoid partial state
 		productCopies := make(map[int64]*domain.Product)
-		for _, it := range items {
-			p, err := s.products.GetByID(ctx, it.ProductID)
-			if err != nil {
-				return err
+		var sold []domain.SerialUnit
+		for i, it := range items {
+			if it.Barcode != "" {
+				byCode, err := s.products.GetByBarcode(ctx, it.Barcode)
+				if err != nil {
+					return err
+				}
+				if it.ProductID != 0 && it.ProductID != byCode.ID {
+					return ErrInvalidInput
+				}
+				it.ProductID = byCode.ID
+				items[i].ProductID = byCode.ID
+			}
+			// repeated lines of the same product reserve from the same copy
+			p, ok := productCopies[it.ProductID]
+			if !ok {
+				var err error
+				if p, err = s.products.GetByID(ctx, it.ProductID); err != nil {
+					return err
+				}
+				if p.ArchivedAt != nil {
+					return fmt.Errorf("%w: product %d is archived", ErrInvalidState, p.ID)
+				}
 			}
 			if p.Stock < it.Quantity {
-				return ErrNotEnoughStock
+				if !options.suggestSubstitutes {
+					return ErrNotEnoughStock
+				}
+				subs, err := findSubstitutes(ctx, s.products, *p, it.Quantity)
+				if err != nil {
+					return err
+				}
+				return &StockError{ProductID: p.ID, Requested: it.Quantity, Available: p.Stock, Substitutes: subs}
 			}
+			units, err := s.checkSerials(ctx, *p, it)
+			if err != nil {
+				return err
+			}
+			sold = append(sold, units...)
 			// reserve
 			p.Stock -= it.Quantity
 			productCopies[p.ID] = p
+			items[i].UnitPrice = p.Price
+			items[i].TaxRate = p.TaxRate
+			items[i].Discount, items[i].Discounts = 0, nil
 		}
-		// persist product stock updates
-		for _, p := range productCopies {
-			if err := s.products.Update(ctx, p); err != nil {
+		if s.discounts != nil {
+			products := make(map[int64]domain.Product, len(productCopies))
+			for id, p := range productCopies {
+				products[id] = *p
+			}
+			if err := s.discounts.ApplyDiscounts(ctx, items, products, codes); err != nil {
 				return err
 			}
 		}
-
-		// create order
 		o := domain.Order{
 			CustomerName: customer,
 			Items:        items,
 			Status:       domain.OrderStatusConfirmed,
+			PromoCodes:   codes,
+		}
+		recalcTotals(&o)
+		if options.points > 0 {
+			value, err := s.loyalty.PointsValue(ctx, &o, options.points)
+			if err != nil {
+				return err
+			}
+			allocateDiscount(o.Items, value, domain.LineDiscount{Name: loyaltyDiscountName})
+			o.PointsRedeemed = options.points
+			recalcTotals(&o)
+		}
+		// persist product stock updates
+		for _, p := range productCopies {
+			if err := s.products.Update(ctx, p); err != nil {
+				return err
+			}
 		}
+
+		// create order
 		if err := s.orders.Create(ctx, &o); err != nil {
 			return err
 		}
+		// списание баллов ссылается на сохранённый заказ
+		if options.points > 0 {
+			if err := s.loyalty.RedeemPoints(ctx, &o, options.points); err != nil {
+				return err
+			}
+		}
+		for _, u := range sold {
+			u.Status = domain.SerialStatusSold
+			u.OrderID = o.ID
+			if err := s.serials.Update(ctx, &u); err != nil {
+				return err
+			}
+		}
 		created = &o
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, events.OrderCreated{Order: orderSnapshot(created)})
 	return created, nil
 }
 
+// Quote рассчитывает цены, скидки и итоги заказа из items по текущему каталогу,
+// ничего не резервируя. Строки, которые сейчас нельзя заказать, попадают в
+// предупреждения; строки удалённых и архивных товаров в расчёт не входят.
+func (s *OrderService) Quote(ctx context.Context, customer string, items []domain.OrderItem, codes []string) (*domain.Order, []domain.StockWarning, error) {
+	codes = normalizePromoCodes(codes)
+	if len(codes) > 0 && s.discounts == nil {
+		return nil, nil, invalidField("promo_codes", "promotions are not configured")
+	}
+	o := domain.Order{CustomerName: customer, PromoCodes: codes, Items: make([]domain.OrderItem, 0, len(items))}
+	warnings := make([]domain.StockWarning, 0)
+	products := make(map[int64]domain.Product, len(items))
+	for _, it := range items {
+		p, err := s.products.GetByID(ctx, it.ProductID)
+		if errors.Is(err, repository.ErrNotFound) {
+			warnings = append(warnings, domain.StockWarning{ProductID: it.ProductID, Requested: it.Quantity, Message: "product not found"})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if p.ArchivedAt != nil {
+			warnings = append(warnings, domain.StockWarning{ProductID: p.ID, Requested: it.Quantity, Available: p.Stock, Message: "product is archived"})
+			continue
+		}
+		if p.Stock < it.Quantity {
+			warnings = append(warnings, domain.StockWarning{ProductID: p.ID, Requested: it.Quantity, Available: p.Stock, Message: "not enough stock"})
+		}
+		products[p.ID] = *p
+		o.Items = append(o.Items, domain.OrderItem{ProductID: p.ID, Quantity: it.Quantity, UnitPrice: p.Price, TaxRate: p.TaxRate})
+	}
+	if s.discounts != nil {
+		if err := s.discounts.ApplyDiscounts(ctx, o.Items, products, codes); err != nil {
+			return nil, nil, err
+		}
+	}
+	recalcTotals(&o)
+	return &o, warnings, nil
+}
+
 // GetOrder возвращает заказ по id
 func (s *OrderService) GetOrder(ctx context.Context, id int64) (*domain.Order, error) {
 	if id <= 0 {
@@ -86,12 +313,14 @@ func (s *OrderService) GetOrder(ctx context.Context, id int64) (*domain.Order, e
 	return s.orders.GetByID(ctx, id)
 }
 
-// CancelOrder если Confirmed — возвращаем товары на склад и ставим Cancelled
-func (s *OrderService) CancelOrder(ctx context.Context, id int64) (*domain.Order, error) {
+// CancelOrder если Confirmed — возвращаем товары на склад, ставим Cancelled
+// и рассчитываем возврат всей суммы заказа
+func (s *OrderService) CancelOrder(ctx context.Context, id int64) (*domain.Order, *domain.Refund, error) {
 	if id <= 0 {
-		return nil, ErrInvalidInput
+		return nil, nil, ErrInvalidInput
 	}
 	var updated *domain.Order
+	var refund domain.Refund
 	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
 		o, err := s.orders.GetByID(ctx, id)
 		if err != nil {
@@ -110,29 +339,273 @@ func (s *OrderService) CancelOrder(ctx context.Context, id int64) (*domain.Order
 			if err := s.products.Update(ctx, p); err != nil {
 				return err
 			}
+			if err := s.restockSerials(ctx, it.Serials); err != nil {
+				return err
+			}
+		}
+		refund = domain.Refund{OrderID: o.ID, Kind: domain.RefundKindCancel, Method: domain.RefundMethodOriginal}
+		for _, it := range o.Items {
+			refund.Lines = append(refund.Lines, domain.RefundLine{
+				ProductID: it.ProductID, Quantity: it.Quantity, UnitPrice: it.UnitPrice,
+				Amount: lineAmount(it), Discount: it.Discount, Tax: it.Tax,
+			})
 		}
 		o.Status = domain.OrderStatusCancelled
 		if err := s.orders.Update(ctx, o); err != nil {
 			return err
 		}
 		updated = o
-		return nil
+		return s.createRefund(ctx, &refund)
 	})
 	if err != nil {
+		return nil, nil, err
+	}
+	s.events.Publish(ctx, events.OrderCancelled{Order: orderSnapshot(updated), Refund: refund})
+	return updated, s.reloadRefund(ctx, refund), nil
+}
+
+// EditOrder задаёт новые количества товаров в заказе до его выполнения: товар с
+// количеством 0 удаляется, товар не из заказа добавляется по текущей цене с
+// действующими акциями. Скидки акций в изменённых строках меняются пропорционально
+// количеству, скидка за баллы сохраняет сумму, пока помещается в заказ. Запас резервируется или
+// возвращается на разницу. Если сумма заказа уменьшилась, разница записывается
+// возвратом вида edit. Маркированный товар так не меняется: его упаковки
+// возвращаются через PartialReturn.
+func (s *OrderService) EditOrder(ctx context.Context, id int64, lines []domain.OrderItem, note string) (*domain.Order, *domain.OrderEdit, error) {
+	if id <= 0 || len(lines) == 0 {
+		return nil, nil, ErrInvalidInput
+	}
+	target := make(map[int64]int64, len(lines))
+	for _, l := range lines {
+		if l.ProductID <= 0 || l.Quantity < 0 {
+			return nil, nil, ErrInvalidInput
+		}
+		if _, dup := target[l.ProductID]; dup {
+			return nil, nil, invalidField("items", "duplicate product %d", l.ProductID)
+		}
+		if len(l.Serials) > 0 {
+			return nil, nil, invalidField("items.serials", "serials cannot be edited")
+		}
+		target[l.ProductID] = l.Quantity
+	}
+
+	var updated *domain.Order
+	var edit domain.OrderEdit
+	var refund domain.Refund
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		o, err := s.orders.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if o.Status != domain.OrderStatusConfirmed && o.Status != domain.OrderStatusPending {
+			return ErrInvalidState
+		}
+		current := make(map[int64]int64)
+		for _, it := range o.Items {
+			current[it.ProductID] += it.Quantity
+		}
+		edit = domain.OrderEdit{OrderID: o.ID, TotalBefore: o.Total, Note: strings.TrimSpace(note)}
+		taxBefore := o.Tax
+		points := takeDiscount(o.Items, loyaltyDiscountName)
+		productCopies := make(map[int64]*domain.Product)
+		var added []domain.OrderItem
+		for _, l := range lines {
+			before, after := current[l.ProductID], l.Quantity
+			if before == after {
+				continue
+			}
+			p, err := s.products.GetByID(ctx, l.ProductID)
+			if errors.Is(err, repository.ErrNotFound) && before == 0 {
+				return invalidField("items", "product %d not found", l.ProductID)
+			}
+			if err != nil {
+				return err
+			}
+			if p.Serialized {
+				return invalidField("items", "product %d is serialized: return its units instead", p.ID)
+			}
+			delta := after - before
+			if delta > 0 && p.ArchivedAt != nil {
+				return fmt.Errorf("%w: product %d is archived", ErrInvalidState, p.ID)
+			}
+			if p.Stock < delta {
+				return &StockError{ProductID: p.ID, Requested: delta, Available: p.Stock}
+			}
+			p.Stock -= delta
+			productCopies[p.ID] = p
+			edit.Changes = append(edit.Changes, domain.OrderLineChange{ProductID: p.ID, Before: before, After: after})
+			if before == 0 {
+				added = append(added, domain.OrderItem{ProductID: p.ID, Quantity: after, UnitPrice: p.Price, TaxRate: p.TaxRate})
+				continue
+			}
+			resizeProduct(o, p.ID, before, after)
+		}
+		if len(edit.Changes) == 0 {
+			return invalidField("items", "nothing to change")
+		}
+		if len(added) > 0 && s.discounts != nil {
+			products := make(map[int64]domain.Product, len(added))
+			for _, it := range added {
+				products[it.ProductID] = *productCopies[it.ProductID]
+			}
+			if err := s.discounts.ApplyDiscounts(ctx, added, products, o.PromoCodes); err != nil {
+				return err
+			}
+		}
+		o.Items = append(o.Items, added...)
+		if len(o.Items) == 0 {
+			return invalidField("items", "order cannot be left empty: cancel it instead")
+		}
+		if left := allocateDiscount(o.Items, points, domain.LineDiscount{Name: loyaltyDiscountName}); left > 0 && o.PointsRedeemed > 0 {
+			n := min(o.PointsRedeemed, int64(math.Round(float64(o.PointsRedeemed)*left/points)))
+			o.PointsRedeemed -= n
+			edit.PointsRestored = n
+		}
+		recalcTotals(o)
+		edit.TotalAfter = o.Total
+
+		for _, p := range productCopies {
+			if err := s.products.Update(ctx, p); err != nil {
+				return err
+			}
+		}
+		if err := s.orders.Update(ctx, o); err != nil {
+			return err
+		}
+		updated = o
+		if o.Total < edit.TotalBefore {
+			refund = domain.Refund{
+				OrderID: o.ID, Kind: domain.RefundKindEdit, Method: domain.RefundMethodOriginal,
+				Amount: roundMoney(edit.TotalBefore - o.Total), Tax: roundMoney(taxBefore - o.Tax),
+				Status: domain.RefundStatusPending,
+			}
+			if s.refunds != nil {
+				if err := s.refunds.Create(ctx, &refund); err != nil {
+					return err
+				}
+			}
+			edit.RefundID = refund.ID
+		}
+		if s.edits == nil {
+			return nil
+		}
+		return s.edits.Create(ctx, &edit)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	s.events.Publish(ctx, events.OrderEdited{Order: orderSnapshot(updated), Edit: edit, Refund: refund})
+	return updated, &edit, nil
+}
+
+// resizeProduct меняет количество товара в заказе с before на after: лишнее
+// снимается с последних строк товара, недостающее добавляется в последнюю
+func resizeProduct(o *domain.Order, productID, before, after int64) {
+	if after > before {
+		for i := len(o.Items) - 1; i >= 0; i-- {
+			if it := &o.Items[i]; it.ProductID == productID {
+				scaleLine(it, it.Quantity+after-before)
+				return
+			}
+		}
+	}
+	remove := before - after
+	for i := len(o.Items) - 1; i >= 0 && remove > 0; i-- {
+		it := &o.Items[i]
+		if it.ProductID != productID {
+			continue
+		}
+		n := min(remove, it.Quantity)
+		remove -= n
+		if n == it.Quantity {
+			o.Items = append(o.Items[:i], o.Items[i+1:]...)
+			continue
+		}
+		reduceLine(it, n)
+	}
+}
+
+// Edits возвращает журнал изменений заказа
+func (s *OrderService) Edits(ctx context.Context, orderID int64) ([]domain.OrderEdit, error) {
+	if orderID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
 		return nil, err
 	}
-	return updated, nil
+	if s.edits == nil {
+		return []domain.OrderEdit{}, nil
+	}
+	return s.edits.ListByOrder(ctx, orderID)
+}
+
+// ReturnOption настраивает PartialReturn
+type ReturnOption func(*returnOptions)
+
+type returnOptions struct {
+	method domain.RefundMethod
+}
+
+// RefundToStoreCredit возвращает деньги на депозит покупателя вместо исходной оплаты
+func RefundToStoreCredit() ReturnOption {
+	return func(o *returnOptions) { o.method = domain.RefundMethodStoreCredit }
+}
+
+// PartialReturn уменьшает количество в заказе, возвращает часть на склад и
+// рассчитывает возврат денег. Скидка строки уменьшается пропорционально
+// оставшемуся количеству. Для маркированного товара возвращаемые упаковки
+// указываются кодами.
+func (s *OrderService) PartialReturn(ctx context.Context, id int64, returns []domain.OrderItem, opts ...ReturnOption) (*domain.Order, *domain.Refund, error) {
+	options := returnOptions{method: domain.RefundMethodOriginal}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	refund := domain.Refund{Kind: domain.RefundKindReturn, Method: options.method}
+	updated, returns, err := s.removeItems(ctx, id, returns, &refund, domain.OrderStatusConfirmed)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.events.Publish(ctx, events.OrderReturned{Order: orderSnapshot(updated), Returned: returns, Refund: refund})
+	return updated, s.reloadRefund(ctx, refund), nil
 }
 
-// PartialReturn уменьшает количество в заказе и возвращает часть на склад
-func (s *OrderService) PartialReturn(ctx context.Context, id int64, returns []domain.OrderItem) (*domain.Order, error) {
+// PartialCancel снимает с заказа позиции, которые магазин не может выполнить, и
+// возвращает их на склад. В отличие от PartialReturn товар покупателю не
+// передавался: возврат денег записывается с видом partial_cancel и причиной
+// и учитывается в сводке отдельно от возвратов покупателей.
+func (s *OrderService) PartialCancel(ctx context.Context, id int64, items []domain.OrderItem, reason string) (*domain.Order, *domain.Refund, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, nil, invalidField("reason", "is required")
+	}
+	refund := domain.Refund{Kind: domain.RefundKindPartialCancel, Method: domain.RefundMethodOriginal, Reason: reason}
+	updated, items, err := s.removeItems(ctx, id, items, &refund, domain.OrderStatusConfirmed, domain.OrderStatusPending)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.events.Publish(ctx, events.OrderLinesCancelled{Order: orderSnapshot(updated), Cancelled: items, Refund: refund, Reason: reason})
+	return updated, s.reloadRefund(ctx, refund), nil
+}
+
+// removeItems уменьшает количество в заказе со статусом из statuses, возвращает
+// снятое на склад и сохраняет рассчитанный refund. Возвращает обновлённый заказ и
+// нормализованные позиции.
+func (s *OrderService) removeItems(ctx context.Context, id int64, returns []domain.OrderItem, refund *domain.Refund, statuses ...domain.OrderStatus) (*domain.Order, []domain.OrderItem, error) {
 	if id <= 0 || len(returns) == 0 {
-		return nil, ErrInvalidInput
+		return nil, nil, ErrInvalidInput
 	}
 	// validate returns
-	for _, r := range returns {
+	returns = append([]domain.OrderItem(nil), returns...)
+	for i, r := range returns {
 		if r.ProductID <= 0 || r.Quantity <= 0 {
-			return nil, ErrInvalidInput
+			return nil, nil, ErrInvalidInput
+		}
+		if len(r.Serials) > 0 {
+			codes, err := normalizeSerials("serials", r.Serials)
+			if err != nil {
+				return nil, nil, err
+			}
+			returns[i].Serials = codes
 		}
 	}
 
@@ -142,91 +615,228 @@ func (s *OrderService) PartialReturn(ctx context.Context, id int64, returns []do
 		if err != nil {
 			return err
 		}
-		if o.Status != domain.OrderStatusConfirmed {
+		if !slices.Contains(statuses, o.Status) {
 			return ErrInvalidState
 		}
-		// map current quantities
+		// map current quantities and sold codes
 		qtyByProduct := make(map[int64]int64)
+		soldBy := make(map[string]int64)
 		for _, it := range o.Items {
 			qtyByProduct[it.ProductID] += it.Quantity
+			for _, code := range it.Serials {
+				soldBy[code] = it.ProductID
+			}
 		}
-		// validate not exceeding
+		// validate not exceeding; serialized lines are returned by code
+		toReturn := make(map[int64]int64)
+		returnedCodes := make(map[string]bool)
 		for _, r := range returns {
-			if qtyByProduct[r.ProductID] < r.Quantity {
+			toReturn[r.ProductID] += r.Quantity
+			if qtyByProduct[r.ProductID] < toReturn[r.ProductID] {
 				return ErrInvalidInput
 			}
+			if err := checkReturnedSerials(r, soldBy, returnedCodes); err != nil {
+				return err
+			}
 		}
 		// apply returns to order items and restore stock
 		newItems := make([]domain.OrderItem, 0, len(o.Items))
-		consumed := make(map[int64]int64)
+		restock := make(map[int64]int64)
+		refund.OrderID = o.ID
 		for _, it := range o.Items {
-			ret := consumed[it.ProductID]
-			remainingToReturn := qtyByProduct[it.ProductID]
-			_ = remainingToReturn
-			// how much to return for this product overall
-			totalReturn := int64(0)
-			for _, r := range returns {
-				if r.ProductID == it.ProductID {
-					totalReturn += r.Quantity
+			var n int64
+			if len(it.Serials) > 0 {
+				kept := make([]string, 0, len(it.Serials))
+				for _, code := range it.Serials {
+					if returnedCodes[code] {
+						n++
+						continue
+					}
+					kept = append(kept, code)
 				}
+				it.Serials = kept
+			} else {
+				n = min(it.Quantity, toReturn[it.ProductID])
+				toReturn[it.ProductID] -= n
 			}
-			if ret >= totalReturn {
-				// already returned enough in previous items of same product
+			if n > 0 {
+				taxBefore := it.Tax
+				discount := reduceLine(&it, n)
+				it.Tax = lineTax(it)
+				refund.Lines = append(refund.Lines, domain.RefundLine{
+					ProductID: it.ProductID, Quantity: n, UnitPrice: it.UnitPrice,
+					Amount: roundMoney(it.UnitPrice * float64(n)), Discount: discount, Tax: roundMoney(taxBefore - it.Tax),
+				})
+			}
+			restock[it.ProductID] += n
+			if it.Quantity > 0 {
 				newItems = append(newItems, it)
+			}
+		}
+		for productID, qty := range restock {
+			if qty == 0 {
 				continue
 			}
-			// available to return from this item
-			canReturn := it.Quantity
-			needReturn := totalReturn - ret
-			if needReturn < canReturn {
-				// partially reduce
-				it.Quantity -= needReturn
-				consumed[it.ProductID] += needReturn
-				newItems = append(newItems, it)
-				// restore stock
-				p, err := s.products.GetByID(ctx, it.ProductID)
-				if err != nil {
-					return err
-				}
-				p.Stock += needReturn
-				if err := s.products.Update(ctx, p); err != nil {
-					return err
-				}
-			} else if needReturn == canReturn {
-				// drop this item fully
-				consumed[it.ProductID] += needReturn
-				// restore stock
-				p, err := s.products.GetByID(ctx, it.ProductID)
-				if err != nil {
-					return err
-				}
-				p.Stock += needReturn
-				if err := s.products.Update(ctx, p); err != nil {
-					return err
-				}
-			} else { // needReturn > canReturn
-				// consume whole item and continue
-				consumed[it.ProductID] += canReturn
-				p, err := s.products.GetByID(ctx, it.ProductID)
-				if err != nil {
-					return err
-				}
-				p.Stock += canReturn
-				if err := s.products.Update(ctx, p); err != nil {
-					return err
-				}
-				// item removed
+			p, err := s.products.GetByID(ctx, productID)
+			if err != nil {
+				return err
+			}
+			p.Stock += qty
+			if err := s.products.Update(ctx, p); err != nil {
+				return err
+			}
+		}
+		for _, r := range returns {
+			if err := s.restockSerials(ctx, r.Serials); err != nil {
+				return err
 			}
 		}
 		o.Items = newItems
+		recalcTotals(o)
 		if err := s.orders.Update(ctx, o); err != nil {
 			return err
 		}
 		updated = o
-		return nil
+		return s.createRefund(ctx, refund)
 	})
 	if err != nil {
+		return nil, nil, err
+	}
+	return updated, returns, nil
+}
+
+// createRefund подводит итоги возврата и сохраняет его со статусом Pending
+func (s *OrderService) createRefund(ctx context.Context, r *domain.Refund) error {
+	var amount, tax float64
+	for i := range r.Lines {
+		l := &r.Lines[i]
+		l.Total = roundMoney(l.Amount - l.Discount)
+		amount += l.Total
+		tax += l.Tax
+	}
+	r.Amount, r.Tax = roundMoney(amount), roundMoney(tax)
+	r.Status = domain.RefundStatusPending
+	if s.refunds == nil {
+		return nil
+	}
+	return s.refunds.Create(ctx, r)
+}
+
+// reloadRefund перечитывает возврат после синхронных подписчиков, которые могли вернуть деньги
+func (s *OrderService) reloadRefund(ctx context.Context, r domain.Refund) *domain.Refund {
+	if s.refunds != nil {
+		if cur, err := s.refunds.GetByID(ctx, r.ID); err == nil {
+			return cur
+		}
+	}
+	return &r
+}
+
+// Refunds возвращает возвраты денег по заказу
+func (s *OrderService) Refunds(ctx context.Context, orderID int64) ([]domain.Refund, error) {
+	if orderID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
 		return nil, err
 	}
-	return updated, nil
+	if s.refunds == nil {
+		return []domain.Refund{}, nil
+	}
+	return s.refunds.ListByOrder(ctx, orderID)
+}
+
+// orderSnapshot глубокая копия заказа для событий, чтобы подписчики не разделяли
+// слайсы (строки, коды упаковок, скидки, промокоды) с вызывающим и друг с другом
+func orderSnapshot(o *domain.Order) domain.Order {
+	cp := *o
+	cp.PromoCodes = append([]string(nil), o.PromoCodes...)
+	cp.Items = make([]domain.OrderItem, len(o.Items))
+	for i, it := range o.Items {
+		it.Serials = append([]string(nil), it.Serials...)
+		it.Discounts = append([]domain.LineDiscount(nil), it.Discounts...)
+		cp.Items[i] = it
+	}
+	return cp
+}
+
+// checkSerials проверяет коды строки заказа: маркированный товар требует по коду
+// на единицу, и каждая упаковка должна принадлежать товару и быть на складе
+func (s *OrderService) checkSerials(ctx context.Context, p domain.Product, it domain.OrderItem) ([]domain.SerialUnit, error) {
+	if !p.Serialized {
+		if len(it.Serials) > 0 {
+			return nil, invalidField("items.serials", "product %d is not serialized", p.ID)
+		}
+		return nil, nil
+	}
+	if s.serials == nil {
+		return nil, invalidField("items.serials", "serial tracking is not configured")
+	}
+	if int64(len(it.Serials)) != it.Quantity {
+		return nil, invalidField("items.serials", "product %d needs %d codes, got %d", p.ID, it.Quantity, len(it.Serials))
+	}
+	units := make([]domain.SerialUnit, 0, len(it.Serials))
+	for _, code := range it.Serials {
+		u, err := s.serials.Get(ctx, code)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, invalidField("items.serials", "unknown code %q", code)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if u.ProductID != p.ID {
+			return nil, invalidField("items.serials", "code %q belongs to product %d", code, u.ProductID)
+		}
+		if u.Status != domain.SerialStatusInStock {
+			return nil, fmt.Errorf("%w: code %q already sold", ErrInvalidState, code)
+		}
+		units = append(units, *u)
+	}
+	return units, nil
+}
+
+// checkReturnedSerials сверяет коды возврата с проданными в заказе
+func checkReturnedSerials(r domain.OrderItem, soldBy map[string]int64, returned map[string]bool) error {
+	serialized := false
+	for _, productID := range soldBy {
+		if productID == r.ProductID {
+			serialized = true
+			break
+		}
+	}
+	if !serialized {
+		if len(r.Serials) > 0 {
+			return invalidField("serials", "product %d was not sold by code", r.ProductID)
+		}
+		return nil
+	}
+	if int64(len(r.Serials)) != r.Quantity {
+		return invalidField("serials", "product %d needs %d codes, got %d", r.ProductID, r.Quantity, len(r.Serials))
+	}
+	for _, code := range r.Serials {
+		if soldBy[code] != r.ProductID || returned[code] {
+			return invalidField("serials", "code %q is not in the order", code)
+		}
+		returned[code] = true
+	}
+	return nil
+}
+
+// restockSerials возвращает проданные упаковки на склад
+func (s *OrderService) restockSerials(ctx context.Context, codes []string) error {
+	if s.serials == nil {
+		return nil
+	}
+	for _, code := range codes {
+		u, err := s.serials.Get(ctx, code)
+		if err != nil {
+			return err
+		}
+		u.Status = domain.SerialStatusInStock
+		u.OrderID = 0
+		if err := s.serials.Update(ctx, u); err != nil {
+			return err
+		}
+	}
+	return nil
 }
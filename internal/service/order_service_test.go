@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"april/internal/domain"
+	"april/internal/events"
 	"april/internal/repository"
 )
 
@@ -13,7 +15,7 @@ func setup(t *testing.T) (*ProductService, *OrderService) {
 	store := repository.NewMemoryStore()
 	ordersRepo := repository.NewMemoryOrders(store)
 	tx := repository.NewMemoryTx(store)
-	ps := NewProductService(store)
+	ps := NewProductService(store, tx)
 	os := NewOrderService(store, ordersRepo, tx)
 	return ps, os
 }
@@ -48,7 +50,7 @@ func TestCreateOrderAndCancel(t *testing.T) {
 	}
 
 	// cancel
-	o2, err := os.CancelOrder(ctx, o.ID)
+	o2, _, err := os.CancelOrder(ctx, o.ID)
 	if err != nil {
 		t.Fatalf("cancel order: %v", err)
 	}
@@ -64,6 +66,31 @@ func TestCreateOrderAndCancel(t *testing.T) {
 	}
 }
 
+func TestArchivedProduct_OrdersKeepWorking(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "SKU1", Price: 10, Stock: 5})
+	o, err := os.CreateOrder(ctx, "John", []domain.OrderItem{{ProductID: p.ID, Quantity: 2}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if _, err := ps.Archive(ctx, p.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := os.CreateOrder(ctx, "Jane", []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected archived product not orderable, got %v", err)
+	}
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel order with archived product: %v", err)
+	}
+	if got, _ := ps.GetByID(ctx, p.ID); got.Stock != 5 {
+		t.Fatalf("stock not restored: %v", got.Stock)
+	}
+	if err := ps.Purge(ctx, p.ID); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected conflict purging referenced product, got %v", err)
+	}
+}
+
 func TestCreateOrder_NotEnoughStock(t *testing.T) {
 	ctx := context.Background()
 	ps, os := setup(t)
@@ -88,7 +115,7 @@ func TestPartialReturn(t *testing.T) {
 	}
 
 	// return 2 of product1 and 1 of product2
-	o2, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p1.ID, Quantity: 2}, {ProductID: p2.ID, Quantity: 1}})
+	o2, _, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p1.ID, Quantity: 2}, {ProductID: p2.ID, Quantity: 1}})
 	if err != nil {
 		t.Fatalf("partial return: %v", err)
 	}
@@ -127,10 +154,10 @@ func TestCancelOrder_InvalidState(t *testing.T) {
 	if err != nil {
 		t.Fatalf("create order: %v", err)
 	}
-	if _, err := os.CancelOrder(ctx, o.ID); err != nil {
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
 		t.Fatalf("first cancel: %v", err)
 	}
-	if _, err := os.CancelOrder(ctx, o.ID); err == nil {
+	if _, _, err := os.CancelOrder(ctx, o.ID); err == nil {
 		t.Fatalf("expected invalid state on second cancel")
 	}
 }
@@ -143,7 +170,7 @@ func TestPartialReturn_Exceed(t *testing.T) {
 	if err != nil {
 		t.Fatalf("create order: %v", err)
 	}
-	if _, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p1.ID, Quantity: 3}}); err == nil {
+	if _, _, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p1.ID, Quantity: 3}}); err == nil {
 		t.Fatalf("expected validation error on exceed return")
 	}
 }
@@ -159,3 +186,243 @@ func TestCreateOrder_InvalidInput(t *testing.T) {
 		t.Fatalf("expected invalid quantity")
 	}
 }
+
+func TestOrderService_PublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+	bus := events.NewBus()
+	ps.SetEvents(bus)
+	os.SetEvents(bus)
+	var got []string
+	record := func(ctx context.Context, e events.Event) { got = append(got, e.EventName()) }
+	for _, name := range []string{events.NameProductCreated, events.NameOrderCreated, events.NameOrderReturned, events.NameOrderCancelled} {
+		bus.Subscribe(name, record)
+	}
+	var returned []domain.OrderItem
+	events.On(bus, func(ctx context.Context, e events.OrderReturned) { returned = e.Returned })
+
+	p1, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "SKU1", Price: 10, Stock: 10})
+	o, err := os.CreateOrder(ctx, "Jane", []domain.OrderItem{{ProductID: p1.ID, Quantity: 3}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if _, _, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p1.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("partial return: %v", err)
+	}
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	// failed operation publishes nothing
+	_, _ = os.CreateOrder(ctx, "Jane", []domain.OrderItem{{ProductID: p1.ID, Quantity: 100}})
+
+	want := []string{events.NameProductCreated, events.NameOrderCreated, events.NameOrderReturned, events.NameOrderCancelled}
+	if len(got) != len(want) {
+		t.Fatalf("events %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events %v, want %v", got, want)
+		}
+	}
+	if len(returned) != 1 || returned[0].Quantity != 1 {
+		t.Fatalf("unexpected returned items: %v", returned)
+	}
+}
+
+func TestOrderSnapshot_DeepCopy(t *testing.T) {
+	o := &domain.Order{PromoCodes: []string{"A"}, Items: []domain.OrderItem{{
+		ProductID: 1, Quantity: 1, Serials: []string{"S1"}, Discounts: []domain.LineDiscount{{Name: "x", Amount: 1}},
+	}}}
+	cp := orderSnapshot(o)
+	cp.PromoCodes[0] = "B"
+	cp.Items[0].Serials[0] = "S2"
+	cp.Items[0].Discounts[0].Amount = 2
+	if o.PromoCodes[0] != "A" || o.Items[0].Serials[0] != "S1" || o.Items[0].Discounts[0].Amount != 1 {
+		t.Fatalf("snapshot shares slices with the order: %+v", o)
+	}
+}
+
+func TestRefunds_CalculatedFromOrderLines(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	ps := NewProductService(store, tx)
+	promos := NewPromotionService(repository.NewMemoryPromotions(store), store)
+	os := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	os.SetDiscounts(promos)
+	os.SetRefunds(repository.NewMemoryRefunds(store))
+
+	med, _ := ps.Create(ctx, domain.Product{Name: "Med", SKU: "M", Price: 110, Stock: 10, TaxRate: 10})
+	soap, _ := ps.Create(ctx, domain.Product{Name: "Soap", SKU: "S", Price: 30, Stock: 10, TaxRate: 20})
+	if _, err := promos.Create(ctx, domain.Promotion{Name: "med -10%", Kind: domain.PromotionKindPercent, Percent: 10, ProductIDs: []int64{med.ID}}); err != nil {
+		t.Fatalf("create promotion: %v", err)
+	}
+	o, err := os.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: med.ID, Quantity: 4}, {ProductID: soap.ID, Quantity: 2}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	// 440 - 44 + 60 = 456; НДС 36 + 10
+	if o.Total != 456 || o.Tax != 46 {
+		t.Fatalf("unexpected order %+v", o)
+	}
+
+	o, r, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: med.ID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("partial return: %v", err)
+	}
+	if r.ID == 0 || r.Kind != domain.RefundKindReturn || r.Status != domain.RefundStatusPending || len(r.Lines) != 1 {
+		t.Fatalf("unexpected refund %+v", r)
+	}
+	if l := r.Lines[0]; l.Quantity != 1 || l.Amount != 110 || l.Discount != 11 || l.Tax != 9 || l.Total != 99 {
+		t.Fatalf("unexpected refund line %+v", l)
+	}
+	if r.Amount != 99 || o.Total != 357 {
+		t.Fatalf("refund %v must match order total change, order %+v", r.Amount, o)
+	}
+
+	_, r, err = os.CancelOrder(ctx, o.ID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if r.Kind != domain.RefundKindCancel || r.Amount != 357 || r.Tax != 37 || len(r.Lines) != 2 {
+		t.Fatalf("unexpected cancel refund %+v", r)
+	}
+	list, err := os.Refunds(ctx, o.ID)
+	if err != nil || len(list) != 2 || list[0].Kind != domain.RefundKindReturn || list[1].Kind != domain.RefundKindCancel {
+		t.Fatalf("refunds: %+v %v", list, err)
+	}
+	if _, err := os.Refunds(ctx, 99); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestEditOrder(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	ps := NewProductService(store, tx)
+	promos := NewPromotionService(repository.NewMemoryPromotions(store), store)
+	os := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	os.SetDiscounts(promos)
+	os.SetEdits(repository.NewMemoryOrderEdits(store))
+	bus := events.NewBus()
+	os.SetEvents(bus)
+	var edited []events.OrderEdited
+	events.On(bus, func(_ context.Context, e events.OrderEdited) { edited = append(edited, e) })
+
+	med, _ := ps.Create(ctx, domain.Product{Name: "Med", SKU: "M", Price: 100, Stock: 10})
+	soap, _ := ps.Create(ctx, domain.Product{Name: "Soap", SKU: "S", Price: 30, Stock: 10})
+	gel, _ := ps.Create(ctx, domain.Product{Name: "Gel", SKU: "G", Price: 50, Stock: 1})
+	mask, _ := ps.Create(ctx, domain.Product{Name: "Mask", SKU: "K", Price: 5, Stock: 10, Serialized: true})
+	if _, err := promos.Create(ctx, domain.Promotion{Name: "med -10%", Kind: domain.PromotionKindPercent, Percent: 10, ProductIDs: []int64{med.ID, gel.ID}}); err != nil {
+		t.Fatalf("create promotion: %v", err)
+	}
+	o, err := os.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: med.ID, Quantity: 4}, {ProductID: soap.ID, Quantity: 2}})
+	if err != nil || o.Total != 420 {
+		t.Fatalf("create order: %+v %v", o, err)
+	}
+
+	o, edit, err := os.EditOrder(ctx, o.ID, []domain.OrderItem{
+		{ProductID: med.ID, Quantity: 2}, {ProductID: soap.ID, Quantity: 0}, {ProductID: gel.ID, Quantity: 1},
+	}, " phone call ")
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	// 200 - 20 + 50 - 5
+	if o.Total != 225 || len(o.Items) != 2 || o.Items[0].Discount != 20 || o.Items[1].Discount != 5 {
+		t.Fatalf("unexpected edited order %+v", o)
+	}
+	if edit.ID == 0 || edit.TotalBefore != 420 || edit.TotalAfter != 225 || edit.Note != "phone call" || len(edit.Changes) != 3 {
+		t.Fatalf("unexpected edit %+v", edit)
+	}
+	for id, want := range map[int64]int64{med.ID: 8, soap.ID: 10, gel.ID: 0} {
+		if p, _ := ps.GetByID(ctx, id); p.Stock != want {
+			t.Fatalf("product %d stock %d, want %d", id, p.Stock, want)
+		}
+	}
+	if len(edited) != 1 || edited[0].Edit.ID != edit.ID {
+		t.Fatalf("expected order.edited event, got %+v", edited)
+	}
+
+	_, _, err = os.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: gel.ID, Quantity: 2}}, "")
+	var stockErr *StockError
+	if !errors.As(err, &stockErr) || stockErr.Available != 0 {
+		t.Fatalf("expected stock error, got %v", err)
+	}
+	if _, _, err := os.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: mask.ID, Quantity: 1}}, ""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected serialized product rejected, got %v", err)
+	}
+	if _, _, err := os.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: med.ID, Quantity: 2}}, ""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected nothing to change, got %v", err)
+	}
+	if _, _, err := os.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: med.ID, Quantity: 0}, {ProductID: gel.ID, Quantity: 0}}, ""); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected empty order rejected, got %v", err)
+	}
+	if p, _ := ps.GetByID(ctx, med.ID); p.Stock != 8 {
+		t.Fatalf("rejected edits must not touch stock: %d", p.Stock)
+	}
+
+	list, err := os.Edits(ctx, o.ID)
+	if err != nil || len(list) != 1 || list[0].Changes[0] != (domain.OrderLineChange{ProductID: med.ID, Before: 4, After: 2}) {
+		t.Fatalf("edits: %+v %v", list, err)
+	}
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if p, _ := ps.GetByID(ctx, gel.ID); p.Stock != 1 {
+		t.Fatalf("cancel must return added line: %d", p.Stock)
+	}
+	if _, _, err := os.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: med.ID, Quantity: 1}}, ""); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected invalid state for cancelled order, got %v", err)
+	}
+}
+
+func TestPartialCancel(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	os := NewOrderService(store, repository.NewMemoryOrders(store), repository.NewMemoryTx(store))
+	os.SetRefunds(repository.NewMemoryRefunds(store))
+	bus := events.NewBus()
+	os.SetEvents(bus)
+	var cancelled []events.OrderLinesCancelled
+	returned := 0
+	events.On(bus, func(_ context.Context, e events.OrderLinesCancelled) { cancelled = append(cancelled, e) })
+	events.On(bus, func(context.Context, events.OrderReturned) { returned++ })
+
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 10, Stock: 5})
+	o, err := os.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: p.ID, Quantity: 4}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if _, _, err := os.PartialCancel(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}, " "); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected reason required, got %v", err)
+	}
+	if _, _, err := os.PartialCancel(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 5}}, "out_of_stock"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected exceeding quantity rejected, got %v", err)
+	}
+
+	o, r, err := os.PartialCancel(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 3}}, " out_of_stock ")
+	if err != nil {
+		t.Fatalf("partial cancel: %v", err)
+	}
+	if o.Total != 10 || o.Status != domain.OrderStatusConfirmed {
+		t.Fatalf("unexpected order %+v", o)
+	}
+	if r.Kind != domain.RefundKindPartialCancel || r.Reason != "out_of_stock" || r.Amount != 30 || len(r.Lines) != 1 {
+		t.Fatalf("unexpected refund %+v", r)
+	}
+	if got, _ := ps.GetByID(ctx, p.ID); got.Stock != 4 {
+		t.Fatalf("cancelled quantity not restocked: %d", got.Stock)
+	}
+	if len(cancelled) != 1 || cancelled[0].Reason != "out_of_stock" || cancelled[0].Cancelled[0].Quantity != 3 || returned != 0 {
+		t.Fatalf("expected only order.lines_cancelled, got %+v returned=%d", cancelled, returned)
+	}
+
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if _, _, err := os.PartialCancel(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}, "out_of_stock"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected invalid state, got %v", err)
+	}
+}
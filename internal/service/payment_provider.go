@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPaymentDeclined провайдер отказал в оплате
+var ErrPaymentDeclined = errors.New("payment declined")
+
+// PaymentRequest запрос авторизации оплаты заказа
+type PaymentRequest struct {
+	OrderID int64
+	Amount  float64
+	// Token платёжные данные покупателя, полученные провайдером на стороне клиента
+	Token string
+}
+
+// PaymentProvider платёжный провайдер. Authorize блокирует сумму и возвращает
+// идентификатор авторизации, остальные операции выполняются по нему. Отказ
+// провайдера оборачивает ErrPaymentDeclined.
+type PaymentProvider interface {
+	Name() string
+	Authorize(ctx context.Context, req PaymentRequest) (string, error)
+	Capture(ctx context.Context, ref string, amount float64) error
+	Void(ctx context.Context, ref string) error
+	Refund(ctx context.Context, ref string, amount float64) error
+}
+
+// FakeDeclineToken токен, по которому FakePaymentProvider отказывает в оплате
+const FakeDeclineToken = "tok_declined"
+
+// FakeAuthorization состояние авторизации в FakePaymentProvider
+type FakeAuthorization struct {
+	Amount   float64
+	Captured float64
+	Refunded float64
+	Voided   bool
+}
+
+// FakePaymentProvider провайдер в памяти для тестов и локального запуска:
+// одобряет любой токен, кроме FakeDeclineToken, и проверяет суммы операций
+type FakePaymentProvider struct {
+	mu    sync.Mutex
+	next  int64
+	auths map[string]*FakeAuthorization
+}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{auths: make(map[string]*FakeAuthorization)}
+}
+
+var _ PaymentProvider = (*FakePaymentProvider)(nil)
+
+func (f *FakePaymentProvider) Name() string { return "fake" }
+
+func (f *FakePaymentProvider) Authorize(ctx context.Context, req PaymentRequest) (string, error) {
+	if req.Token == FakeDeclineToken {
+		return "", fmt.Errorf("%w: card declined", ErrPaymentDeclined)
+	}
+	if req.Amount <= 0 {
+		return "", fmt.Errorf("fake: invalid amount %.2f", req.Amount)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	ref := fmt.Sprintf("fake_%d", f.next)
+	f.auths[ref] = &FakeAuthorization{Amount: req.Amount}
+	return ref, nil
+}
+
+func (f *FakePaymentProvider) Capture(ctx context.Context, ref string, amount float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, err := f.auth(ref)
+	if err != nil {
+		return err
+	}
+	if a.Voided || amount <= 0 || amount > roundMoney(a.Amount-a.Captured) {
+		return fmt.Errorf("fake: cannot capture %.2f of %s", amount, ref)
+	}
+	a.Captured = roundMoney(a.Captured + amount)
+	return nil
+}
+
+func (f *FakePaymentProvider) Void(ctx context.Context, ref string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, err := f.auth(ref)
+	if err != nil {
+		return err
+	}
+	if a.Voided || a.Captured > 0 {
+		return fmt.Errorf("fake: cannot void %s", ref)
+	}
+	a.Voided = true
+	return nil
+}
+
+func (f *FakePaymentProvider) Refund(ctx context.Context, ref string, amount float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, err := f.auth(ref)
+	if err != nil {
+		return err
+	}
+	if amount <= 0 || amount > roundMoney(a.Captured-a.Refunded) {
+		return fmt.Errorf("fake: cannot refund %.2f of %s", amount, ref)
+	}
+	a.Refunded = roundMoney(a.Refunded + amount)
+	return nil
+}
+
+// Authorization возвращает состояние авторизации по идентификатору
+func (f *FakePaymentProvider) Authorization(ref string) (FakeAuthorization, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.auths[ref]
+	if !ok {
+		return FakeAuthorization{}, false
+	}
+	return *a, true
+}
+
+func (f *FakePaymentProvider) auth(ref string) (*FakeAuthorization, error) {
+	a, ok := f.auths[ref]
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown authorization %s", ref)
+	}
+	return a, nil
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// PaymentService проводит оплату заказов через PaymentProvider и возвращает деньги
+// при отмене и частичном возврате заказа
+type PaymentService struct {
+	orders   repository.OrderRepository
+	payments repository.PaymentRepository
+	provider PaymentProvider
+	tx       repository.TxManager
+	refunds  repository.RefundRepository
+	credits  *CreditService
+	// mu упорядочивает операции с оплатами: вызовы провайдера идут вне транзакций
+	mu sync.Mutex
+}
+
+func NewPaymentService(orders repository.OrderRepository, payments repository.PaymentRepository, provider PaymentProvider, tx repository.TxManager) *PaymentService {
+	return &PaymentService{orders: orders, payments: payments, provider: provider, tx: tx}
+}
+
+// SetRefunds подключает возвраты денег: их статус обновляется по результату операции у провайдера
+func (s *PaymentService) SetRefunds(refunds repository.RefundRepository) { s.refunds = refunds }
+
+// SetCredits подключает оплату подарочными картами и депозитом и возврат денег на депозит
+func (s *PaymentService) SetCredits(credits *CreditService) { s.credits = credits }
+
+// creditProvider имя провайдера в оплатах с предоплаченных счетов
+const creditProvider = "credit"
+
+// Subscribe снимает блокировку или возвращает деньги после отмены, частичной отмены,
+// возврата и изменения заказа, уменьшившего его сумму.
+// Обработчики синхронные: к ответу на запрос возврат уже проведён.
+func (s *PaymentService) Subscribe(bus *events.Bus) {
+	events.On(bus, func(ctx context.Context, e events.OrderCancelled) {
+		s.settleLogged(ctx, e.Refund)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderReturned) {
+		s.settleLogged(ctx, e.Refund)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderLinesCancelled) {
+		s.settleLogged(ctx, e.Refund)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderEdited) {
+		if e.Refund.Amount > 0 {
+			s.settleLogged(ctx, e.Refund)
+		}
+	})
+}
+
+func (s *PaymentService) settleLogged(ctx context.Context, r domain.Refund) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.settle(ctx, &r); err != nil {
+		log.Printf("payments for order %d: %v", r.OrderID, err)
+	}
+	if s.refunds == nil || r.ID == 0 {
+		return
+	}
+	if err := s.refunds.Update(ctx, &r); err != nil {
+		log.Printf("refund %d: %v", r.ID, err)
+	}
+}
+
+// Pay авторизует оплату неоплаченного остатка заказа; при capture сразу списывает её.
+// Остаток — Total за вычетом действующих оплат, так что часть заказа можно оплатить
+// картой или депозитом через PayWithCredit. Отказ провайдера
+// сохраняется в оплате со статусом Failed и возвращается как ErrPaymentDeclined.
+func (s *PaymentService) Pay(ctx context.Context, orderID int64, token string, capture bool) (*domain.Payment, error) {
+	if orderID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if token == "" {
+		return nil, invalidField("token", "is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var p domain.Payment
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		o, err := s.orders.GetByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		amount, err := s.outstanding(ctx, o)
+		if err != nil {
+			return err
+		}
+		p = domain.Payment{OrderID: orderID, Provider: s.provider.Name(), Amount: amount, Status: domain.PaymentStatusPending}
+		return s.payments.Create(ctx, &p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := s.provider.Authorize(ctx, PaymentRequest{OrderID: orderID, Amount: p.Amount, Token: token})
+	if err != nil {
+		p.Status, p.Error = domain.PaymentStatusFailed, err.Error()
+		if uerr := s.payments.Update(ctx, &p); uerr != nil {
+			return nil, uerr
+		}
+		return &p, err
+	}
+	p.ProviderRef, p.Status = ref, domain.PaymentStatusAuthorized
+	var captureErr error
+	if capture {
+		captureErr = s.capture(ctx, &p)
+	}
+	if err := s.payments.Update(ctx, &p); err != nil {
+		return nil, err
+	}
+	if captureErr != nil {
+		return &p, captureErr
+	}
+	return &p, nil
+}
+
+// PayWithCredit оплачивает заказ с подарочной карты или депозита по коду; amount 0 —
+// сколько хватает баланса в пределах остатка. Оплата сразу списана.
+func (s *PaymentService) PayWithCredit(ctx context.Context, orderID int64, code string, amount float64) (*domain.Payment, error) {
+	if orderID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if s.credits == nil {
+		return nil, invalidField("credit_code", "credit accounts are not configured")
+	}
+	code = normalizeCreditCode(code)
+	if code == "" {
+		return nil, invalidField("credit_code", "is required")
+	}
+	if amount < 0 || roundMoney(amount) != amount {
+		return nil, invalidField("amount", "must be non-negative with at most 2 decimals")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var p domain.Payment
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		o, err := s.orders.GetByID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		rest, err := s.outstanding(ctx, o)
+		if err != nil {
+			return err
+		}
+		a, err := s.credits.accountByCode(ctx, code)
+		if err != nil {
+			return err
+		}
+		if amount == 0 {
+			amount = min(a.Balance, rest)
+		}
+		if amount <= 0 || amount > rest || amount > a.Balance {
+			return ErrInvalidState
+		}
+		p = domain.Payment{
+			OrderID: orderID, Provider: creditProvider, AccountID: a.ID,
+			Amount: amount, Captured: amount, Status: domain.PaymentStatusCaptured,
+		}
+		if err := s.payments.Create(ctx, &p); err != nil {
+			return err
+		}
+		_, err = s.credits.post(ctx, a, domain.CreditTransaction{
+			Kind: domain.CreditTransactionPayment, Amount: -amount, OrderID: orderID, PaymentID: p.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Capture списывает авторизованную оплату в пределах текущей суммы заказа за вычетом других оплат
+func (s *PaymentService) Capture(ctx context.Context, id int64) (*domain.Payment, error) {
+	return s.change(ctx, id, domain.PaymentStatusAuthorized, s.capture)
+}
+
+// Void снимает блокировку авторизованной, но не списанной оплаты
+func (s *PaymentService) Void(ctx context.Context, id int64) (*domain.Payment, error) {
+	return s.change(ctx, id, domain.PaymentStatusAuthorized, s.void)
+}
+
+// List возвращает оплаты заказа, включая неуспешные
+func (s *PaymentService) List(ctx context.Context, orderID int64) ([]domain.Payment, error) {
+	if orderID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+	return s.payments.List(ctx, repository.PaymentFilter{OrderID: orderID})
+}
+
+func (s *PaymentService) Get(ctx context.Context, id int64) (*domain.Payment, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.payments.GetByID(ctx, id)
+}
+
+// change выполняет операцию провайдера над оплатой в статусе from и сохраняет результат;
+// ошибка провайдера сохраняется в оплате
+func (s *PaymentService) change(ctx context.Context, id int64, from domain.PaymentStatus, op func(context.Context, *domain.Payment) error) (*domain.Payment, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, err := s.payments.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != from {
+		return nil, ErrInvalidState
+	}
+	opErr := op(ctx, p)
+	if err := s.payments.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	if opErr != nil {
+		return nil, opErr
+	}
+	return p, nil
+}
+
+// settle возвращает деньги по возврату r в пределах оплаченного сверх текущей суммы
+// заказа и проставляет статус возврата. Заблокированные оплаты отменённого заказа
+// снимаются, а после частичного возврата списание возьмёт новую сумму. На исходные
+// оплаты деньги возвращаются начиная с последней; на депозит — покупателю заказа.
+// Вызывается под s.mu.
+func (s *PaymentService) settle(ctx context.Context, r *domain.Refund) error {
+	o, err := s.orders.GetByID(ctx, r.OrderID)
+	if err != nil {
+		return err
+	}
+	held, list, err := s.held(ctx, r.OrderID, r.ID)
+	if err != nil {
+		return err
+	}
+	due := o.Total
+	if o.Status == domain.OrderStatusCancelled {
+		due = 0
+	}
+	r.Status, r.Refunded = domain.RefundStatusNotRequired, 0
+	var errs []error
+	if due == 0 {
+		for i := range list {
+			if list[i].Status != domain.PaymentStatusAuthorized {
+				continue
+			}
+			errs = append(errs, s.void(ctx, &list[i]))
+			if err := s.payments.Update(ctx, &list[i]); err != nil {
+				return err
+			}
+		}
+	}
+	amount := min(r.Amount, roundMoney(held-due))
+	if amount > 0 {
+		if r.Method == domain.RefundMethodStoreCredit {
+			errs = append(errs, s.refundToStoreCredit(ctx, o, r, amount))
+		} else {
+			rest, err := s.refundPayments(ctx, list, r, amount)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			r.Refunded = roundMoney(amount - rest)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		r.Status, r.Error = domain.RefundStatusFailed, err.Error()
+		return err
+	}
+	if r.Refunded > 0 {
+		r.Status, r.Error = domain.RefundStatusCompleted, ""
+	}
+	return nil
+}
+
+// refundPayments возвращает amount на списанные оплаты начиная с последней и
+// возвращает невозвращённый остаток
+func (s *PaymentService) refundPayments(ctx context.Context, list []domain.Payment, r *domain.Refund, amount float64) (float64, error) {
+	for i := len(list) - 1; i >= 0 && amount > 0; i-- {
+		p := &list[i]
+		if p.Status != domain.PaymentStatusCaptured && p.Status != domain.PaymentStatusPartiallyRefunded {
+			continue
+		}
+		part := min(amount, roundMoney(p.Captured-p.Refunded))
+		if part <= 0 {
+			continue
+		}
+		r.PaymentID = p.ID
+		opErr := s.refund(ctx, p, part, r.ID)
+		if err := s.payments.Update(ctx, p); err != nil {
+			return amount, err
+		}
+		if opErr != nil {
+			return amount, opErr
+		}
+		amount = roundMoney(amount - part)
+	}
+	return amount, nil
+}
+
+// refundToStoreCredit начисляет amount на депозит покупателя заказа
+func (s *PaymentService) refundToStoreCredit(ctx context.Context, o *domain.Order, r *domain.Refund, amount float64) error {
+	if s.credits == nil {
+		return errors.New("store credit is not configured")
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		a, err := s.credits.storeCredit(ctx, o.CustomerName)
+		if err != nil {
+			return err
+		}
+		_, err = s.credits.post(ctx, a, domain.CreditTransaction{
+			Kind: domain.CreditTransactionRefund, Amount: amount, OrderID: o.ID, RefundID: r.ID,
+		})
+		if err != nil {
+			return err
+		}
+		r.AccountID, r.Refunded = a.ID, amount
+		return nil
+	})
+}
+
+func (s *PaymentService) capture(ctx context.Context, p *domain.Payment) error {
+	o, err := s.orders.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return err
+	}
+	if o.Status != domain.OrderStatusConfirmed {
+		return ErrInvalidState
+	}
+	held, _, err := s.held(ctx, p.OrderID, 0)
+	if err != nil {
+		return err
+	}
+	amount := min(p.Amount, roundMoney(o.Total-held))
+	if amount <= 0 {
+		return ErrInvalidState
+	}
+	if err := s.provider.Capture(ctx, p.ProviderRef, amount); err != nil {
+		p.Error = err.Error()
+		return err
+	}
+	p.Captured, p.Status, p.Error = amount, domain.PaymentStatusCaptured, ""
+	return nil
+}
+
+func (s *PaymentService) void(ctx context.Context, p *domain.Payment) error {
+	if err := s.provider.Void(ctx, p.ProviderRef); err != nil {
+		p.Error = err.Error()
+		return err
+	}
+	p.Status, p.Error = domain.PaymentStatusVoided, ""
+	return nil
+}
+
+// refund возвращает amount по оплате: на счёт, с которого платили, или через провайдера
+func (s *PaymentService) refund(ctx context.Context, p *domain.Payment, amount float64, refundID int64) error {
+	var err error
+	if p.Provider == creditProvider {
+		err = s.refundToAccount(ctx, p, amount, refundID)
+	} else {
+		err = s.provider.Refund(ctx, p.ProviderRef, amount)
+	}
+	if err != nil {
+		p.Error = err.Error()
+		return err
+	}
+	p.Refunded, p.Error = roundMoney(p.Refunded+amount), ""
+	p.Status = domain.PaymentStatusPartiallyRefunded
+	if p.Refunded >= p.Captured {
+		p.Status = domain.PaymentStatusRefunded
+	}
+	return nil
+}
+
+func (s *PaymentService) refundToAccount(ctx context.Context, p *domain.Payment, amount float64, refundID int64) error {
+	if s.credits == nil {
+		return errors.New("credit accounts are not configured")
+	}
+	return s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		a, err := s.credits.accountByID(ctx, p.AccountID)
+		if err != nil {
+			return err
+		}
+		_, err = s.credits.post(ctx, a, domain.CreditTransaction{
+			Kind: domain.CreditTransactionRefund, Amount: amount, OrderID: p.OrderID, PaymentID: p.ID, RefundID: refundID,
+		})
+		return err
+	})
+}
+
+// outstanding неоплаченный остаток заказа; ErrInvalidState, если платить нечего
+func (s *PaymentService) outstanding(ctx context.Context, o *domain.Order) (float64, error) {
+	if o.Status != domain.OrderStatusConfirmed {
+		return 0, ErrInvalidState
+	}
+	held, list, err := s.held(ctx, o.ID, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range list {
+		if p.Status == domain.PaymentStatusPending || p.Status == domain.PaymentStatusAuthorized {
+			held += p.Amount
+		}
+	}
+	rest := roundMoney(o.Total - held)
+	if rest <= 0 {
+		return 0, ErrInvalidState
+	}
+	return rest, nil
+}
+
+// held сумма, списанная по заказу и ещё не возвращённая, включая возвраты на
+// депозит кроме exceptRefund, и оплаты заказа по возрастанию id
+func (s *PaymentService) held(ctx context.Context, orderID, exceptRefund int64) (float64, []domain.Payment, error) {
+	list, err := s.payments.List(ctx, repository.PaymentFilter{OrderID: orderID})
+	if err != nil {
+		return 0, nil, err
+	}
+	var held float64
+	for _, p := range list {
+		if p.Status == domain.PaymentStatusCaptured || p.Status == domain.PaymentStatusPartiallyRefunded {
+			held += p.Captured - p.Refunded
+		}
+	}
+	if s.refunds != nil {
+		refunds, err := s.refunds.ListByOrder(ctx, orderID)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, r := range refunds {
+			if r.ID != exceptRefund && r.Method == domain.RefundMethodStoreCredit {
+				held -= r.Refunded
+			}
+		}
+	}
+	return roundMoney(held), list, nil
+}
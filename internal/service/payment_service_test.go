@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+type paymentFixture struct {
+	products *ProductService
+	orders   *OrderService
+	payments *PaymentService
+	provider *FakePaymentProvider
+	credits  *CreditService
+}
+
+func setupPayments(t *testing.T) paymentFixture {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	ordersRepo := repository.NewMemoryOrders(store)
+	os := NewOrderService(store, ordersRepo, tx)
+	os.SetEvents(bus)
+	provider := NewFakePaymentProvider()
+	payments := NewPaymentService(ordersRepo, repository.NewMemoryPayments(store), provider, tx)
+	refunds := repository.NewMemoryRefunds(store)
+	os.SetRefunds(refunds)
+	payments.SetRefunds(refunds)
+	payments.Subscribe(bus)
+	credits := NewCreditService(repository.NewMemoryCredits(store), tx)
+	payments.SetCredits(credits)
+	return paymentFixture{NewProductService(store, tx), os, payments, provider, credits}
+}
+
+func (f paymentFixture) order(t *testing.T, qty int64) *domain.Order {
+	t.Helper()
+	ctx := context.Background()
+	p, err := f.products.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 25, Stock: 100})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	o, err := f.orders.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: p.ID, Quantity: qty}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	return o
+}
+
+func TestPayment_PayAndDecline(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+	o := f.order(t, 4)
+
+	p, err := f.payments.Pay(ctx, o.ID, FakeDeclineToken, true)
+	if !errors.Is(err, ErrPaymentDeclined) || p == nil || p.Status != domain.PaymentStatusFailed || p.Error == "" {
+		t.Fatalf("expected recorded decline, got %+v %v", p, err)
+	}
+	p, err = f.payments.Pay(ctx, o.ID, "tok_visa", true)
+	if err != nil || p.Status != domain.PaymentStatusCaptured || p.Captured != 100 || p.ProviderRef == "" {
+		t.Fatalf("pay: %+v %v", p, err)
+	}
+	if _, err := f.payments.Pay(ctx, o.ID, "tok_visa", true); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected second payment rejected, got %v", err)
+	}
+	list, _ := f.payments.List(ctx, o.ID)
+	if len(list) != 2 {
+		t.Fatalf("expected failed and captured payments, got %+v", list)
+	}
+	if _, err := f.payments.Pay(ctx, o.ID, "", true); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected missing token rejected, got %v", err)
+	}
+}
+
+func TestPayment_RefundOnReturnAndCancel(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+	o := f.order(t, 4)
+	p, _ := f.payments.Pay(ctx, o.ID, "tok_visa", true)
+
+	_, r, err := f.orders.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: o.Items[0].ProductID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("partial return: %v", err)
+	}
+	if r.Status != domain.RefundStatusCompleted || r.PaymentID != p.ID || r.Amount != 25 {
+		t.Fatalf("expected completed refund, got %+v", r)
+	}
+	p, _ = f.payments.Get(ctx, p.ID)
+	if p.Status != domain.PaymentStatusPartiallyRefunded || p.Refunded != 25 {
+		t.Fatalf("expected partial refund, got %+v", p)
+	}
+	if _, r, err = f.orders.CancelOrder(ctx, o.ID); err != nil || r.Status != domain.RefundStatusCompleted || r.Amount != 75 {
+		t.Fatalf("cancel: %+v %v", r, err)
+	}
+	p, _ = f.payments.Get(ctx, p.ID)
+	if p.Status != domain.PaymentStatusRefunded || p.Refunded != 100 {
+		t.Fatalf("expected full refund, got %+v", p)
+	}
+	if a, _ := f.provider.Authorization(p.ProviderRef); a.Refunded != 100 {
+		t.Fatalf("provider not refunded: %+v", a)
+	}
+}
+
+func TestPayment_RefundOnPartialCancel(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+	o := f.order(t, 4)
+	p, _ := f.payments.Pay(ctx, o.ID, "tok_visa", true)
+
+	_, r, err := f.orders.PartialCancel(ctx, o.ID, []domain.OrderItem{{ProductID: o.Items[0].ProductID, Quantity: 2}}, "out_of_stock")
+	if err != nil {
+		t.Fatalf("partial cancel: %v", err)
+	}
+	if r.Kind != domain.RefundKindPartialCancel || r.Status != domain.RefundStatusCompleted || r.Refunded != 50 || r.PaymentID != p.ID {
+		t.Fatalf("expected completed refund, got %+v", r)
+	}
+	if p, _ = f.payments.Get(ctx, p.ID); p.Refunded != 50 {
+		t.Fatalf("expected partial refund, got %+v", p)
+	}
+}
+
+func TestPayment_RefundOnEditAfterCapture(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+	o := f.order(t, 4)
+	p, _ := f.payments.Pay(ctx, o.ID, "tok_visa", true)
+
+	_, edit, err := f.orders.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: o.Items[0].ProductID, Quantity: 1}}, "")
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	refunds, _ := f.orders.Refunds(ctx, o.ID)
+	if len(refunds) != 1 || refunds[0].ID != edit.RefundID || refunds[0].Kind != domain.RefundKindEdit ||
+		refunds[0].Status != domain.RefundStatusCompleted || refunds[0].Refunded != 75 {
+		t.Fatalf("expected completed edit refund, got %+v", refunds)
+	}
+	if p, _ = f.payments.Get(ctx, p.ID); p.Refunded != 75 {
+		t.Fatalf("expected 75 refunded after edit, got %+v", p)
+	}
+	if _, r, err := f.orders.CancelOrder(ctx, o.ID); err != nil || r.Refunded != 25 {
+		t.Fatalf("cancel: %+v %v", r, err)
+	}
+	if p, _ = f.payments.Get(ctx, p.ID); p.Status != domain.PaymentStatusRefunded || p.Refunded != 100 {
+		t.Fatalf("expected full refund, got %+v", p)
+	}
+
+	// увеличение суммы ничего не возвращает: доплата проводится через Pay
+	o = f.order(t, 1)
+	p, _ = f.payments.Pay(ctx, o.ID, "tok_visa", true)
+	if _, edit, err = f.orders.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: o.Items[0].ProductID, Quantity: 2}}, ""); err != nil || edit.RefundID != 0 {
+		t.Fatalf("edit up: %+v %v", edit, err)
+	}
+	if extra, err := f.payments.Pay(ctx, o.ID, "tok_visa", true); err != nil || extra.Captured != 25 {
+		t.Fatalf("expected surcharge of 25, got %+v %v", extra, err)
+	}
+}
+
+func TestPayment_AuthorizeCaptureAndVoid(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+
+	// списание после частичного возврата берёт текущую сумму заказа
+	o := f.order(t, 4)
+	p, err := f.payments.Pay(ctx, o.ID, "tok_visa", false)
+	if err != nil || p.Status != domain.PaymentStatusAuthorized || p.Amount != 100 {
+		t.Fatalf("authorize: %+v %v", p, err)
+	}
+	// списания ещё не было — возвращать нечего
+	if _, r, _ := f.orders.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: o.Items[0].ProductID, Quantity: 2}}); r.Status != domain.RefundStatusNotRequired {
+		t.Fatalf("expected refund not required before capture, got %+v", r)
+	}
+	if p, err = f.payments.Capture(ctx, p.ID); err != nil || p.Captured != 50 {
+		t.Fatalf("capture: %+v %v", p, err)
+	}
+	if _, err := f.payments.Void(ctx, p.ID); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected captured payment not voidable, got %v", err)
+	}
+
+	// отмена заказа снимает блокировку
+	o = f.order(t, 1)
+	p, _ = f.payments.Pay(ctx, o.ID, "tok_visa", false)
+	_, _, _ = f.orders.CancelOrder(ctx, o.ID)
+	p, _ = f.payments.Get(ctx, p.ID)
+	if a, _ := f.provider.Authorization(p.ProviderRef); p.Status != domain.PaymentStatusVoided || !a.Voided {
+		t.Fatalf("expected void on cancel, got %+v %+v", p, a)
+	}
+	if _, err := f.payments.Pay(ctx, o.ID, "tok_visa", true); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected cancelled order not payable, got %v", err)
+	}
+}
+
+func TestPayment_SplitTenderAndRefundToAccounts(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+	o := f.order(t, 4)
+	card, err := f.credits.IssueGiftCard(ctx, "gift-1", 30, "")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	pc, err := f.payments.PayWithCredit(ctx, o.ID, "GIFT-1", 0)
+	if err != nil || pc.Captured != 30 || pc.AccountID != card.ID {
+		t.Fatalf("pay with credit: %+v %v", pc, err)
+	}
+	if _, err := f.payments.PayWithCredit(ctx, o.ID, "GIFT-1", 10); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected empty card rejected, got %v", err)
+	}
+	pp, err := f.payments.Pay(ctx, o.ID, "tok_visa", true)
+	if err != nil || pp.Amount != 70 || pp.Captured != 70 {
+		t.Fatalf("pay rest: %+v %v", pp, err)
+	}
+	if _, err := f.payments.Pay(ctx, o.ID, "tok_visa", true); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected paid order rejected, got %v", err)
+	}
+
+	// возврат идёт сначала на последнюю оплату, остаток — на карту
+	_, r, err := f.orders.CancelOrder(ctx, o.ID)
+	if err != nil || r.Status != domain.RefundStatusCompleted || r.Refunded != 100 {
+		t.Fatalf("cancel: %+v %v", r, err)
+	}
+	if got, _ := f.provider.Authorization(pp.ProviderRef); got.Refunded != 70 {
+		t.Fatalf("expected provider refund 70, got %+v", got)
+	}
+	card, _ = f.credits.Get(ctx, card.ID)
+	if card.Balance != 30 {
+		t.Fatalf("expected card balance restored, got %v", card.Balance)
+	}
+	txs, _ := f.credits.Transactions(ctx, card.ID)
+	if len(txs) != 3 || txs[1].Amount != -30 || txs[2].Kind != domain.CreditTransactionRefund || txs[2].RefundID != r.ID {
+		t.Fatalf("unexpected history: %+v", txs)
+	}
+}
+
+func TestPayment_ReturnToStoreCredit(t *testing.T) {
+	ctx := context.Background()
+	f := setupPayments(t)
+	o := f.order(t, 4)
+	p, _ := f.payments.Pay(ctx, o.ID, "tok_visa", true)
+
+	_, r, err := f.orders.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: o.Items[0].ProductID, Quantity: 1}}, RefundToStoreCredit())
+	if err != nil || r.Method != domain.RefundMethodStoreCredit || r.Status != domain.RefundStatusCompleted || r.Refunded != 25 || r.AccountID == 0 {
+		t.Fatalf("return: %+v %v", r, err)
+	}
+	if got, _ := f.provider.Authorization(p.ProviderRef); got.Refunded != 0 {
+		t.Fatalf("expected provider untouched, got %+v", got)
+	}
+	a, _ := f.credits.Get(ctx, r.AccountID)
+	if a.Kind != domain.CreditAccountStoreCredit || a.Customer != "c" || a.Balance != 25 {
+		t.Fatalf("unexpected store credit: %+v", a)
+	}
+
+	// при отмене на исходную оплату возвращается только ещё не возвращённое
+	_, r, err = f.orders.CancelOrder(ctx, o.ID)
+	if err != nil || r.Refunded != 75 {
+		t.Fatalf("cancel: %+v %v", r, err)
+	}
+	if got, _ := f.provider.Authorization(p.ProviderRef); got.Refunded != 75 {
+		t.Fatalf("expected provider refund 75, got %+v", got)
+	}
+
+	// депозитом можно оплатить следующий заказ
+	next := f.order(t, 1)
+	pc, err := f.payments.PayWithCredit(ctx, next.ID, a.Code, 0)
+	if err != nil || pc.Captured != 25 {
+		t.Fatalf("pay with store credit: %+v %v", pc, err)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// PriceService ведёт историю цен товаров и применяет запланированные цены.
+// Ручные изменения цены попадают в историю по событиям каталога.
+type PriceService struct {
+	products repository.ProductRepository
+	prices   repository.PriceChangeRepository
+	tx       repository.TxManager
+	events   *events.Bus
+}
+
+func NewPriceService(products repository.ProductRepository, prices repository.PriceChangeRepository, tx repository.TxManager) *PriceService {
+	return &PriceService{products: products, prices: prices, tx: tx}
+}
+
+// SetEvents подключает шину событий; без неё события не публикуются
+func (s *PriceService) SetEvents(bus *events.Bus) { s.events = bus }
+
+// scheduledKey помечает контекст публикации изменения, применённого по расписанию:
+// такое изменение уже есть в истории
+type scheduledKey struct{}
+
+// Subscribe записывает в историю начальную цену и каждое изменение цены товара.
+// Обработчики синхронные: история видна сразу после ответа на запрос.
+func (s *PriceService) Subscribe(bus *events.Bus) {
+	events.On(bus, func(ctx context.Context, e events.ProductCreated) {
+		s.recordLogged(ctx, e.Product.ID, e.Product.Price, e.Product.CreatedAt)
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductUpdated) {
+		if e.Previous.Price == e.Product.Price || ctx.Value(scheduledKey{}) != nil {
+			return
+		}
+		s.recordLogged(ctx, e.Product.ID, e.Product.Price, e.Product.UpdatedAt)
+	})
+}
+
+func (s *PriceService) recordLogged(ctx context.Context, productID int64, price float64, at time.Time) {
+	c := domain.PriceChange{
+		ProductID: productID, Price: price, Status: domain.PriceChangeStatusApplied,
+		EffectiveFrom: at, AppliedAt: &at,
+	}
+	if err := s.prices.Create(ctx, &c); err != nil {
+		log.Printf("price history for product %d: %v", productID, err)
+	}
+}
+
+// Schedule планирует новую цену товара с момента effectiveFrom (только в будущем)
+func (s *PriceService) Schedule(ctx context.Context, productID int64, price float64, effectiveFrom time.Time) (*domain.PriceChange, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if price < 0 {
+		return nil, invalidField("price", "must not be negative")
+	}
+	if !effectiveFrom.After(time.Now()) {
+		return nil, invalidField("effective_from", "must be in the future")
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	c := domain.PriceChange{
+		ProductID: productID, Price: price, Status: domain.PriceChangeStatusScheduled,
+		EffectiveFrom: effectiveFrom.UTC(),
+	}
+	if err := s.prices.Create(ctx, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Cancel отменяет запланированное, ещё не применённое изменение цены
+func (s *PriceService) Cancel(ctx context.Context, productID, changeID int64) (*domain.PriceChange, error) {
+	if productID <= 0 || changeID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var out *domain.PriceChange
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		c, err := s.prices.GetByID(ctx, changeID)
+		if err != nil {
+			return err
+		}
+		if c.ProductID != productID {
+			return repository.ErrNotFound
+		}
+		if c.Status != domain.PriceChangeStatusScheduled {
+			return ErrInvalidState
+		}
+		c.Status = domain.PriceChangeStatusCancelled
+		if err := s.prices.Update(ctx, c); err != nil {
+			return err
+		}
+		out = c
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// History возвращает хронологию цен товара: применённые, запланированные и отменённые
+func (s *PriceService) History(ctx context.Context, productID int64) ([]domain.PriceChange, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.prices.List(ctx, repository.PriceChangeFilter{ProductID: productID})
+}
+
+// ApplyDue применяет запланированные цены, срок которых наступил к моменту now,
+// в порядке вступления в силу. Возвращает число применённых изменений.
+func (s *PriceService) ApplyDue(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.prices.List(ctx, repository.PriceChangeFilter{Status: domain.PriceChangeStatusScheduled, DueBy: &now})
+	if err != nil {
+		return 0, err
+	}
+	applied := 0
+	for _, c := range due {
+		var prev, next domain.Product
+		err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+			// изменение могли отменить после выборки
+			cur, err := s.prices.GetByID(ctx, c.ID)
+			if err != nil {
+				return err
+			}
+			if cur.Status != domain.PriceChangeStatusScheduled {
+				return nil
+			}
+			p, err := s.products.GetByID(ctx, c.ProductID)
+			if err != nil {
+				return err
+			}
+			prev, next = *p, *p
+			next.Price = cur.Price
+			if err := s.products.Update(ctx, &next); err != nil {
+				return err
+			}
+			at := now.UTC()
+			cur.Status, cur.AppliedAt = domain.PriceChangeStatusApplied, &at
+			return s.prices.Update(ctx, cur)
+		})
+		if err != nil {
+			return applied, err
+		}
+		if next.ID == 0 {
+			continue
+		}
+		applied++
+		s.events.Publish(context.WithValue(ctx, scheduledKey{}, true), events.ProductUpdated{Previous: prev, Product: next})
+	}
+	return applied, nil
+}
+
+// Run раз в interval применяет наступившие цены, пока не отменён ctx
+func (s *PriceService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ApplyDue(ctx, time.Now().UTC()); err != nil {
+				log.Printf("scheduled prices: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+func setupPrices(t *testing.T) (*ProductService, *PriceService, *events.Bus) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	bus := events.NewBus()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	ps.SetEvents(bus)
+	prices := NewPriceService(store, repository.NewMemoryPriceChanges(store), repository.NewMemoryTx(store))
+	prices.SetEvents(bus)
+	prices.Subscribe(bus)
+	return ps, prices, bus
+}
+
+func TestPrice_HistoryOfManualChanges(t *testing.T) {
+	ctx := context.Background()
+	ps, prices, _ := setupPrices(t)
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 5})
+	p.Stock = 4
+	_, _ = ps.Update(ctx, *p)
+	p.Price = 12
+	_, _ = ps.Update(ctx, *p)
+
+	list, err := prices.History(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	// изменение запаса без смены цены в историю не попадает
+	if len(list) != 2 || list[0].Price != 10 || list[1].Price != 12 || list[1].Status != domain.PriceChangeStatusApplied {
+		t.Fatalf("unexpected history %+v", list)
+	}
+	if _, err := prices.History(ctx, 99); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestPrice_ScheduleApplyAndCancel(t *testing.T) {
+	ctx := context.Background()
+	ps, prices, bus := setupPrices(t)
+	var updates []events.ProductUpdated
+	events.On(bus, func(ctx context.Context, e events.ProductUpdated) { updates = append(updates, e) })
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 5})
+
+	now := time.Now()
+	if _, err := prices.Schedule(ctx, p.ID, 9, now.Add(-time.Minute)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected past effective_from rejected, got %v", err)
+	}
+	if _, err := prices.Schedule(ctx, p.ID, -1, now.Add(time.Hour)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected negative price rejected, got %v", err)
+	}
+	first, err := prices.Schedule(ctx, p.ID, 11, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	second, _ := prices.Schedule(ctx, p.ID, 13, now.Add(2*time.Hour))
+	cancelled, _ := prices.Schedule(ctx, p.ID, 1, now.Add(90*time.Minute))
+	if _, err := prices.Cancel(ctx, p.ID, cancelled.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if _, err := prices.Cancel(ctx, p.ID, cancelled.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state cancelling twice, got %v", err)
+	}
+
+	if n, err := prices.ApplyDue(ctx, now); err != nil || n != 0 {
+		t.Fatalf("nothing is due yet: %d %v", n, err)
+	}
+	if n, err := prices.ApplyDue(ctx, now.Add(3*time.Hour)); err != nil || n != 2 {
+		t.Fatalf("apply due: %d %v", n, err)
+	}
+	if got, _ := ps.GetByID(ctx, p.ID); got.Price != 13 {
+		t.Fatalf("expected latest scheduled price, got %v", got.Price)
+	}
+	if len(updates) != 2 || updates[0].Previous.Price != 10 || updates[0].Product.Price != 11 {
+		t.Fatalf("unexpected update events %+v", updates)
+	}
+
+	list, _ := prices.History(ctx, p.ID)
+	if len(list) != 4 {
+		t.Fatalf("scheduled changes recorded twice: %+v", list)
+	}
+	for _, c := range list {
+		if (c.ID == first.ID || c.ID == second.ID) && (c.Status != domain.PriceChangeStatusApplied || c.AppliedAt == nil) {
+			t.Fatalf("change not marked applied: %+v", c)
+		}
+	}
+	if _, err := prices.Cancel(ctx, p.ID, first.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state cancelling applied change, got %v", err)
+	}
+}
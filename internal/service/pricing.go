@@ -0,0 +1,132 @@
+package service
+
+import (
+	"math"
+
+	"april/internal/domain"
+)
+
+// roundMoney округляет сумму до копеек
+func roundMoney(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// lineAmount сумма строки по цене без скидок
+func lineAmount(it domain.OrderItem) float64 {
+	return roundMoney(it.UnitPrice * float64(it.Quantity))
+}
+
+// lineTax НДС, включённый в сумму строки после скидок
+func lineTax(it domain.OrderItem) float64 {
+	return roundMoney((lineAmount(it) - it.Discount) * it.TaxRate / (100 + it.TaxRate))
+}
+
+// recalcTotals пересчитывает налог строк и суммы заказа по его строкам
+func recalcTotals(o *domain.Order) {
+	var subtotal, discount, tax float64
+	for i := range o.Items {
+		it := &o.Items[i]
+		it.Tax = lineTax(*it)
+		subtotal += lineAmount(*it)
+		discount += it.Discount
+		tax += it.Tax
+	}
+	o.Subtotal = roundMoney(subtotal)
+	o.Discount = roundMoney(discount)
+	o.Total = roundMoney(o.Subtotal - o.Discount)
+	o.Tax = roundMoney(tax)
+}
+
+// reduceLine убирает из строки n единиц и пропорционально уменьшает её скидки.
+// Возвращает снятую со строки сумму скидки.
+func reduceLine(it *domain.OrderItem, n int64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	before := it.Discount
+	scaleLine(it, it.Quantity-n)
+	return roundMoney(before - it.Discount)
+}
+
+// scaleLine меняет количество в строке на qty, пересчитывая её скидки пропорционально
+func scaleLine(it *domain.OrderItem, qty int64) {
+	discounts := make([]domain.LineDiscount, 0, len(it.Discounts))
+	var total float64
+	for _, d := range it.Discounts {
+		d.Amount = roundMoney(d.Amount * float64(qty) / float64(it.Quantity))
+		total += d.Amount
+		if d.Amount > 0 {
+			discounts = append(discounts, d)
+		}
+	}
+	it.Quantity = qty
+	it.Discount = roundMoney(total)
+	it.Discounts = discounts
+	if len(discounts) == 0 {
+		it.Discounts = nil
+	}
+}
+
+// allocateDiscount распределяет скидку d на amount по строкам пропорционально их
+// сумме после скидок; остаток от округления достаётся последней строке. Строка не
+// уходит в минус: не поместившаяся часть скидки возвращается.
+func allocateDiscount(items []domain.OrderItem, amount float64, d domain.LineDiscount) float64 {
+	var base float64
+	last := -1
+	for i, it := range items {
+		if net := lineAmount(it) - it.Discount; net > 0 {
+			base += net
+			last = i
+		}
+	}
+	if amount <= 0 {
+		return 0
+	}
+	if base <= 0 {
+		return amount
+	}
+	rest := amount
+	for i := range items {
+		it := &items[i]
+		net := lineAmount(*it) - it.Discount
+		if net <= 0 {
+			continue
+		}
+		part := roundMoney(amount * net / base)
+		if i == last {
+			part = rest
+		}
+		part = min(part, roundMoney(net))
+		if part <= 0 {
+			continue
+		}
+		rest = roundMoney(rest - part)
+		d.Amount = part
+		it.Discounts = append(it.Discounts, d)
+		it.Discount = roundMoney(it.Discount + part)
+	}
+	return rest
+}
+
+// takeDiscount снимает со строк скидки с названием name, не относящиеся к акциям,
+// и возвращает их сумму
+func takeDiscount(items []domain.OrderItem, name string) float64 {
+	var total float64
+	for i := range items {
+		it := &items[i]
+		kept := it.Discounts[:0:0]
+		for _, d := range it.Discounts {
+			if d.PromotionID == 0 && d.Name == name {
+				total += d.Amount
+				it.Discount = roundMoney(it.Discount - d.Amount)
+				continue
+			}
+			kept = append(kept, d)
+		}
+		it.Discounts = kept
+		if len(kept) == 0 {
+			it.Discounts = nil
+		}
+	}
+	return roundMoney(total)
+}
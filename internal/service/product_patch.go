@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/patch"
+)
+
+// readOnlyProductFields поля товара, которые патч не может изменить
+var readOnlyProductFields = []string{"id", "archived_at", "created_at", "updated_at"}
+
+// requiredProductFields поля, которые патч не может удалить
+var requiredProductFields = []string{"name", "sku", "price", "stock"}
+
+// Patch применяет к товару JSON Merge Patch или JSON Patch и сохраняет результат
+// с той же проверкой, что и Update. Чтение, патч и запись идут в одной транзакции,
+// поэтому поля, которых патч не касается (например, запас), не откатываются к
+// прочитанным раньше значениям. Невыполненная операция test — ErrInvalidState.
+func (s *ProductService) Patch(ctx context.Context, id int64, p patch.Patch) (*domain.Product, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var prev, cp domain.Product
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		doc, err := productDocument(*cur)
+		if err != nil {
+			return err
+		}
+		out, err := p.Apply(doc)
+		if err != nil {
+			if errors.Is(err, patch.ErrTestFailed) {
+				return fmt.Errorf("%w: %w", ErrInvalidState, err)
+			}
+			return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+		next, err := productFromDocument(*cur, out)
+		if err != nil {
+			return err
+		}
+		prev, cp, err = s.replace(ctx, next)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, events.ProductUpdated{Previous: prev, Product: cp})
+	return &cp, nil
+}
+
+// productDocument представляет товар JSON-документом, к которому применяется патч;
+// пустые списки и атрибуты — [] и {}, чтобы в них можно было добавлять по пути
+func productDocument(p domain.Product) (map[string]any, error) {
+	if p.CategoryIDs == nil {
+		p.CategoryIDs = []int64{}
+	}
+	if p.Barcodes == nil {
+		p.Barcodes = []string{}
+	}
+	if p.Attributes == nil {
+		p.Attributes = map[string]string{}
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// productFromDocument проверяет изменённый документ по полям и собирает из него товар
+func productFromDocument(prev domain.Product, doc any) (domain.Product, error) {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return domain.Product{}, fmt.Errorf("%w: product must be a JSON object", ErrInvalidInput)
+	}
+	orig, err := productDocument(prev)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	for _, field := range readOnlyProductFields {
+		if !reflect.DeepEqual(m[field], orig[field]) {
+			return domain.Product{}, invalidField(field, "is read-only")
+		}
+	}
+	for _, field := range requiredProductFields {
+		if m[field] == nil {
+			return domain.Product{}, invalidField(field, "is required")
+		}
+	}
+	for field := range m {
+		if _, known := orig[field]; !known && field != "archived_at" {
+			return domain.Product{}, invalidField(field, "unknown field")
+		}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	var next domain.Product
+	if err := json.Unmarshal(data, &next); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return domain.Product{}, invalidField(typeErr.Field, "must be %s", typeErr.Type)
+		}
+		return domain.Product{}, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	next.ID = prev.ID
+	return next, nil
+}
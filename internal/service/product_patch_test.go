@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/patch"
+	"april/internal/repository"
+)
+
+func TestProduct_Patch(t *testing.T) {
+	ctx := context.Background()
+	ps := setupPS(t)
+	p, _ := ps.Create(ctx, domain.Product{Name: "Aspirin", SKU: "S1", Price: 10, Stock: 5, Barcodes: []string{"4006381333931"}})
+
+	merge, _ := patch.ParseMerge([]byte(`{"price":12,"barcodes":null}`))
+	got, err := ps.Patch(ctx, p.ID, merge)
+	if err != nil {
+		t.Fatalf("merge patch: %v", err)
+	}
+	if got.Price != 12 || got.Name != "Aspirin" || got.SKU != "S1" || got.Stock != 5 || len(got.Barcodes) != 0 {
+		t.Fatalf("unexpected product after merge patch: %+v", got)
+	}
+
+	ops, _ := patch.ParseJSON([]byte(`[{"op":"add","path":"/barcodes/-","value":"96385074"},{"op":"copy","from":"/name","path":"/attributes"}]`))
+	if _, err := ps.Patch(ctx, p.ID, ops); err == nil {
+		t.Fatalf("expected type error for attributes")
+	}
+	ops, _ = patch.ParseJSON([]byte(`[{"op":"add","path":"/barcodes/-","value":"96385074"}]`))
+	if got, err = ps.Patch(ctx, p.ID, ops); err != nil || len(got.Barcodes) != 1 {
+		t.Fatalf("json patch: %+v %v", got, err)
+	}
+
+	for doc, field := range map[string]string{
+		`{"name":""}`:                           "name",
+		`{"stock":null}`:                        "stock",
+		`{"created_at":"2020-01-01T00:00:00Z"}`: "created_at",
+		`{"colour":"red"}`:                      "colour",
+		`{"barcodes":["12345"]}`:                "barcodes",
+	} {
+		mp, _ := patch.ParseMerge([]byte(doc))
+		_, err := ps.Patch(ctx, p.ID, mp)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != field {
+			t.Fatalf("%s: expected validation error for %s, got %v", doc, field, err)
+		}
+	}
+
+	failed, _ := patch.ParseJSON([]byte(`[{"op":"test","path":"/price","value":10},{"op":"replace","path":"/price","value":1}]`))
+	if _, err := ps.Patch(ctx, p.ID, failed); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected invalid state on failed test, got %v", err)
+	}
+	missing, _ := patch.ParseJSON([]byte(`[{"op":"remove","path":"/attributes/missing"}]`))
+	if _, err := ps.Patch(ctx, p.ID, missing); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input for missing path, got %v", err)
+	}
+	if _, err := ps.Patch(ctx, 99, merge); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if cur, _ := ps.GetByID(ctx, p.ID); cur.Price != 12 {
+		t.Fatalf("failed patch changed product: %+v", cur)
+	}
+}
+
+func TestProduct_Patch_InTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := &txCheckedProducts{ProductRepository: repository.NewMemoryStore()}
+	ps := NewProductService(repo, fakeTx{})
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 1, Stock: 5})
+	merge, _ := patch.ParseMerge([]byte(`{"name":"B"}`))
+	if _, err := ps.Patch(ctx, p.ID, merge); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if _, err := ps.Update(ctx, domain.Product{ID: p.ID, Name: "C", SKU: "A", Price: 1, Stock: 5}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if len(repo.outside) != 0 {
+		t.Fatalf("read-modify-write outside transaction: %v", repo.outside)
+	}
+}
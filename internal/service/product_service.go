@@ -3,30 +3,66 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
+	"sort"
+	"time"
 
 	"april/internal/domain"
+	"april/internal/events"
 	"april/internal/repository"
 )
 
 // ProductService инкапсулирует бизнес-логику вокруг товаров
 type ProductService struct {
-	repo repository.ProductRepository
+	repo       repository.ProductRepository
+	tx         repository.TxManager
+	categories repository.CategoryRepository
+	events     *events.Bus
+	search     Searcher
 }
 
-func NewProductService(repo repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+// Searcher полнотекстовый поиск товаров: id в порядке релевантности
+type Searcher interface {
+	Search(ctx context.Context, query string) ([]int64, error)
 }
 
+func NewProductService(repo repository.ProductRepository, tx repository.TxManager) *ProductService {
+	return &ProductService{repo: repo, tx: tx}
+}
+
+// SetEvents подключает шину событий; без неё события не публикуются
+func (s *ProductService) SetEvents(bus *events.Bus) { s.events = bus }
+
+// SetCategories подключает каталог: проверку категорий товара и фильтр по поддереву
+func (s *ProductService) SetCategories(categories repository.CategoryRepository) {
+	s.categories = categories
+}
+
+// SetSearch подключает полнотекстовый поиск для NameSubstring; без него — поиск подстроки
+func (s *ProductService) SetSearch(searcher Searcher) { s.search = searcher }
+
 var ErrInvalidInput = errors.New("invalid input")
 
 func (s *ProductService) Create(ctx context.Context, p domain.Product) (*domain.Product, error) {
-	if p.Name == "" || p.SKU == "" || p.Price < 0 || p.Stock < 0 {
-		return nil, ErrInvalidInput
+	if err := checkProduct(p); err != nil {
+		return nil, err
 	}
 	cp := p
+	ids, err := s.checkCategories(ctx, p.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	cp.CategoryIDs = ids
+	if cp.Attributes, err = s.checkAttributes(ctx, cp.CategoryIDs, p.Attributes); err != nil {
+		return nil, err
+	}
+	if cp.Barcodes, err = normalizeBarcodes(p.Barcodes); err != nil {
+		return nil, err
+	}
 	if err := s.repo.Create(ctx, &cp); err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, events.ProductCreated{Product: cp})
 	return &cp, nil
 }
 
@@ -37,24 +73,315 @@ func (s *ProductService) GetByID(ctx context.Context, id int64) (*domain.Product
 	return s.repo.GetByID(ctx, id)
 }
 
+// GetByBarcode ищет товар по штрихкоду любой длины (GTIN-8/12/13/14); код с неверной
+// контрольной цифрой — ErrInvalidInput
+func (s *ProductService) GetByBarcode(ctx context.Context, code string) (*domain.Product, error) {
+	code, ok := normalizeGTIN(code)
+	if !ok {
+		return nil, ErrInvalidInput
+	}
+	return s.repo.GetByBarcode(ctx, code)
+}
+
+// Update заменяет товар целиком; поля, которых нет в p, обнуляются
 func (s *ProductService) Update(ctx context.Context, p domain.Product) (*domain.Product, error) {
-	if p.ID <= 0 || p.Name == "" || p.Price < 0 || p.Stock < 0 {
+	if p.ID <= 0 {
 		return nil, ErrInvalidInput
 	}
+	var prev, cp domain.Product
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		prev, cp, err = s.replace(ctx, p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, events.ProductUpdated{Previous: prev, Product: cp})
+	return &cp, nil
+}
+
+// replace проверяет p и сохраняет его вместо товара p.ID; возвращает товар до и
+// после замены. Вызывать в транзакции.
+func (s *ProductService) replace(ctx context.Context, p domain.Product) (domain.Product, domain.Product, error) {
+	if err := checkProduct(p); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+	prev, err := s.repo.GetByID(ctx, p.ID)
+	if err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
 	cp := p
+	cp.ArchivedAt = prev.ArchivedAt
+	if cp.CategoryIDs, err = s.checkCategories(ctx, p.CategoryIDs); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+	if cp.Attributes, err = s.checkAttributes(ctx, cp.CategoryIDs, p.Attributes); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+	if cp.Barcodes, err = normalizeBarcodes(p.Barcodes); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
 	if err := s.repo.Update(ctx, &cp); err != nil {
+		return domain.Product{}, domain.Product{}, err
+	}
+	return *prev, cp, nil
+}
+
+// Archive скрывает товар из каталога и продажи; заказы по-прежнему получают его по id
+func (s *ProductService) Archive(ctx context.Context, id int64) (*domain.Product, error) {
+	return s.setArchived(ctx, id, true)
+}
+
+// Restore возвращает архивный товар в каталог
+func (s *ProductService) Restore(ctx context.Context, id int64) (*domain.Product, error) {
+	return s.setArchived(ctx, id, false)
+}
+
+// setArchived меняет отметку архива в транзакции, чтобы не затереть запас,
+// изменённый заказом или приёмкой между чтением и записью
+func (s *ProductService) setArchived(ctx context.Context, id int64, archived bool) (*domain.Product, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var prev, cp domain.Product
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		p, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if (p.ArchivedAt != nil) == archived {
+			return ErrInvalidState
+		}
+		prev, cp = *p, *p
+		cp.ArchivedAt = nil
+		if archived {
+			now := time.Now().UTC()
+			cp.ArchivedAt = &now
+		}
+		return s.repo.Update(ctx, &cp)
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.events.Publish(ctx, events.ProductUpdated{Previous: prev, Product: cp})
 	return &cp, nil
 }
 
-func (s *ProductService) Delete(ctx context.Context, id int64) error {
+// Purge окончательно удаляет архивный товар. Пока на него ссылаются заказы,
+// поставки, инвентаризации или маркировка, репозиторий возвращает ErrConflict.
+func (s *ProductService) Purge(ctx context.Context, id int64) error {
 	if id <= 0 {
 		return ErrInvalidInput
 	}
-	return s.repo.Delete(ctx, id)
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		p, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if p.ArchivedAt == nil {
+			return ErrInvalidState
+		}
+		return s.repo.Delete(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, events.ProductDeleted{ProductID: id})
+	return nil
 }
 
+// List возвращает товары по фильтру; без явного Archived — только активные
 func (s *ProductService) List(ctx context.Context, f repository.ProductFilter) ([]domain.Product, error) {
-	return s.repo.List(ctx, f)
+	if err := checkFilter(f); err != nil {
+		return nil, err
+	}
+	if f.Archived == nil {
+		active := false
+		f.Archived = &active
+	}
+	f, rank, err := s.resolveFilter(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if rank != nil {
+		sort.Slice(list, func(i, j int) bool { return rank[list[i].ID] < rank[list[j].ID] })
+	}
+	return list, nil
+}
+
+// DefaultPriceEdges границы ценовых диапазонов фасета по умолчанию
+var DefaultPriceEdges = []float64{100, 500, 1000, 5000}
+
+// ListWithFacets возвращает выборку и агрегаты по ней: ценовые диапазоны (границы
+// priceEdges, по умолчанию DefaultPriceEdges), наличие, категории с учётом подкатегорий
+// и производители
+func (s *ProductService) ListWithFacets(ctx context.Context, f repository.ProductFilter, priceEdges []float64) ([]domain.Product, *domain.ProductFacets, error) {
+	list, err := s.List(ctx, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(priceEdges) == 0 {
+		priceEdges = DefaultPriceEdges
+	}
+	opts := repository.FacetOptions{PriceEdges: priceEdges}
+	if s.categories != nil {
+		all, err := s.categories.List(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.CategoryAncestors = ancestors(all)
+	}
+	// фасеты по тем же товарам, что попали в выборку (в том числе найденным поиском)
+	ids := make([]int64, 0, len(list))
+	for _, p := range list {
+		ids = append(ids, p.ID)
+	}
+	facets, err := s.repo.Facets(ctx, repository.ProductFilter{IDs: ids}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return list, facets, nil
+}
+
+// checkFilter отклоняет отрицательные и противоречивые условия выборки
+func checkFilter(f repository.ProductFilter) error {
+	switch {
+	case f.MinPrice != nil && *f.MinPrice < 0:
+		return invalidField("min_price", "must not be negative")
+	case f.MaxPrice != nil && *f.MaxPrice < 0:
+		return invalidField("max_price", "must not be negative")
+	case f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice:
+		return invalidField("min_price", "must not exceed max_price")
+	case f.MinStock != nil && *f.MinStock < 0:
+		return invalidField("min_stock", "must not be negative")
+	case f.MinStock != nil && *f.MinStock > 0 && f.InStock != nil && !*f.InStock:
+		return invalidField("min_stock", "contradicts in_stock=false")
+	case f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo):
+		return invalidField("created_from", "must not be after created_to")
+	case f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo):
+		return invalidField("updated_from", "must not be after updated_to")
+	}
+	for _, id := range f.IDs {
+		if id <= 0 {
+			return invalidField("ids", "must be positive")
+		}
+	}
+	return nil
+}
+
+// resolveFilter раскрывает категорию в поддерево и заменяет текстовый запрос на id
+// найденных поиском товаров; rank — их порядок по релевантности (nil без поиска)
+func (s *ProductService) resolveFilter(ctx context.Context, f repository.ProductFilter) (repository.ProductFilter, map[int64]int, error) {
+	if f.CategoryID != 0 {
+		if s.categories == nil {
+			return f, nil, ErrInvalidInput
+		}
+		if _, err := s.categories.GetByID(ctx, f.CategoryID); err != nil {
+			return f, nil, err
+		}
+		all, err := s.categories.List(ctx)
+		if err != nil {
+			return f, nil, err
+		}
+		f.CategoryIDs = subtree(all, f.CategoryID)
+	}
+	if f.NameSubstring == "" || s.search == nil {
+		return f, nil, nil
+	}
+	ids, err := s.search.Search(ctx, f.NameSubstring)
+	if err != nil {
+		return f, nil, err
+	}
+	if f.IDs != nil {
+		ids = slices.DeleteFunc(ids, func(id int64) bool { return !slices.Contains(f.IDs, id) })
+	}
+	rank := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		rank[id] = i
+	}
+	// пустой, но не nil список: ничего не найдено, а не «без ограничения»
+	f.NameSubstring, f.IDs = "", append([]int64{}, ids...)
+	return f, rank, nil
+}
+
+// Substitutes возвращает аналоги товара в наличии (то же МНН и дозировка), дешёвые первыми
+func (s *ProductService) Substitutes(ctx context.Context, id int64) ([]domain.Product, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return findSubstitutes(ctx, s.repo, *p, 1)
+}
+
+// checkProduct проверяет обязательные поля и неотрицательность чисел
+func checkProduct(p domain.Product) error {
+	switch {
+	case p.Name == "":
+		return invalidField("name", "is required")
+	case p.SKU == "":
+		return invalidField("sku", "is required")
+	case p.Price < 0:
+		return invalidField("price", "must not be negative")
+	case p.Stock < 0:
+		return invalidField("stock", "must not be negative")
+	case p.ReorderPoint < 0:
+		return invalidField("reorder_point", "must not be negative")
+	case p.ReorderQty < 0:
+		return invalidField("reorder_quantity", "must not be negative")
+	case p.TaxRate < 0 || p.TaxRate >= 100:
+		return invalidField("tax_rate", "must be in [0, 100)")
+	}
+	return nil
+}
+
+// checkCategories убирает дубли и проверяет, что категории существуют
+func (s *ProductService) checkCategories(ctx context.Context, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.categories == nil {
+		return nil, ErrInvalidInput
+	}
+	out := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, ErrInvalidInput
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.categories.GetByID(ctx, id); err != nil {
+			if err == repository.ErrNotFound {
+				return nil, ErrInvalidInput
+			}
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// checkAttributes проверяет атрибуты по схеме категорий товара (с учётом предков)
+func (s *ProductService) checkAttributes(ctx context.Context, categoryIDs []int64, values map[string]string) (map[string]string, error) {
+	if s.categories == nil {
+		if len(values) > 0 {
+			return nil, invalidField("attributes", "catalogue is not configured")
+		}
+		return nil, nil
+	}
+	all, err := s.categories.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeAttributes(effectiveSchema(all, categoryIDs), values)
 }
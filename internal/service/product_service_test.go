@@ -2,16 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"april/internal/domain"
+	"april/internal/events"
 	"april/internal/repository"
 )
 
 func setupPS(t *testing.T) *ProductService {
 	t.Helper()
 	store := repository.NewMemoryStore()
-	return NewProductService(store)
+	return NewProductService(store, repository.NewMemoryTx(store))
 }
 
 func TestProduct_Create_Valid(t *testing.T) {
@@ -66,19 +69,47 @@ func TestProduct_Update_Get_Delete(t *testing.T) {
 		t.Fatalf("not updated")
 	}
 
-	// delete
-	if err := ps.Delete(ctx, p.ID); err != nil {
-		t.Fatalf("delete err: %v", err)
+	// archive: hidden from listing, still resolvable by id
+	if err := ps.Purge(ctx, p.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state purging active product, got %v", err)
+	}
+	if _, err := ps.Archive(ctx, p.ID); err != nil {
+		t.Fatalf("archive err: %v", err)
+	}
+	if got, err := ps.GetByID(ctx, p.ID); err != nil || got.ArchivedAt == nil {
+		t.Fatalf("archived product not resolvable: %+v %v", got, err)
+	}
+	if list, _ := ps.List(ctx, repository.ProductFilter{}); len(list) != 0 {
+		t.Fatalf("archived product listed: %+v", list)
+	}
+	up.Name = "A++"
+	if up, err = ps.Update(ctx, *up); err != nil || up.ArchivedAt == nil {
+		t.Fatalf("update dropped archived_at: %+v %v", up, err)
+	}
+	if _, err := ps.Archive(ctx, p.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state archiving twice, got %v", err)
+	}
+	if got, err := ps.Restore(ctx, p.ID); err != nil || got.ArchivedAt != nil {
+		t.Fatalf("restore: %+v %v", got, err)
+	}
+	if list, _ := ps.List(ctx, repository.ProductFilter{}); len(list) != 1 {
+		t.Fatalf("restored product not listed: %+v", list)
+	}
+
+	// purge
+	_, _ = ps.Archive(ctx, p.ID)
+	if err := ps.Purge(ctx, p.ID); err != nil {
+		t.Fatalf("purge err: %v", err)
 	}
 	if _, err := ps.GetByID(ctx, p.ID); err == nil {
-		t.Fatalf("expected not found after delete")
+		t.Fatalf("expected not found after purge")
 	}
 }
 
 func TestProduct_List_Filtering(t *testing.T) {
 	ctx := context.Background()
 	store := repository.NewMemoryStore()
-	ps := NewProductService(store)
+	ps := NewProductService(store, repository.NewMemoryTx(store))
 	must := func(p *domain.Product, err error) *domain.Product {
 		if err != nil {
 			t.Fatal(err)
@@ -122,3 +153,120 @@ func TestProduct_List_Filtering(t *testing.T) {
 		}
 	}
 }
+
+func TestProduct_List_RejectsContradictoryFilter(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	lo, hi, neg := 200.0, 100.0, int64(-1)
+	one, no := int64(1), false
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	cases := []struct {
+		field string
+		f     repository.ProductFilter
+	}{
+		{"min_price", repository.ProductFilter{MinPrice: &lo, MaxPrice: &hi}},
+		{"min_stock", repository.ProductFilter{MinStock: &neg}},
+		{"min_stock", repository.ProductFilter{MinStock: &one, InStock: &no}},
+		{"created_from", repository.ProductFilter{CreatedFrom: &now, CreatedTo: &earlier}},
+		{"ids", repository.ProductFilter{IDs: []int64{0}}},
+	}
+	for _, tc := range cases {
+		_, err := ps.List(context.Background(), tc.f)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != tc.field {
+			t.Fatalf("%s: expected validation error, got %v", tc.field, err)
+		}
+	}
+}
+
+func TestProduct_PublishesEvents(t *testing.T) {
+	ctx := context.Background()
+	ps := setupPS(t)
+	bus := events.NewBus()
+	ps.SetEvents(bus)
+	var upd events.ProductUpdated
+	var deleted int64
+	events.On(bus, func(ctx context.Context, e events.ProductUpdated) { upd = e })
+	events.On(bus, func(ctx context.Context, e events.ProductDeleted) { deleted = e.ProductID })
+
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 5})
+	p.Price = 15
+	if _, err := ps.Update(ctx, *p); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if upd.Previous.Price != 10 || upd.Product.Price != 15 {
+		t.Fatalf("unexpected update event: %+v", upd)
+	}
+	if _, err := ps.Archive(ctx, p.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if upd.Product.ArchivedAt == nil {
+		t.Fatalf("expected update event on archive: %+v", upd)
+	}
+	if err := ps.Purge(ctx, p.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if deleted != p.ID {
+		t.Fatalf("expected delete event for %v, got %v", p.ID, deleted)
+	}
+}
+
+type fakeTxKey struct{}
+
+// fakeTx помечает контекст транзакции, чтобы txCheckedProducts видел, что вызов идёт внутри неё
+type fakeTx struct{}
+
+func (fakeTx) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, fakeTxKey{}, true))
+}
+
+// txCheckedProducts запоминает чтения и записи товаров вне транзакции
+type txCheckedProducts struct {
+	repository.ProductRepository
+	outside []string
+}
+
+func (r *txCheckedProducts) check(ctx context.Context, op string) {
+	if ctx.Value(fakeTxKey{}) == nil {
+		r.outside = append(r.outside, op)
+	}
+}
+
+func (r *txCheckedProducts) GetByID(ctx context.Context, id int64) (*domain.Product, error) {
+	r.check(ctx, "get")
+	return r.ProductRepository.GetByID(ctx, id)
+}
+
+func (r *txCheckedProducts) Update(ctx context.Context, p *domain.Product) error {
+	r.check(ctx, "update")
+	return r.ProductRepository.Update(ctx, p)
+}
+
+func (r *txCheckedProducts) Delete(ctx context.Context, id int64) error {
+	r.check(ctx, "delete")
+	return r.ProductRepository.Delete(ctx, id)
+}
+
+func TestProduct_ArchiveRestorePurge_InTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := &txCheckedProducts{ProductRepository: repository.NewMemoryStore()}
+	ps := NewProductService(repo, fakeTx{})
+	p, err := ps.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 1, Stock: 5})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := ps.Archive(ctx, p.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if _, err := ps.Restore(ctx, p.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	_, _ = ps.Archive(ctx, p.ID)
+	if err := ps.Purge(ctx, p.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if len(repo.outside) != 0 {
+		t.Fatalf("read-modify-write outside transaction: %v", repo.outside)
+	}
+}
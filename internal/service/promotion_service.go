@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+// PromotionService ведёт акции и рассчитывает по ним скидки строк заказа
+type PromotionService struct {
+	promotions repository.PromotionRepository
+	products   repository.ProductRepository
+	categories repository.CategoryRepository
+}
+
+func NewPromotionService(promotions repository.PromotionRepository, products repository.ProductRepository) *PromotionService {
+	return &PromotionService{promotions: promotions, products: products}
+}
+
+// SetCategories подключает каталог для акций на категории; без него такие акции не создаются
+func (s *PromotionService) SetCategories(categories repository.CategoryRepository) {
+	s.categories = categories
+}
+
+func (s *PromotionService) Create(ctx context.Context, p domain.Promotion) (*domain.Promotion, error) {
+	cp, err := s.checkPromotion(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.promotions.Create(ctx, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s *PromotionService) Get(ctx context.Context, id int64) (*domain.Promotion, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.promotions.GetByID(ctx, id)
+}
+
+func (s *PromotionService) Update(ctx context.Context, p domain.Promotion) (*domain.Promotion, error) {
+	if p.ID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	cp, err := s.checkPromotion(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.promotions.Update(ctx, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// Delete удаляет акцию; в созданных заказах остаются её название и суммы скидок
+func (s *PromotionService) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrInvalidInput
+	}
+	return s.promotions.Delete(ctx, id)
+}
+
+func (s *PromotionService) List(ctx context.Context) ([]domain.Promotion, error) {
+	return s.promotions.List(ctx)
+}
+
+// checkPromotion проверяет параметры вида акции, область действия и период
+func (s *PromotionService) checkPromotion(ctx context.Context, p domain.Promotion) (domain.Promotion, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Code = normalizePromoCode(p.Code)
+	if p.Name == "" {
+		return p, invalidField("name", "is required")
+	}
+	switch p.Kind {
+	case domain.PromotionKindPercent:
+		if p.Percent <= 0 || p.Percent > 100 {
+			return p, invalidField("percent", "must be in (0, 100]")
+		}
+	case domain.PromotionKindFixed:
+		if p.Amount <= 0 {
+			return p, invalidField("amount", "must be positive")
+		}
+	case domain.PromotionKindBuyGet:
+		if p.BuyQty <= 0 || p.FreeQty <= 0 {
+			return p, invalidField("buy_qty", "buy_qty and free_qty must be positive")
+		}
+	default:
+		return p, invalidField("kind", "unknown kind %q", p.Kind)
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.StartsAt.Before(*p.EndsAt) {
+		return p, invalidField("ends_at", "must be after starts_at")
+	}
+	for _, id := range p.ProductIDs {
+		if _, err := s.products.GetByID(ctx, id); err != nil {
+			return p, invalidField("product_ids", "unknown product %d", id)
+		}
+	}
+	if len(p.CategoryIDs) > 0 && s.categories == nil {
+		return p, invalidField("category_ids", "catalogue is not configured")
+	}
+	for _, id := range p.CategoryIDs {
+		if _, err := s.categories.GetByID(ctx, id); err != nil {
+			return p, invalidField("category_ids", "unknown category %d", id)
+		}
+	}
+	return p, nil
+}
+
+// normalizePromoCode приводит промокод к верхнему регистру без пробелов по краям
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// normalizePromoCodes нормализует промокоды, убирая пустые и повторы
+func normalizePromoCodes(codes []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		c = normalizePromoCode(c)
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// ApplyDiscounts рассчитывает скидки строк с заполненной UnitPrice. Действуют
+// автоматические акции и акции по переданным промокодам; по убыванию приоритета
+// каждая следующая считается от остатка суммы строки. Неизвестный или недействующий
+// промокод — ошибка поля promo_codes.
+func (s *PromotionService) ApplyDiscounts(ctx context.Context, items []domain.OrderItem, products map[int64]domain.Product, codes []string) error {
+	now := time.Now()
+	all, err := s.promotions.List(ctx)
+	if err != nil {
+		return err
+	}
+	codes = normalizePromoCodes(codes)
+	requested := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		requested[c] = false
+	}
+	eligible := make([]domain.Promotion, 0, len(all))
+	for _, p := range all {
+		if p.Code != "" {
+			if _, ok := requested[p.Code]; !ok {
+				continue
+			}
+			requested[p.Code] = true
+			if !p.ActiveAt(now) {
+				return invalidField("promo_codes", "code %q is not active", p.Code)
+			}
+		} else if !p.ActiveAt(now) {
+			continue
+		}
+		eligible = append(eligible, p)
+	}
+	for _, code := range codes {
+		if !requested[code] {
+			return invalidField("promo_codes", "unknown code %q", code)
+		}
+	}
+	sort.SliceStable(eligible, func(i, j int) bool { return eligible[i].Priority > eligible[j].Priority })
+	scopes, err := s.categoryScopes(ctx, eligible)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		it := &items[i]
+		it.Discount, it.Discounts = 0, nil
+		p := products[it.ProductID]
+		for _, promo := range eligible {
+			if !promotionCovers(promo, p, scopes[promo.ID]) {
+				continue
+			}
+			if promo.Exclusive && len(it.Discounts) > 0 {
+				continue
+			}
+			remaining := roundMoney(it.UnitPrice*float64(it.Quantity) - it.Discount)
+			amount := min(promotionAmount(promo, *it), remaining)
+			if amount <= 0 {
+				continue
+			}
+			it.Discounts = append(it.Discounts, domain.LineDiscount{PromotionID: promo.ID, Name: promo.Name, Amount: amount})
+			it.Discount = roundMoney(it.Discount + amount)
+			if promo.Exclusive {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// categoryScopes раскрывает категории акций в поддеревья
+func (s *PromotionService) categoryScopes(ctx context.Context, promos []domain.Promotion) (map[int64]map[int64]bool, error) {
+	scopes := make(map[int64]map[int64]bool)
+	var all []domain.Category
+	for _, p := range promos {
+		if len(p.CategoryIDs) == 0 || s.categories == nil {
+			continue
+		}
+		if all == nil {
+			var err error
+			if all, err = s.categories.List(ctx); err != nil {
+				return nil, err
+			}
+		}
+		set := make(map[int64]bool)
+		for _, id := range p.CategoryIDs {
+			for _, c := range subtree(all, id) {
+				set[c] = true
+			}
+		}
+		scopes[p.ID] = set
+	}
+	return scopes, nil
+}
+
+// promotionCovers проверяет, распространяется ли акция на товар
+func promotionCovers(promo domain.Promotion, p domain.Product, categories map[int64]bool) bool {
+	if len(promo.ProductIDs) == 0 && len(promo.CategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range promo.ProductIDs {
+		if id == p.ID {
+			return true
+		}
+	}
+	for _, id := range p.CategoryIDs {
+		if categories[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// promotionAmount скидка акции на строку без учёта других акций
+func promotionAmount(promo domain.Promotion, it domain.OrderItem) float64 {
+	switch promo.Kind {
+	case domain.PromotionKindPercent:
+		return roundMoney((it.UnitPrice*float64(it.Quantity) - it.Discount) * promo.Percent / 100)
+	case domain.PromotionKindFixed:
+		return roundMoney(promo.Amount * float64(it.Quantity))
+	case domain.PromotionKindBuyGet:
+		free := it.Quantity / (promo.BuyQty + promo.FreeQty) * promo.FreeQty
+		return roundMoney(it.UnitPrice * float64(free))
+	}
+	return 0
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+type promoFixture struct {
+	products   *ProductService
+	categories *CategoryService
+	promotions *PromotionService
+	orders     *OrderService
+}
+
+func setupPromotions(t *testing.T) promoFixture {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	categories := repository.NewMemoryCategories(store)
+	ps := NewProductService(store, tx)
+	ps.SetCategories(categories)
+	promos := NewPromotionService(repository.NewMemoryPromotions(store), store)
+	promos.SetCategories(categories)
+	os := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	os.SetDiscounts(promos)
+	return promoFixture{ps, NewCategoryService(categories, store, tx), promos, os}
+}
+
+func (f promoFixture) promotion(t *testing.T, p domain.Promotion) *domain.Promotion {
+	t.Helper()
+	out, err := f.promotions.Create(context.Background(), p)
+	if err != nil {
+		t.Fatalf("create promotion %s: %v", p.Name, err)
+	}
+	return out
+}
+
+func TestPromotion_Validation(t *testing.T) {
+	ctx := context.Background()
+	f := setupPromotions(t)
+	now := time.Now()
+	later := now.Add(-time.Hour)
+	cases := []domain.Promotion{
+		{Kind: domain.PromotionKindPercent, Percent: 10},
+		{Name: "p", Kind: domain.PromotionKindPercent, Percent: 120},
+		{Name: "f", Kind: domain.PromotionKindFixed},
+		{Name: "b", Kind: domain.PromotionKindBuyGet, BuyQty: 2},
+		{Name: "k", Kind: "gift"},
+		{Name: "d", Kind: domain.PromotionKindPercent, Percent: 5, StartsAt: &now, EndsAt: &later},
+		{Name: "x", Kind: domain.PromotionKindPercent, Percent: 5, ProductIDs: []int64{99}},
+		{Name: "c", Kind: domain.PromotionKindPercent, Percent: 5, CategoryIDs: []int64{99}},
+	}
+	for _, c := range cases {
+		if _, err := f.promotions.Create(ctx, c); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("expected invalid input for %+v, got %v", c, err)
+		}
+	}
+	f.promotion(t, domain.Promotion{Name: "a", Kind: domain.PromotionKindPercent, Percent: 5, Code: " spring "})
+	if _, err := f.promotions.Create(ctx, domain.Promotion{Name: "b", Kind: domain.PromotionKindPercent, Percent: 5, Code: "SPRING"}); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected taken code conflict, got %v", err)
+	}
+}
+
+func TestPromotion_KindsAndScopes(t *testing.T) {
+	ctx := context.Background()
+	f := setupPromotions(t)
+	root, _ := f.categories.Create(ctx, domain.Category{Name: "Meds"})
+	child, _ := f.categories.Create(ctx, domain.Category{Name: "Pain", ParentID: root.ID})
+	pill, _ := f.products.Create(ctx, domain.Product{Name: "Pill", SKU: "P", Price: 100, Stock: 10, CategoryIDs: []int64{child.ID}})
+	cream, _ := f.products.Create(ctx, domain.Product{Name: "Cream", SKU: "C", Price: 50, Stock: 10})
+	soap, _ := f.products.Create(ctx, domain.Product{Name: "Soap", SKU: "S", Price: 20, Stock: 10})
+
+	f.promotion(t, domain.Promotion{Name: "meds -10%", Kind: domain.PromotionKindPercent, Percent: 10, CategoryIDs: []int64{root.ID}})
+	f.promotion(t, domain.Promotion{Name: "cream -5", Kind: domain.PromotionKindFixed, Amount: 5, ProductIDs: []int64{cream.ID}})
+	f.promotion(t, domain.Promotion{Name: "soap 2+1", Kind: domain.PromotionKindBuyGet, BuyQty: 2, FreeQty: 1, ProductIDs: []int64{soap.ID}})
+
+	o, err := f.orders.CreateOrder(ctx, "c", []domain.OrderItem{
+		{ProductID: pill.ID, Quantity: 2},
+		{ProductID: cream.ID, Quantity: 3},
+		{ProductID: soap.ID, Quantity: 7},
+	})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	want := []float64{20, 15, 40}
+	for i, it := range o.Items {
+		if it.Discount != want[i] || len(it.Discounts) != 1 {
+			t.Fatalf("line %d: expected discount %v, got %+v", i, want[i], it)
+		}
+	}
+	if o.Subtotal != 490 || o.Discount != 75 || o.Total != 415 {
+		t.Fatalf("unexpected totals %+v", o)
+	}
+}
+
+func TestPromotion_PriorityStackingAndExclusive(t *testing.T) {
+	ctx := context.Background()
+	f := setupPromotions(t)
+	a, _ := f.products.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 100, Stock: 10})
+	b, _ := f.products.Create(ctx, domain.Product{Name: "B", SKU: "B", Price: 100, Stock: 10})
+
+	f.promotion(t, domain.Promotion{Name: "all -10%", Kind: domain.PromotionKindPercent, Percent: 10, Priority: 1})
+	f.promotion(t, domain.Promotion{Name: "A -20", Kind: domain.PromotionKindFixed, Amount: 20, Priority: 5, ProductIDs: []int64{a.ID}})
+	f.promotion(t, domain.Promotion{Name: "B half", Kind: domain.PromotionKindPercent, Percent: 50, Priority: 3, Exclusive: true, ProductIDs: []int64{b.ID}})
+
+	o, err := f.orders.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: a.ID, Quantity: 1}, {ProductID: b.ID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	// A: сначала -20 по приоритету, затем 10% от остатка 80
+	if it := o.Items[0]; it.Discount != 28 || len(it.Discounts) != 2 || it.Discounts[0].Name != "A -20" {
+		t.Fatalf("unexpected stacking %+v", it)
+	}
+	// B: исключительная акция закрывает строку для общей скидки
+	if it := o.Items[1]; it.Discount != 50 || len(it.Discounts) != 1 {
+		t.Fatalf("unexpected exclusive %+v", it)
+	}
+}
+
+func TestPromotion_Codes(t *testing.T) {
+	ctx := context.Background()
+	f := setupPromotions(t)
+	p, _ := f.products.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 100, Stock: 10})
+	past := time.Now().Add(-time.Hour)
+	f.promotion(t, domain.Promotion{Name: "spring", Kind: domain.PromotionKindPercent, Percent: 15, Code: "spring"})
+	f.promotion(t, domain.Promotion{Name: "old", Kind: domain.PromotionKindPercent, Percent: 15, Code: "old", EndsAt: &past})
+	items := func() []domain.OrderItem { return []domain.OrderItem{{ProductID: p.ID, Quantity: 1}} }
+
+	o, err := f.orders.CreateOrder(ctx, "c", items())
+	if err != nil || o.Discount != 0 {
+		t.Fatalf("coded promotion must not apply without code: %+v %v", o, err)
+	}
+	o, err = f.orders.CreateOrder(ctx, "c", items(), WithPromoCodes(" Spring"))
+	if err != nil || o.Discount != 15 || len(o.PromoCodes) != 1 || o.PromoCodes[0] != "SPRING" {
+		t.Fatalf("expected code applied: %+v %v", o, err)
+	}
+	for _, code := range []string{"nope", "old"} {
+		var ve *ValidationError
+		if _, err := f.orders.CreateOrder(ctx, "c", items(), WithPromoCodes(code)); !errors.As(err, &ve) || ve.Field != "promo_codes" {
+			t.Fatalf("expected promo_codes error for %q, got %v", code, err)
+		}
+	}
+	got, _ := f.products.GetByID(ctx, p.ID)
+	if got.Stock != 8 {
+		t.Fatalf("rejected orders must not reserve stock, got %d", got.Stock)
+	}
+}
+
+func TestPromotion_PartialReturnProRata(t *testing.T) {
+	ctx := context.Background()
+	f := setupPromotions(t)
+	p, _ := f.products.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 100, Stock: 10})
+	f.promotion(t, domain.Promotion{Name: "-10%", Kind: domain.PromotionKindPercent, Percent: 10})
+	o, err := f.orders.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: p.ID, Quantity: 4}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	o, _, err = f.orders.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("partial return: %v", err)
+	}
+	it := o.Items[0]
+	if it.Quantity != 3 || it.Discount != 30 || it.Discounts[0].Amount != 30 {
+		t.Fatalf("expected pro-rata discount, got %+v", it)
+	}
+	if o.Subtotal != 300 || o.Discount != 30 || o.Total != 270 {
+		t.Fatalf("unexpected totals %+v", o)
+	}
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// PurchaseService реализует входящую сторону запаса: поставщики,
+// заказы поставщикам и приёмка товара с увеличением остатков
+type PurchaseService struct {
+	products  repository.ProductRepository
+	suppliers repository.SupplierRepository
+	purchases repository.PurchaseOrderRepository
+	tx        repository.TxManager
+	events    *events.Bus
+	batches   repository.BatchRepository
+	serials   repository.SerialRepository
+}
+
+func NewPurchaseService(products repository.ProductRepository, suppliers repository.SupplierRepository, purchases repository.PurchaseOrderRepository, tx repository.TxManager) *PurchaseService {
+	return &PurchaseService{products: products, suppliers: suppliers, purchases: purchases, tx: tx}
+}
+
+// SetEvents подключает шину событий; события публикуются после фиксации транзакции
+func (s *PurchaseService) SetEvents(bus *events.Bus) { s.events = bus }
+
+// SetSerials подключает учёт маркированных упаковок: при приёмке такого товара
+// обязательны номер серии и коды упаковок
+func (s *PurchaseService) SetSerials(batches repository.BatchRepository, serials repository.SerialRepository) {
+	s.batches = batches
+	s.serials = serials
+}
+
+func (s *PurchaseService) CreateSupplier(ctx context.Context, sup domain.Supplier) (*domain.Supplier, error) {
+	if sup.Name == "" {
+		return nil, ErrInvalidInput
+	}
+	cp := sup
+	if err := s.suppliers.Create(ctx, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s *PurchaseService) GetSupplier(ctx context.Context, id int64) (*domain.Supplier, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.suppliers.GetByID(ctx, id)
+}
+
+func (s *PurchaseService) UpdateSupplier(ctx context.Context, sup domain.Supplier) (*domain.Supplier, error) {
+	if sup.ID <= 0 || sup.Name == "" {
+		return nil, ErrInvalidInput
+	}
+	cur, err := s.suppliers.GetByID(ctx, sup.ID)
+	if err != nil {
+		return nil, err
+	}
+	cur.Name = sup.Name
+	cur.Contact = sup.Contact
+	if err := s.suppliers.Update(ctx, cur); err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (s *PurchaseService) ListSuppliers(ctx context.Context) ([]domain.Supplier, error) {
+	return s.suppliers.List(ctx)
+}
+
+// CreatePurchaseOrder создаёт черновик заказа поставщику
+func (s *PurchaseService) CreatePurchaseOrder(ctx context.Context, supplierID int64, lines []domain.PurchaseOrderLine) (*domain.PurchaseOrder, error) {
+	if supplierID <= 0 || len(lines) == 0 {
+		return nil, ErrInvalidInput
+	}
+	seen := make(map[int64]bool, len(lines))
+	for _, l := range lines {
+		if l.ProductID <= 0 || l.Quantity <= 0 || l.UnitCost < 0 || seen[l.ProductID] {
+			return nil, ErrInvalidInput
+		}
+		seen[l.ProductID] = true
+	}
+	var created *domain.PurchaseOrder
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.suppliers.GetByID(ctx, supplierID); err != nil {
+			return err
+		}
+		po := domain.PurchaseOrder{SupplierID: supplierID, Status: domain.PurchaseOrderStatusDraft}
+		for _, l := range lines {
+			if _, err := s.products.GetByID(ctx, l.ProductID); err != nil {
+				return err
+			}
+			l.Received = 0
+			po.Lines = append(po.Lines, l)
+		}
+		if err := s.purchases.Create(ctx, &po); err != nil {
+			return err
+		}
+		created = &po
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *PurchaseService) GetPurchaseOrder(ctx context.Context, id int64) (*domain.PurchaseOrder, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.purchases.GetByID(ctx, id)
+}
+
+func (s *PurchaseService) ListPurchaseOrders(ctx context.Context, f repository.PurchaseOrderFilter) ([]domain.PurchaseOrder, error) {
+	return s.purchases.List(ctx, f)
+}
+
+// SendPurchaseOrder переводит черновик в статус Sent
+func (s *PurchaseService) SendPurchaseOrder(ctx context.Context, id int64) (*domain.PurchaseOrder, error) {
+	return s.transition(ctx, id, domain.PurchaseOrderStatusSent, domain.PurchaseOrderStatusDraft)
+}
+
+// CancelPurchaseOrder отменяет заказ; уже принятый товар остаётся на складе
+func (s *PurchaseService) CancelPurchaseOrder(ctx context.Context, id int64) (*domain.PurchaseOrder, error) {
+	return s.transition(ctx, id, domain.PurchaseOrderStatusCancelled,
+		domain.PurchaseOrderStatusDraft, domain.PurchaseOrderStatusSent, domain.PurchaseOrderStatusPartiallyReceived)
+}
+
+func (s *PurchaseService) transition(ctx context.Context, id int64, to domain.PurchaseOrderStatus, from ...domain.PurchaseOrderStatus) (*domain.PurchaseOrder, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var updated *domain.PurchaseOrder
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		po, err := s.purchases.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		allowed := false
+		for _, st := range from {
+			if po.Status == st {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return ErrInvalidState
+		}
+		po.Status = to
+		if err := s.purchases.Update(ctx, po); err != nil {
+			return err
+		}
+		updated = po
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ReceiveGoods принимает товар по отправленному заказу и атомарно увеличивает запас.
+// Принимать можно частями, но не больше ожидаемого по строке количества.
+func (s *PurchaseService) ReceiveGoods(ctx context.Context, id int64, receipt []domain.ReceiptLine) (*domain.PurchaseOrder, error) {
+	if id <= 0 || len(receipt) == 0 {
+		return nil, ErrInvalidInput
+	}
+	for _, r := range receipt {
+		if r.ProductID <= 0 || r.Quantity <= 0 {
+			return nil, ErrInvalidInput
+		}
+	}
+	var updated *domain.PurchaseOrder
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		po, err := s.purchases.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if po.Status != domain.PurchaseOrderStatusSent && po.Status != domain.PurchaseOrderStatusPartiallyReceived {
+			return ErrInvalidState
+		}
+		lineByProduct := make(map[int64]int, len(po.Lines))
+		for i, l := range po.Lines {
+			lineByProduct[l.ProductID] = i
+		}
+		// validate before touching stock
+		incoming := make(map[int64]int64, len(receipt))
+		for _, r := range receipt {
+			i, ok := lineByProduct[r.ProductID]
+			if !ok {
+				return ErrInvalidInput
+			}
+			incoming[r.ProductID] += r.Quantity
+			if incoming[r.ProductID] > po.Lines[i].Outstanding() {
+				return ErrInvalidInput
+			}
+		}
+		serialized, err := s.checkReceiptSerials(ctx, receipt)
+		if err != nil {
+			return err
+		}
+		for productID, qty := range incoming {
+			p, err := s.products.GetByID(ctx, productID)
+			if err != nil {
+				return err
+			}
+			p.Stock += qty
+			if err := s.products.Update(ctx, p); err != nil {
+				return err
+			}
+			po.Lines[lineByProduct[productID]].Received += qty
+		}
+		for _, r := range serialized {
+			if _, err := registerSerials(ctx, s.batches, s.serials, r.ProductID, r.Batch, r.Serials); err != nil {
+				return err
+			}
+		}
+		po.Status = domain.PurchaseOrderStatusReceived
+		for _, l := range po.Lines {
+			if l.Outstanding() > 0 {
+				po.Status = domain.PurchaseOrderStatusPartiallyReceived
+				break
+			}
+		}
+		if err := s.purchases.Update(ctx, po); err != nil {
+			return err
+		}
+		updated = po
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, events.GoodsReceived{
+		PurchaseOrder: *updated,
+		Lines:         append([]domain.ReceiptLine(nil), receipt...),
+	})
+	return updated, nil
+}
+
+// checkReceiptSerials проверяет серии и коды маркированных строк приёмки и
+// возвращает эти строки с нормализованными кодами
+func (s *PurchaseService) checkReceiptSerials(ctx context.Context, receipt []domain.ReceiptLine) ([]domain.ReceiptLine, error) {
+	var out []domain.ReceiptLine
+	seen := make(map[string]bool)
+	for _, r := range receipt {
+		p, err := s.products.GetByID(ctx, r.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if !p.Serialized {
+			if len(r.Serials) > 0 {
+				return nil, invalidField("serials", "product %d is not serialized", p.ID)
+			}
+			continue
+		}
+		if s.serials == nil {
+			return nil, invalidField("serials", "serial tracking is not configured")
+		}
+		if int64(len(r.Serials)) != r.Quantity {
+			return nil, invalidField("serials", "product %d needs %d codes, got %d", p.ID, r.Quantity, len(r.Serials))
+		}
+		batch, codes, err := checkNewSerials(ctx, s.serials, *p, r.Batch, r.Serials)
+		if err != nil {
+			return nil, err
+		}
+		for _, code := range codes {
+			if seen[code] {
+				return nil, invalidField("serials", "duplicate code %q", code)
+			}
+			seen[code] = true
+		}
+		out = append(out, domain.ReceiptLine{ProductID: p.ID, Quantity: r.Quantity, Batch: batch, Serials: codes})
+	}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func setupPurchasing(t *testing.T) (*ProductService, *PurchaseService) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	ps := NewProductService(store, tx)
+	pur := NewPurchaseService(store, repository.NewMemorySuppliers(store), repository.NewMemoryPurchaseOrders(store), tx)
+	return ps, pur
+}
+
+func TestPurchaseOrder_ReceiveInParts(t *testing.T) {
+	ctx := context.Background()
+	ps, pur := setupPurchasing(t)
+	p1, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 1})
+	p2, _ := ps.Create(ctx, domain.Product{Name: "B", SKU: "S2", Price: 20, Stock: 0})
+	sup, err := pur.CreateSupplier(ctx, domain.Supplier{Name: "Farm"})
+	if err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+	po, err := pur.CreatePurchaseOrder(ctx, sup.ID, []domain.PurchaseOrderLine{
+		{ProductID: p1.ID, Quantity: 10, UnitCost: 5},
+		{ProductID: p2.ID, Quantity: 4, UnitCost: 12},
+	})
+	if err != nil {
+		t.Fatalf("create po: %v", err)
+	}
+	if po.Status != domain.PurchaseOrderStatusDraft {
+		t.Fatalf("expected draft, got %v", po.Status)
+	}
+
+	// приёмка по черновику запрещена
+	if _, err := pur.ReceiveGoods(ctx, po.ID, []domain.ReceiptLine{{ProductID: p1.ID, Quantity: 1}}); err != ErrInvalidState {
+		t.Fatalf("expected invalid state, got %v", err)
+	}
+	if _, err := pur.SendPurchaseOrder(ctx, po.ID); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	po, err = pur.ReceiveGoods(ctx, po.ID, []domain.ReceiptLine{{ProductID: p1.ID, Quantity: 6}})
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if po.Status != domain.PurchaseOrderStatusPartiallyReceived {
+		t.Fatalf("expected partially received, got %v", po.Status)
+	}
+	p1a, _ := ps.GetByID(ctx, p1.ID)
+	if p1a.Stock != 7 {
+		t.Fatalf("p1 stock expected 7, got %v", p1a.Stock)
+	}
+
+	// больше ожидаемого принять нельзя, запас не меняется
+	if _, err := pur.ReceiveGoods(ctx, po.ID, []domain.ReceiptLine{{ProductID: p1.ID, Quantity: 5}}); err != ErrInvalidInput {
+		t.Fatalf("expected invalid input on over-receipt, got %v", err)
+	}
+	p1a, _ = ps.GetByID(ctx, p1.ID)
+	if p1a.Stock != 7 {
+		t.Fatalf("stock changed on rejected receipt: %v", p1a.Stock)
+	}
+
+	po, err = pur.ReceiveGoods(ctx, po.ID, []domain.ReceiptLine{{ProductID: p1.ID, Quantity: 4}, {ProductID: p2.ID, Quantity: 4}})
+	if err != nil {
+		t.Fatalf("receive rest: %v", err)
+	}
+	if po.Status != domain.PurchaseOrderStatusReceived {
+		t.Fatalf("expected received, got %v", po.Status)
+	}
+	p2a, _ := ps.GetByID(ctx, p2.ID)
+	if p2a.Stock != 4 {
+		t.Fatalf("p2 stock expected 4, got %v", p2a.Stock)
+	}
+	if _, err := pur.CancelPurchaseOrder(ctx, po.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state on cancel of received po, got %v", err)
+	}
+}
+
+func TestPurchaseOrder_InvalidInput(t *testing.T) {
+	ctx := context.Background()
+	ps, pur := setupPurchasing(t)
+	p1, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 1})
+	sup, _ := pur.CreateSupplier(ctx, domain.Supplier{Name: "Farm"})
+
+	if _, err := pur.CreateSupplier(ctx, domain.Supplier{}); err == nil {
+		t.Fatalf("expected error on empty supplier name")
+	}
+	if _, err := pur.CreatePurchaseOrder(ctx, sup.ID, []domain.PurchaseOrderLine{{ProductID: p1.ID, Quantity: 0}}); err == nil {
+		t.Fatalf("expected error on zero quantity")
+	}
+	if _, err := pur.CreatePurchaseOrder(ctx, sup.ID, []domain.PurchaseOrderLine{
+		{ProductID: p1.ID, Quantity: 1}, {ProductID: p1.ID, Quantity: 2},
+	}); err == nil {
+		t.Fatalf("expected error on duplicate product lines")
+	}
+	if _, err := pur.CreatePurchaseOrder(ctx, 42, []domain.PurchaseOrderLine{{ProductID: p1.ID, Quantity: 1}}); err != repository.ErrNotFound {
+		t.Fatalf("expected not found supplier, got %v", err)
+	}
+	if _, err := pur.CreatePurchaseOrder(ctx, sup.ID, []domain.PurchaseOrderLine{{ProductID: 42, Quantity: 1}}); err != repository.ErrNotFound {
+		t.Fatalf("expected not found product, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+// refundKinds порядок видов возвратов в сводке
+var refundKinds = []domain.RefundKind{domain.RefundKindReturn, domain.RefundKindPartialCancel, domain.RefundKindEdit, domain.RefundKindCancel}
+
+// RefundSummary сводит возвраты денег, рассчитанные в периоде [from, to), по видам:
+// возвраты покупателей, частичные и полные отмены заказов считаются отдельно.
+// Частичные отмены дополнительно разбиты по причинам.
+func (s *OrderService) RefundSummary(ctx context.Context, from, to time.Time) (*domain.RefundSummary, error) {
+	if from.IsZero() {
+		return nil, invalidField("from", "is required")
+	}
+	if to.IsZero() {
+		return nil, invalidField("to", "is required")
+	}
+	if !from.Before(to) {
+		return nil, invalidField("from", "must be before to")
+	}
+	out := &domain.RefundSummary{From: from, To: to, Kinds: make([]domain.RefundKindSummary, len(refundKinds)), CancelReasons: []domain.RefundReasonSummary{}}
+	for i, k := range refundKinds {
+		out.Kinds[i].Kind = k
+	}
+	if s.refunds == nil {
+		return out, nil
+	}
+	list, err := s.refunds.List(ctx, repository.RefundFilter{CreatedFrom: &from, CreatedTo: &to})
+	if err != nil {
+		return nil, err
+	}
+	byReason := make(map[string]*domain.RefundReasonSummary)
+	for _, r := range list {
+		var units int64
+		for _, l := range r.Lines {
+			units += l.Quantity
+		}
+		for i := range out.Kinds {
+			if k := &out.Kinds[i]; k.Kind == r.Kind {
+				k.Refunds++
+				k.Units += units
+				k.Amount += r.Amount
+				k.Tax += r.Tax
+			}
+		}
+		if r.Kind != domain.RefundKindPartialCancel {
+			continue
+		}
+		rs, ok := byReason[r.Reason]
+		if !ok {
+			rs = &domain.RefundReasonSummary{Reason: r.Reason}
+			byReason[r.Reason] = rs
+		}
+		rs.Refunds++
+		rs.Units += units
+		rs.Amount += r.Amount
+	}
+	for i := range out.Kinds {
+		k := &out.Kinds[i]
+		k.Amount, k.Tax = roundMoney(k.Amount), roundMoney(k.Tax)
+	}
+	for _, rs := range byReason {
+		rs.Amount = roundMoney(rs.Amount)
+		out.CancelReasons = append(out.CancelReasons, *rs)
+	}
+	sort.Slice(out.CancelReasons, func(i, j int) bool { return out.CancelReasons[i].Reason < out.CancelReasons[j].Reason })
+	return out, nil
+}
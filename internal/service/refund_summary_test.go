@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func TestRefundSummary_SeparatesCancellationsFromReturns(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	os := NewOrderService(store, repository.NewMemoryOrders(store), repository.NewMemoryTx(store))
+	os.SetRefunds(repository.NewMemoryRefunds(store))
+
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "A", Price: 10, Stock: 20})
+	from := time.Now().Add(-time.Minute)
+	newOrder := func() int64 {
+		o, err := os.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: p.ID, Quantity: 4}})
+		if err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+		return o.ID
+	}
+	one := []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}
+	a, b := newOrder(), newOrder()
+	if _, _, err := os.PartialReturn(ctx, a, one); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	if _, _, err := os.PartialCancel(ctx, a, []domain.OrderItem{{ProductID: p.ID, Quantity: 2}}, "out_of_stock"); err != nil {
+		t.Fatalf("partial cancel: %v", err)
+	}
+	if _, _, err := os.PartialCancel(ctx, b, one, "damaged"); err != nil {
+		t.Fatalf("partial cancel: %v", err)
+	}
+	if _, _, err := os.EditOrder(ctx, b, []domain.OrderItem{{ProductID: p.ID, Quantity: 2}}, ""); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if _, _, err := os.CancelOrder(ctx, b); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	sum, err := os.RefundSummary(ctx, from, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	want := []domain.RefundKindSummary{
+		{Kind: domain.RefundKindReturn, Refunds: 1, Units: 1, Amount: 10},
+		{Kind: domain.RefundKindPartialCancel, Refunds: 2, Units: 3, Amount: 30},
+		{Kind: domain.RefundKindEdit, Refunds: 1, Amount: 10},
+		{Kind: domain.RefundKindCancel, Refunds: 1, Units: 2, Amount: 20},
+	}
+	if len(sum.Kinds) != len(want) {
+		t.Fatalf("unexpected kinds %+v", sum.Kinds)
+	}
+	for i := range want {
+		if sum.Kinds[i] != want[i] {
+			t.Fatalf("kind %d: got %+v, want %+v", i, sum.Kinds[i], want[i])
+		}
+	}
+	if len(sum.CancelReasons) != 2 || sum.CancelReasons[0].Reason != "damaged" || sum.CancelReasons[1].Units != 2 || sum.CancelReasons[1].Amount != 20 {
+		t.Fatalf("unexpected reasons %+v", sum.CancelReasons)
+	}
+
+	empty, err := os.RefundSummary(ctx, from.Add(-time.Hour), from)
+	if err != nil || empty.Kinds[0].Refunds != 0 || len(empty.CancelReasons) != 0 {
+		t.Fatalf("expected empty summary, got %+v %v", empty, err)
+	}
+	if _, err := os.RefundSummary(ctx, from, from); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid period, got %v", err)
+	}
+}
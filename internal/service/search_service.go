@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/search"
+)
+
+// Веса полей товара в поисковом индексе
+const (
+	searchWeightName       = 3
+	searchWeightSKU        = 2
+	searchWeightAttributes = 1
+)
+
+// SearchService держит полнотекстовый индекс товаров и обновляет его по событиям каталога
+type SearchService struct {
+	products repository.ProductRepository
+	index    *search.Index
+}
+
+func NewSearchService(products repository.ProductRepository) *SearchService {
+	return &SearchService{products: products, index: search.NewIndex()}
+}
+
+// Rebuild заново индексирует весь каталог
+func (s *SearchService) Rebuild(ctx context.Context) error {
+	all, err := s.products.List(ctx, repository.ProductFilter{})
+	if err != nil {
+		return err
+	}
+	for _, p := range all {
+		s.index.Put(p.ID, productFields(p)...)
+	}
+	return nil
+}
+
+// Subscribe обновляет индекс при создании, изменении и удалении товаров.
+// Обработчики синхронные: товар находится поиском сразу после ответа на запрос.
+func (s *SearchService) Subscribe(bus *events.Bus) {
+	events.On(bus, func(ctx context.Context, e events.ProductCreated) {
+		s.index.Put(e.Product.ID, productFields(e.Product)...)
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductUpdated) {
+		s.index.Put(e.Product.ID, productFields(e.Product)...)
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductDeleted) {
+		s.index.Remove(e.ProductID)
+	})
+}
+
+// Search возвращает id подходящих товаров, самые релевантные первыми
+func (s *SearchService) Search(ctx context.Context, query string) ([]int64, error) {
+	hits := s.index.Search(query)
+	ids := make([]int64, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return ids, nil
+}
+
+func productFields(p domain.Product) []search.Field {
+	attrs := make([]string, 0, len(p.Attributes))
+	for _, v := range p.Attributes {
+		attrs = append(attrs, v)
+	}
+	return []search.Field{
+		{Text: p.Name, Weight: searchWeightName},
+		{Text: p.SKU, Weight: searchWeightSKU},
+		{Text: strings.Join(attrs, " "), Weight: searchWeightAttributes},
+	}
+}
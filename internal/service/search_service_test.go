@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+func TestSearch_IndexedFromEvents(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	bus := events.NewBus()
+	ps := NewProductService(store, repository.NewMemoryTx(store))
+	ps.SetEvents(bus)
+
+	// товары, созданные до подключения поиска, попадают в индекс через Rebuild
+	old, _ := ps.Create(ctx, domain.Product{Name: "Аспирин Кардио", SKU: "A-100", Price: 120})
+	searchSvc := NewSearchService(store)
+	if err := searchSvc.Rebuild(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	searchSvc.Subscribe(bus)
+	ps.SetSearch(searchSvc)
+
+	effervescent, _ := ps.Create(ctx, domain.Product{Name: "Aspirin C", SKU: "A-200", Price: 90})
+	nurofen, _ := ps.Create(ctx, domain.Product{Name: "Нурофен", SKU: "N-1", Price: 200})
+
+	list, err := ps.List(ctx, repository.ProductFilter{NameSubstring: "аспирин"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected both aspirins, got %+v", list)
+	}
+	maxPrice := 100.0
+	list, _ = ps.List(ctx, repository.ProductFilter{NameSubstring: "asprin", MaxPrice: &maxPrice})
+	if len(list) != 1 || list[0].ID != effervescent.ID {
+		t.Fatalf("expected typo match filtered by price, got %+v", list)
+	}
+	list, _ = ps.List(ctx, repository.ProductFilter{NameSubstring: "aspirin", IDs: []int64{old.ID}})
+	if len(list) != 1 || list[0].ID != old.ID {
+		t.Fatalf("expected ids to narrow results, got %+v", list)
+	}
+
+	nurofen.Name = "Нурофен Экспресс"
+	if _, err := ps.Update(ctx, *nurofen); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if list, _ := ps.List(ctx, repository.ProductFilter{NameSubstring: "ekspress"}); len(list) != 1 {
+		t.Fatalf("updated product not reindexed: %+v", list)
+	}
+	_, _ = ps.Archive(ctx, nurofen.ID)
+	if list, _ := ps.List(ctx, repository.ProductFilter{NameSubstring: "nurofen"}); len(list) != 0 {
+		t.Fatalf("archived product still found: %+v", list)
+	}
+}
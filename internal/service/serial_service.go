@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+// SerialService учитывает маркированные упаковки (коды Data Matrix) от приёмки до продажи.
+// Продажа и возврат упаковок выполняются в OrderService, приёмка — в PurchaseService.
+type SerialService struct {
+	products repository.ProductRepository
+	batches  repository.BatchRepository
+	serials  repository.SerialRepository
+	tx       repository.TxManager
+}
+
+func NewSerialService(products repository.ProductRepository, batches repository.BatchRepository, serials repository.SerialRepository, tx repository.TxManager) *SerialService {
+	return &SerialService{products: products, batches: batches, serials: serials, tx: tx}
+}
+
+// Register заводит коды упаковок уже имеющегося запаса (например, при переходе на маркировку).
+// Упаковок на складе не может стать больше, чем запас товара.
+func (s *SerialService) Register(ctx context.Context, productID int64, batch string, codes []string) ([]domain.SerialUnit, error) {
+	if productID <= 0 || len(codes) == 0 {
+		return nil, ErrInvalidInput
+	}
+	var units []domain.SerialUnit
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		p, err := s.products.GetByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+		inStock, err := s.serials.List(ctx, repository.SerialFilter{ProductID: p.ID, Status: domain.SerialStatusInStock})
+		if err != nil {
+			return err
+		}
+		if int64(len(inStock)+len(codes)) > p.Stock {
+			return invalidField("codes", "%d packs would exceed product stock %d", len(inStock)+len(codes), p.Stock)
+		}
+		batch, codes, err := checkNewSerials(ctx, s.serials, *p, batch, codes)
+		if err != nil {
+			return err
+		}
+		units, err = registerSerials(ctx, s.batches, s.serials, p.ID, batch, codes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+func (s *SerialService) Get(ctx context.Context, code string) (*domain.SerialUnit, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, ErrInvalidInput
+	}
+	return s.serials.Get(ctx, code)
+}
+
+func (s *SerialService) List(ctx context.Context, f repository.SerialFilter) ([]domain.SerialUnit, error) {
+	return s.serials.List(ctx, f)
+}
+
+// Batches серии товара
+func (s *SerialService) Batches(ctx context.Context, productID int64) ([]domain.Batch, error) {
+	if productID <= 0 {
+		return nil, ErrInvalidInput
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.batches.List(ctx, productID)
+}
+
+// checkNewSerials проверяет серию и коды приходуемых упаковок маркированного товара
+// до любой записи (транзакция не откатывается) и возвращает их нормализованными
+func checkNewSerials(ctx context.Context, serials repository.SerialRepository, p domain.Product, batch string, codes []string) (string, []string, error) {
+	if !p.Serialized {
+		return "", nil, invalidField("serials", "product %d is not serialized", p.ID)
+	}
+	batch = strings.TrimSpace(batch)
+	if batch == "" {
+		return "", nil, invalidField("batch", "required for serialized product %d", p.ID)
+	}
+	codes, err := normalizeSerials("serials", codes)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, code := range codes {
+		if _, err := serials.Get(ctx, code); err == nil {
+			return "", nil, repository.ErrConflict
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return "", nil, err
+		}
+	}
+	return batch, codes, nil
+}
+
+// registerSerials заводит проверенные упаковки на склад; серия создаётся при первом упоминании
+func registerSerials(ctx context.Context, batches repository.BatchRepository, serials repository.SerialRepository, productID int64, batch string, codes []string) ([]domain.SerialUnit, error) {
+	b, err := batches.FindByNumber(ctx, productID, batch)
+	if errors.Is(err, repository.ErrNotFound) {
+		b = &domain.Batch{ProductID: productID, Number: batch}
+		err = batches.Create(ctx, b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	units := make([]domain.SerialUnit, 0, len(codes))
+	for _, code := range codes {
+		u := domain.SerialUnit{Code: code, ProductID: productID, BatchID: b.ID, Status: domain.SerialStatusInStock}
+		if err := serials.Create(ctx, &u); err != nil {
+			return nil, err
+		}
+		units = append(units, u)
+	}
+	return units, nil
+}
+
+// normalizeSerials обрезает пробелы; пустой или повторённый код — ошибка поля field
+func normalizeSerials(field string, codes []string) ([]string, error) {
+	out := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, raw := range codes {
+		code := strings.TrimSpace(raw)
+		if code == "" {
+			return nil, invalidField(field, "empty code")
+		}
+		if seen[code] {
+			return nil, invalidField(field, "duplicate code %q", code)
+		}
+		seen[code] = true
+		out = append(out, code)
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+type serialFixture struct {
+	ps  *ProductService
+	os  *OrderService
+	pur *PurchaseService
+	ss  *SerialService
+}
+
+func setupSerials(t *testing.T) serialFixture {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	batches := repository.NewMemoryBatches(store)
+	serials := repository.NewMemorySerials(store)
+	os := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	os.SetSerials(serials)
+	pur := NewPurchaseService(store, repository.NewMemorySuppliers(store), repository.NewMemoryPurchaseOrders(store), tx)
+	pur.SetSerials(batches, serials)
+	return serialFixture{
+		ps: NewProductService(store, tx), os: os, pur: pur,
+		ss: NewSerialService(store, batches, serials, tx),
+	}
+}
+
+func TestSerials_ReceiptToSaleAndBack(t *testing.T) {
+	ctx := context.Background()
+	f := setupSerials(t)
+	p, _ := f.ps.Create(ctx, domain.Product{Name: "Insulin", SKU: "I1", Price: 10, Serialized: true})
+	sup, _ := f.pur.CreateSupplier(ctx, domain.Supplier{Name: "Farm"})
+	po, _ := f.pur.CreatePurchaseOrder(ctx, sup.ID, []domain.PurchaseOrderLine{{ProductID: p.ID, Quantity: 3, UnitCost: 5}})
+	_, _ = f.pur.SendPurchaseOrder(ctx, po.ID)
+
+	// маркированный товар принимается только с кодами на каждую упаковку
+	if _, err := f.pur.ReceiveGoods(ctx, po.ID, []domain.ReceiptLine{{ProductID: p.ID, Quantity: 3, Batch: "B1", Serials: []string{"c1", "c2"}}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+	if _, err := f.pur.ReceiveGoods(ctx, po.ID, []domain.ReceiptLine{{ProductID: p.ID, Quantity: 3, Batch: "B1", Serials: []string{"c1", "c2", "c3"}}}); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	units, _ := f.ss.List(ctx, repository.SerialFilter{ProductID: p.ID, Status: domain.SerialStatusInStock})
+	if len(units) != 3 || units[0].BatchID == 0 {
+		t.Fatalf("unexpected units %+v", units)
+	}
+	if _, err := f.ss.Register(ctx, p.ID, "B2", []string{"c3"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected stock limit, got %v", err)
+	}
+
+	if _, err := f.os.CreateOrder(ctx, "A", []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected codes to be required, got %v", err)
+	}
+	if _, err := f.os.CreateOrder(ctx, "A", []domain.OrderItem{{ProductID: p.ID, Quantity: 2, Serials: []string{"c1", "c1"}}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected duplicate code to be rejected, got %v", err)
+	}
+	o, err := f.os.CreateOrder(ctx, "A", []domain.OrderItem{{ProductID: p.ID, Quantity: 2, Serials: []string{"c1", " c2"}}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if _, err := f.os.CreateOrder(ctx, "B", []domain.OrderItem{{ProductID: p.ID, Quantity: 1, Serials: []string{"c2"}}}); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected already sold, got %v", err)
+	}
+	u, _ := f.ss.Get(ctx, "c2")
+	if u.Status != domain.SerialStatusSold || u.OrderID != o.ID {
+		t.Fatalf("unit not sold: %+v", u)
+	}
+
+	if _, _, err := f.os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1, Serials: []string{"c3"}}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected foreign code to be rejected, got %v", err)
+	}
+	o, _, err = f.os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1, Serials: []string{"c2"}}})
+	if err != nil {
+		t.Fatalf("partial return: %v", err)
+	}
+	if len(o.Items) != 1 || o.Items[0].Quantity != 1 || len(o.Items[0].Serials) != 1 || o.Items[0].Serials[0] != "c1" {
+		t.Fatalf("unexpected items %+v", o.Items)
+	}
+	if u, _ := f.ss.Get(ctx, "c2"); u.Status != domain.SerialStatusInStock || u.OrderID != 0 {
+		t.Fatalf("returned unit not restocked: %+v", u)
+	}
+
+	if _, _, err := f.os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	units, _ = f.ss.List(ctx, repository.SerialFilter{ProductID: p.ID, Status: domain.SerialStatusInStock})
+	got, _ := f.ps.GetByID(ctx, p.ID)
+	if len(units) != 3 || got.Stock != 3 {
+		t.Fatalf("expected 3 packs and stock 3, got %d and %d", len(units), got.Stock)
+	}
+}
+
+func TestSerials_RegisterExistingStock(t *testing.T) {
+	ctx := context.Background()
+	f := setupSerials(t)
+	plain, _ := f.ps.Create(ctx, domain.Product{Name: "Plain", SKU: "P1", Stock: 5})
+	p, _ := f.ps.Create(ctx, domain.Product{Name: "Marked", SKU: "M1", Stock: 3, Serialized: true})
+
+	if _, err := f.ss.Register(ctx, plain.ID, "B1", []string{"x"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected not serialized, got %v", err)
+	}
+	if _, err := f.ss.Register(ctx, p.ID, "", []string{"x"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected batch required, got %v", err)
+	}
+	units, err := f.ss.Register(ctx, p.ID, "B1", []string{"x", "y"})
+	if err != nil || len(units) != 2 {
+		t.Fatalf("register: %v", err)
+	}
+	if _, err := f.ss.Register(ctx, p.ID, "B1", []string{"x"}); err != repository.ErrConflict {
+		t.Fatalf("expected duplicate to be rejected, got %v", err)
+	}
+	batches, _ := f.ss.Batches(ctx, p.ID)
+	if len(batches) != 1 || batches[0].Number != "B1" {
+		t.Fatalf("unexpected batches %+v", batches)
+	}
+	// коды маркированного товара нельзя передать для обычного
+	if _, err := f.os.CreateOrder(ctx, "A", []domain.OrderItem{{ProductID: plain.ID, Quantity: 1, Serials: []string{"x"}}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+	if _, err := f.os.CreateOrder(ctx, "A", []domain.OrderItem{{ProductID: plain.ID, Quantity: 1}, {ProductID: p.ID, Quantity: 1, Serials: []string{"y"}}}); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+// StocktakeService ведёт инвентаризации: снимок ожидаемого запаса, приём
+// результатов пересчёта и применение расхождений при утверждении
+type StocktakeService struct {
+	products   repository.ProductRepository
+	stocktakes repository.StocktakeRepository
+	tx         repository.TxManager
+	events     *events.Bus
+}
+
+func NewStocktakeService(products repository.ProductRepository, stocktakes repository.StocktakeRepository, tx repository.TxManager) *StocktakeService {
+	return &StocktakeService{products: products, stocktakes: stocktakes, tx: tx}
+}
+
+// SetEvents подключает шину событий; события публикуются после фиксации транзакции
+func (s *StocktakeService) SetEvents(bus *events.Bus) { s.events = bus }
+
+// Start открывает инвентаризацию и снимает ожидаемый запас по товарам.
+// Пустой список означает весь каталог без архивных товаров.
+func (s *StocktakeService) Start(ctx context.Context, productIDs []int64) (*domain.Stocktake, error) {
+	seen := make(map[int64]bool, len(productIDs))
+	for _, id := range productIDs {
+		if id <= 0 || seen[id] {
+			return nil, ErrInvalidInput
+		}
+		seen[id] = true
+	}
+	var created *domain.Stocktake
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var products []domain.Product
+		if len(productIDs) == 0 {
+			active := false
+			all, err := s.products.List(ctx, repository.ProductFilter{Archived: &active})
+			if err != nil {
+				return err
+			}
+			products = all
+		} else {
+			for _, id := range productIDs {
+				p, err := s.products.GetByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				products = append(products, *p)
+			}
+		}
+		if len(products) == 0 {
+			return ErrInvalidInput
+		}
+		sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+		st := domain.Stocktake{Status: domain.StocktakeStatusOpen}
+		for _, p := range products {
+			st.Lines = append(st.Lines, domain.StocktakeLine{ProductID: p.ID, Expected: p.Stock})
+		}
+		if err := s.stocktakes.Create(ctx, &st); err != nil {
+			return err
+		}
+		created = &st
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *StocktakeService) Get(ctx context.Context, id int64) (*domain.Stocktake, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	return s.stocktakes.GetByID(ctx, id)
+}
+
+func (s *StocktakeService) List(ctx context.Context) ([]domain.Stocktake, error) {
+	return s.stocktakes.List(ctx)
+}
+
+// SubmitCounts добавляет посчитанные количества. Результаты можно передавать
+// несколькими порциями (например, по полкам): количества по товару суммируются.
+func (s *StocktakeService) SubmitCounts(ctx context.Context, id int64, counts []domain.StockCount) (*domain.Stocktake, error) {
+	if id <= 0 || len(counts) == 0 {
+		return nil, ErrInvalidInput
+	}
+	for _, c := range counts {
+		if c.ProductID <= 0 || c.Quantity < 0 {
+			return nil, ErrInvalidInput
+		}
+	}
+	var updated *domain.Stocktake
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		st, err := s.stocktakes.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if st.Status != domain.StocktakeStatusOpen {
+			return ErrInvalidState
+		}
+		lineByProduct := make(map[int64]int, len(st.Lines))
+		for i, l := range st.Lines {
+			lineByProduct[l.ProductID] = i
+		}
+		for _, c := range counts {
+			i, ok := lineByProduct[c.ProductID]
+			if !ok {
+				return ErrInvalidInput
+			}
+			l := &st.Lines[i]
+			total := c.Quantity
+			if l.Counted != nil {
+				total += *l.Counted
+			}
+			l.Counted = &total
+			l.Variance = total - l.Expected
+		}
+		if err := s.stocktakes.Update(ctx, st); err != nil {
+			return err
+		}
+		updated = st
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Variances возвращает посчитанные строки с ненулевым расхождением
+func (s *StocktakeService) Variances(ctx context.Context, id int64) ([]domain.StocktakeLine, error) {
+	st, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.StocktakeLine, 0)
+	for _, l := range st.Lines {
+		if l.Counted != nil && l.Variance != 0 {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// Approve утверждает инвентаризацию и атомарно применяет расхождения.
+// Корректировка — разница факта и снимка, а не перезапись остатка: продажи и
+// приёмки, прошедшие после начала пересчёта, сохраняются.
+func (s *StocktakeService) Approve(ctx context.Context, id int64) (*domain.Stocktake, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var updated *domain.Stocktake
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		st, err := s.stocktakes.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if st.Status != domain.StocktakeStatusOpen {
+			return ErrInvalidState
+		}
+		for _, l := range st.Lines {
+			if l.Counted == nil {
+				// не все товары посчитаны
+				return ErrInvalidState
+			}
+		}
+		for i := range st.Lines {
+			l := &st.Lines[i]
+			if l.Variance == 0 {
+				continue
+			}
+			p, err := s.products.GetByID(ctx, l.ProductID)
+			if err != nil {
+				return err
+			}
+			newStock := p.Stock + l.Variance
+			if newStock < 0 {
+				newStock = 0
+			}
+			l.Adjusted = newStock - p.Stock
+			p.Stock = newStock
+			if err := s.products.Update(ctx, p); err != nil {
+				return err
+			}
+		}
+		now := time.Now().UTC()
+		st.Status = domain.StocktakeStatusApproved
+		st.ApprovedAt = &now
+		if err := s.stocktakes.Update(ctx, st); err != nil {
+			return err
+		}
+		updated = st
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, events.StocktakeApproved{Stocktake: *updated})
+	return updated, nil
+}
+
+// Cancel отменяет открытую инвентаризацию без изменения запаса
+func (s *StocktakeService) Cancel(ctx context.Context, id int64) (*domain.Stocktake, error) {
+	if id <= 0 {
+		return nil, ErrInvalidInput
+	}
+	var updated *domain.Stocktake
+	err := s.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		st, err := s.stocktakes.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if st.Status != domain.StocktakeStatusOpen {
+			return ErrInvalidState
+		}
+		st.Status = domain.StocktakeStatusCancelled
+		if err := s.stocktakes.Update(ctx, st); err != nil {
+			return err
+		}
+		updated = st
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func setupStocktake(t *testing.T) (*ProductService, *OrderService, *StocktakeService) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	ps := NewProductService(store, tx)
+	os := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+	sts := NewStocktakeService(store, repository.NewMemoryStocktakes(store), tx)
+	return ps, os, sts
+}
+
+func TestStocktake_ApplyVarianceWhileOrdersFlow(t *testing.T) {
+	ctx := context.Background()
+	ps, os, sts := setupStocktake(t)
+	p1, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 10})
+	p2, _ := ps.Create(ctx, domain.Product{Name: "B", SKU: "S2", Price: 10, Stock: 5})
+
+	st, err := sts.Start(ctx, []int64{p1.ID, p2.ID})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if len(st.Lines) != 2 || st.Lines[0].Expected != 10 || st.Lines[1].Expected != 5 {
+		t.Fatalf("unexpected snapshot: %+v", st.Lines)
+	}
+
+	// продажа после снимка
+	if _, err := os.CreateOrder(ctx, "John", []domain.OrderItem{{ProductID: p1.ID, Quantity: 3}}); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	// подсчёт двумя порциями: 4 + 4 = 8 при ожидаемых 10
+	if _, err := sts.SubmitCounts(ctx, st.ID, []domain.StockCount{{ProductID: p1.ID, Quantity: 4}}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, err := sts.Approve(ctx, st.ID); err != ErrInvalidState {
+		t.Fatalf("expected invalid state with uncounted lines, got %v", err)
+	}
+	st, err = sts.SubmitCounts(ctx, st.ID, []domain.StockCount{{ProductID: p1.ID, Quantity: 4}, {ProductID: p2.ID, Quantity: 5}})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	vars, _ := sts.Variances(ctx, st.ID)
+	if len(vars) != 1 || vars[0].ProductID != p1.ID || vars[0].Variance != -2 {
+		t.Fatalf("unexpected variances: %+v", vars)
+	}
+
+	st, err = sts.Approve(ctx, st.ID)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if st.Status != domain.StocktakeStatusApproved || st.ApprovedAt == nil {
+		t.Fatalf("expected approved")
+	}
+	// 10 - 3 (продажа) - 2 (недостача) = 5
+	p1a, _ := ps.GetByID(ctx, p1.ID)
+	if p1a.Stock != 5 {
+		t.Fatalf("p1 stock expected 5, got %v", p1a.Stock)
+	}
+	p2a, _ := ps.GetByID(ctx, p2.ID)
+	if p2a.Stock != 5 {
+		t.Fatalf("p2 stock expected 5, got %v", p2a.Stock)
+	}
+
+	if _, err := sts.SubmitCounts(ctx, st.ID, []domain.StockCount{{ProductID: p1.ID, Quantity: 1}}); err != ErrInvalidState {
+		t.Fatalf("expected invalid state after approval, got %v", err)
+	}
+}
+
+func TestStocktake_WholeCatalogueAndCancel(t *testing.T) {
+	ctx := context.Background()
+	ps, _, sts := setupStocktake(t)
+	p1, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 3})
+	_, _ = ps.Create(ctx, domain.Product{Name: "B", SKU: "S2", Price: 10, Stock: 4})
+
+	st, err := sts.Start(ctx, nil)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if len(st.Lines) != 2 {
+		t.Fatalf("expected whole catalogue, got %d lines", len(st.Lines))
+	}
+	if _, err := sts.SubmitCounts(ctx, st.ID, []domain.StockCount{{ProductID: 999, Quantity: 1}}); err != ErrInvalidInput {
+		t.Fatalf("expected invalid input for product outside stocktake, got %v", err)
+	}
+	if _, err := sts.SubmitCounts(ctx, st.ID, []domain.StockCount{{ProductID: p1.ID, Quantity: 0}}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	st, err = sts.Cancel(ctx, st.ID)
+	if err != nil || st.Status != domain.StocktakeStatusCancelled {
+		t.Fatalf("cancel: %v", err)
+	}
+	p1a, _ := ps.GetByID(ctx, p1.ID)
+	if p1a.Stock != 3 {
+		t.Fatalf("cancel must not change stock, got %v", p1a.Stock)
+	}
+}
+
+func TestStocktake_WholeCatalogueSkipsArchived(t *testing.T) {
+	ctx := context.Background()
+	ps, _, sts := setupStocktake(t)
+	p1, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "S1", Price: 10, Stock: 3})
+	p2, _ := ps.Create(ctx, domain.Product{Name: "B", SKU: "S2", Price: 10, Stock: 4})
+	if _, err := ps.Archive(ctx, p2.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	st, err := sts.Start(ctx, nil)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if len(st.Lines) != 1 || st.Lines[0].ProductID != p1.ID {
+		t.Fatalf("archived product counted: %+v", st.Lines)
+	}
+	// строки инвентаризации не ссылаются на архивный товар, удалить его можно
+	if err := ps.Purge(ctx, p2.ID); err != nil {
+		t.Fatalf("purge after stocktake: %v", err)
+	}
+}
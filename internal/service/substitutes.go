@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"april/internal/domain"
+	"april/internal/repository"
+	"april/internal/translit"
+)
+
+// substituteKey ключ взаимозаменяемости: МНН и дозировка, нормализованные без учёта
+// регистра, пробелов и алфавита (Ибупрофен == ibuprofen). Пустой МНН — аналогов нет.
+func substituteKey(p domain.Product) (substance, dosage string) {
+	substance = strings.Join(strings.Fields(translit.ToLatin(p.Attributes[domain.AttrActiveSubstance])), " ")
+	dosage = strings.Join(strings.Fields(translit.ToLatin(p.Attributes[domain.AttrDosage])), "")
+	return substance, dosage
+}
+
+// findSubstitutes ищет аналоги товара с запасом не меньше minStock, сортируя по цене.
+// Если у исходного товара дозировка не указана, сравнивается только МНН.
+func findSubstitutes(ctx context.Context, products repository.ProductRepository, p domain.Product, minStock int64) ([]domain.Product, error) {
+	substance, dosage := substituteKey(p)
+	out := make([]domain.Product, 0)
+	if substance == "" {
+		return out, nil
+	}
+	active := false
+	all, err := products.List(ctx, repository.ProductFilter{Archived: &active})
+	if err != nil {
+		return nil, err
+	}
+	for _, cand := range all {
+		if cand.ID == p.ID || cand.Stock < minStock || cand.Stock <= 0 {
+			continue
+		}
+		cs, cd := substituteKey(cand)
+		if cs != substance || (dosage != "" && cd != dosage) {
+			continue
+		}
+		out = append(out, cand)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Price != out[j].Price {
+			return out[i].Price < out[j].Price
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
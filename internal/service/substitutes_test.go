@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+func TestSubstitutes_LookupAndOrderSuggestions(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	categories := repository.NewMemoryCategories(store)
+	ps := NewProductService(store, tx)
+	ps.SetCategories(categories)
+	cs := NewCategoryService(categories, store, tx)
+	os := NewOrderService(store, repository.NewMemoryOrders(store), tx)
+
+	meds, err := cs.Create(ctx, domain.Category{Name: "Meds", Attributes: []domain.AttributeDef{
+		{Code: domain.AttrActiveSubstance, Name: "МНН", Type: domain.AttributeTypeString},
+		{Code: domain.AttrDosage, Name: "Дозировка", Type: domain.AttributeTypeString},
+	}})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+	mk := func(name string, price float64, stock int64, substance, dosage string) *domain.Product {
+		p, err := ps.Create(ctx, domain.Product{Name: name, SKU: name, Price: price, Stock: stock, CategoryIDs: []int64{meds.ID},
+			Attributes: map[string]string{domain.AttrActiveSubstance: substance, domain.AttrDosage: dosage}})
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		return p
+	}
+	nurofen := mk("Nurofen", 300, 1, "Ибупрофен", "200 mg")
+	mig := mk("MIG", 250, 5, "ibuprofen", "200mg")
+	generic := mk("Ibuprofen", 80, 2, " IBUPROFEN ", "200 MG")
+	_ = mk("Ibuprofen forte", 120, 9, "ibuprofen", "400 mg")
+	_ = mk("Out of stock", 50, 0, "ibuprofen", "200 mg")
+	_ = mk("Paracetamol", 40, 9, "paracetamol", "200 mg")
+
+	subs, err := ps.Substitutes(ctx, nurofen.ID)
+	if err != nil {
+		t.Fatalf("substitutes: %v", err)
+	}
+	if len(subs) != 2 || subs[0].ID != generic.ID || subs[1].ID != mig.ID {
+		t.Fatalf("unexpected substitutes %+v", subs)
+	}
+	if _, err := ps.Substitutes(ctx, 999); err != repository.ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	items := []domain.OrderItem{{ProductID: nurofen.ID, Quantity: 3}}
+	if _, err := os.CreateOrder(ctx, "A", items); err != ErrNotEnoughStock {
+		t.Fatalf("expected plain not enough stock, got %v", err)
+	}
+	_, err = os.CreateOrder(ctx, "A", items, WithSubstituteSuggestions())
+	var stockErr *StockError
+	if !errors.Is(err, ErrNotEnoughStock) || !errors.As(err, &stockErr) {
+		t.Fatalf("expected stock error, got %v", err)
+	}
+	// only analogues that cover the requested quantity are suggested
+	if stockErr.Available != 1 || stockErr.Requested != 3 || len(stockErr.Substitutes) != 1 || stockErr.Substitutes[0].ID != mig.ID {
+		t.Fatalf("unexpected stock error %+v", stockErr)
+	}
+}
+
+func TestCreateOrder_RepeatedLinesShareStock(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+	p, _ := ps.Create(ctx, domain.Product{Name: "A", SKU: "A1", Price: 1, Stock: 4})
+	items := []domain.OrderItem{{ProductID: p.ID, Quantity: 3}, {ProductID: p.ID, Quantity: 2}}
+	if _, err := os.CreateOrder(ctx, "X", items); err != ErrNotEnoughStock {
+		t.Fatalf("expected not enough stock, got %v", err)
+	}
+	items[1].Quantity = 1
+	if _, err := os.CreateOrder(ctx, "X", items); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, _ := ps.GetByID(ctx, p.ID)
+	if got.Stock != 0 {
+		t.Fatalf("expected stock 0, got %d", got.Stock)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+	"april/internal/search"
+)
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// Suggestion подсказка автодополнения для кассы
+type Suggestion struct {
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	SKU   string  `json:"sku"`
+	Price float64 `json:"price"`
+	Stock int64   `json:"stock"`
+	// Popularity сколько единиц товара в действующих заказах: без отменённых,
+	// возвращённых и снятых с заказов
+	Popularity int64 `json:"popularity"`
+}
+
+// SuggestService подсказывает товары по началу названия. Префиксный индекс
+// обновляется по событиям каталога, популярность считается по истории заказов
+// при Rebuild и затем поддерживается событиями заказов.
+type SuggestService struct {
+	products repository.ProductRepository
+	orders   repository.OrderRepository
+	trie     *search.Trie
+
+	mu         sync.RWMutex
+	popularity map[int64]int64
+}
+
+func NewSuggestService(products repository.ProductRepository, orders repository.OrderRepository) *SuggestService {
+	return &SuggestService{products: products, orders: orders, trie: search.NewTrie(), popularity: make(map[int64]int64)}
+}
+
+// Rebuild заново индексирует весь каталог и пересчитывает популярность по
+// текущим строкам неотменённых заказов
+func (s *SuggestService) Rebuild(ctx context.Context) error {
+	all, err := s.products.List(ctx, repository.ProductFilter{})
+	if err != nil {
+		return err
+	}
+	orders, err := s.orders.List(ctx, repository.OrderFilter{})
+	if err != nil {
+		return err
+	}
+	for _, p := range all {
+		s.trie.Put(p.ID, p.Name)
+	}
+	popularity := make(map[int64]int64)
+	for _, o := range orders {
+		if o.Status == domain.OrderStatusCancelled {
+			continue
+		}
+		for _, it := range o.Items {
+			popularity[it.ProductID] += it.Quantity
+		}
+	}
+	s.mu.Lock()
+	s.popularity = popularity
+	s.mu.Unlock()
+	return nil
+}
+
+// Subscribe поддерживает индекс и популярность. Обработчики синхронные и дешёвые:
+// новый товар подсказывается сразу после ответа на запрос.
+func (s *SuggestService) Subscribe(bus *events.Bus) {
+	events.On(bus, func(ctx context.Context, e events.ProductCreated) {
+		s.trie.Put(e.Product.ID, e.Product.Name)
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductUpdated) {
+		s.trie.Put(e.Product.ID, e.Product.Name)
+	})
+	events.On(bus, func(ctx context.Context, e events.ProductDeleted) {
+		s.trie.Remove(e.ProductID)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderCreated) {
+		s.addItems(e.Order.Items, 1)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderCancelled) {
+		s.addItems(e.Order.Items, -1)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderReturned) {
+		s.addItems(e.Returned, -1)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderLinesCancelled) {
+		s.addItems(e.Cancelled, -1)
+	})
+	events.On(bus, func(ctx context.Context, e events.OrderEdited) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, c := range e.Edit.Changes {
+			s.add(c.ProductID, c.After-c.Before)
+		}
+	})
+}
+
+// addItems прибавляет к популярности количества строк со знаком sign
+func (s *SuggestService) addItems(items []domain.OrderItem, sign int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, it := range items {
+		s.add(it.ProductID, sign*it.Quantity)
+	}
+}
+
+// add меняет популярность товара на delta, не опуская её ниже нуля; вызывать под s.mu
+func (s *SuggestService) add(productID, delta int64) {
+	if n := s.popularity[productID] + delta; n > 0 {
+		s.popularity[productID] = n
+		return
+	}
+	delete(s.popularity, productID)
+}
+
+// Suggest возвращает до limit товаров, в названии которых каждое слово префикса
+// начинает какое-либо слово. Сначала товары в наличии, затем по популярности и имени.
+func (s *SuggestService) Suggest(ctx context.Context, prefix string, limit int) ([]Suggestion, error) {
+	if strings.TrimSpace(prefix) == "" || limit < 0 {
+		return nil, ErrInvalidInput
+	}
+	if limit == 0 {
+		limit = defaultSuggestLimit
+	}
+	limit = min(limit, maxSuggestLimit)
+	ids := s.trie.Match(prefix)
+	if len(ids) == 0 {
+		return []Suggestion{}, nil
+	}
+	active := false
+	products, err := s.products.List(ctx, repository.ProductFilter{IDs: ids, Archived: &active})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Suggestion, 0, len(products))
+	s.mu.RLock()
+	for _, p := range products {
+		out = append(out, suggestionOf(p, s.popularity[p.ID]))
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if (a.Stock > 0) != (b.Stock > 0) {
+			return a.Stock > 0
+		}
+		if a.Popularity != b.Popularity {
+			return a.Popularity > b.Popularity
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func suggestionOf(p domain.Product, popularity int64) Suggestion {
+	return Suggestion{ID: p.ID, Name: p.Name, SKU: p.SKU, Price: p.Price, Stock: p.Stock, Popularity: popularity}
+}
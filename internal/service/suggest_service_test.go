@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"april/internal/domain"
+	"april/internal/events"
+	"april/internal/repository"
+)
+
+func TestSuggest_RankedByStockAndPopularity(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	ps := NewProductService(store, tx)
+	ps.SetEvents(bus)
+	ordersRepo := repository.NewMemoryOrders(store)
+	os := NewOrderService(store, ordersRepo, tx)
+	os.SetEvents(bus)
+	sg := NewSuggestService(store, ordersRepo)
+	sg.Subscribe(bus)
+
+	cardio, _ := ps.Create(ctx, domain.Product{Name: "Аспирин Кардио", SKU: "A1", Price: 1, Stock: 10})
+	plain, _ := ps.Create(ctx, domain.Product{Name: "Аспирин", SKU: "A2", Price: 1, Stock: 10})
+	empty, _ := ps.Create(ctx, domain.Product{Name: "Аспирин C", SKU: "A3", Price: 1, Stock: 0})
+	_, _ = ps.Create(ctx, domain.Product{Name: "Нурофен", SKU: "N1", Price: 1, Stock: 10})
+
+	if _, err := os.CreateOrder(ctx, "X", []domain.OrderItem{{ProductID: cardio.ID, Quantity: 3}}); err != nil {
+		t.Fatalf("order: %v", err)
+	}
+	list, err := sg.Suggest(ctx, "асп", 0)
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+	if len(list) != 3 || list[0].ID != cardio.ID || list[1].ID != plain.ID || list[2].ID != empty.ID {
+		t.Fatalf("unexpected order %+v", list)
+	}
+	if list[0].Popularity != 3 || list[0].Stock != 7 {
+		t.Fatalf("unexpected popularity/stock %+v", list[0])
+	}
+	if list, _ := sg.Suggest(ctx, "asp kar", 0); len(list) != 1 || list[0].ID != cardio.ID {
+		t.Fatalf("expected latin multi-word prefix to match, got %+v", list)
+	}
+	if list, _ := sg.Suggest(ctx, "асп", 1); len(list) != 1 {
+		t.Fatalf("limit ignored: %+v", list)
+	}
+	if _, err := sg.Suggest(ctx, " ", 0); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+
+	plain.Name = "Ацетилсалициловая кислота"
+	_, _ = ps.Update(ctx, *plain)
+	_, _ = ps.Archive(ctx, empty.ID)
+	if list, _ := sg.Suggest(ctx, "асп", 0); len(list) != 1 {
+		t.Fatalf("index not updated: %+v", list)
+	}
+}
+
+func TestSuggest_PopularityFromOrderHistory(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	tx := repository.NewMemoryTx(store)
+	bus := events.NewBus()
+	ps := NewProductService(store, tx)
+	ordersRepo := repository.NewMemoryOrders(store)
+	os := NewOrderService(store, ordersRepo, tx)
+	os.SetEvents(bus)
+	p, _ := ps.Create(ctx, domain.Product{Name: "Аспирин", SKU: "A", Price: 1, Stock: 100})
+
+	// история до запуска сервиса подсказок
+	kept, _ := os.CreateOrder(ctx, "X", []domain.OrderItem{{ProductID: p.ID, Quantity: 5}})
+	gone, _ := os.CreateOrder(ctx, "X", []domain.OrderItem{{ProductID: p.ID, Quantity: 7}})
+	if _, _, err := os.CancelOrder(ctx, gone.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if _, _, err := os.PartialReturn(ctx, kept.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+
+	sg := NewSuggestService(store, ordersRepo)
+	if err := sg.Rebuild(ctx); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	sg.Subscribe(bus)
+	check := func(want int64) {
+		t.Helper()
+		list, err := sg.Suggest(ctx, "асп", 0)
+		if err != nil || len(list) != 1 || list[0].Popularity != want {
+			t.Fatalf("expected popularity %d, got %+v %v", want, list, err)
+		}
+	}
+	check(4)
+
+	o, _ := os.CreateOrder(ctx, "Y", []domain.OrderItem{{ProductID: p.ID, Quantity: 6}})
+	check(10)
+	if _, _, err := os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 1}}); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	check(9)
+	if _, _, err := os.PartialCancel(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 2}}, "out_of_stock"); err != nil {
+		t.Fatalf("partial cancel: %v", err)
+	}
+	check(7)
+	if _, _, err := os.EditOrder(ctx, o.ID, []domain.OrderItem{{ProductID: p.ID, Quantity: 5}}, ""); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	check(9)
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	check(4)
+}
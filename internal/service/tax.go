@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"april/internal/domain"
+	"april/internal/repository"
+)
+
+// TaxSummary сводит НДС по ставкам для заказов, созданных в периоде [from, to).
+// Отменённые заказы не учитываются, частично возвращённые — по оставшимся строкам.
+func (s *OrderService) TaxSummary(ctx context.Context, from, to time.Time) (*domain.TaxSummary, error) {
+	if from.IsZero() {
+		return nil, invalidField("from", "is required")
+	}
+	if to.IsZero() {
+		return nil, invalidField("to", "is required")
+	}
+	if !from.Before(to) {
+		return nil, invalidField("from", "must be before to")
+	}
+	orders, err := s.orders.List(ctx, repository.OrderFilter{CreatedFrom: &from, CreatedTo: &to})
+	if err != nil {
+		return nil, err
+	}
+	out := &domain.TaxSummary{From: from, To: to, Rates: []domain.TaxRateSummary{}}
+	byRate := make(map[float64]*domain.TaxRateSummary)
+	for _, o := range orders {
+		if o.Status == domain.OrderStatusCancelled {
+			continue
+		}
+		out.Orders++
+		for _, it := range o.Items {
+			r, ok := byRate[it.TaxRate]
+			if !ok {
+				r = &domain.TaxRateSummary{Rate: it.TaxRate}
+				byRate[it.TaxRate] = r
+			}
+			gross := lineAmount(it) - it.Discount
+			r.Gross += gross
+			r.Tax += it.Tax
+		}
+	}
+	for _, r := range byRate {
+		r.Gross, r.Tax = roundMoney(r.Gross), roundMoney(r.Tax)
+		r.Net = roundMoney(r.Gross - r.Tax)
+		out.Rates = append(out.Rates, *r)
+		out.Gross += r.Gross
+		out.Tax += r.Tax
+	}
+	sort.Slice(out.Rates, func(i, j int) bool { return out.Rates[i].Rate < out.Rates[j].Rate })
+	out.Gross, out.Tax = roundMoney(out.Gross), roundMoney(out.Tax)
+	out.Net = roundMoney(out.Gross - out.Tax)
+	return out, nil
+}
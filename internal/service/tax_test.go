@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"april/internal/domain"
+)
+
+func TestTax_OrderLinesAndPartialReturn(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+	if _, err := ps.Create(ctx, domain.Product{Name: "X", SKU: "X", Price: 1, TaxRate: 120}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid tax rate, got %v", err)
+	}
+	med, _ := ps.Create(ctx, domain.Product{Name: "Med", SKU: "M", Price: 110, Stock: 10, TaxRate: 10})
+	cream, _ := ps.Create(ctx, domain.Product{Name: "Cream", SKU: "C", Price: 60, Stock: 10, TaxRate: 20})
+
+	o, err := os.CreateOrder(ctx, "c", []domain.OrderItem{{ProductID: med.ID, Quantity: 2}, {ProductID: cream.ID, Quantity: 3}})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	// цены включают налог: 220 * 10/110 и 180 * 20/120
+	if o.Items[0].TaxRate != 10 || o.Items[0].Tax != 20 || o.Items[1].Tax != 30 || o.Tax != 50 {
+		t.Fatalf("unexpected taxes %+v", o)
+	}
+	// ставка фиксируется в заказе
+	med.TaxRate = 0
+	_, _ = ps.Update(ctx, *med)
+	o, _, err = os.PartialReturn(ctx, o.ID, []domain.OrderItem{{ProductID: cream.ID, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("partial return: %v", err)
+	}
+	if o.Items[0].Tax != 20 || o.Items[1].Tax != 20 || o.Tax != 40 {
+		t.Fatalf("expected tax recomputed after return, got %+v", o)
+	}
+}
+
+func TestTax_Summary(t *testing.T) {
+	ctx := context.Background()
+	ps, os := setup(t)
+	med, _ := ps.Create(ctx, domain.Product{Name: "Med", SKU: "M", Price: 110, Stock: 10, TaxRate: 10})
+	food, _ := ps.Create(ctx, domain.Product{Name: "Food", SKU: "F", Price: 50, Stock: 10})
+	from := time.Now().Add(-time.Minute)
+	_, _ = os.CreateOrder(ctx, "a", []domain.OrderItem{{ProductID: med.ID, Quantity: 1}, {ProductID: food.ID, Quantity: 2}})
+	o, _ := os.CreateOrder(ctx, "b", []domain.OrderItem{{ProductID: med.ID, Quantity: 3}})
+	if _, _, err := os.CancelOrder(ctx, o.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	to := time.Now().Add(time.Minute)
+
+	sum, err := os.TaxSummary(ctx, from, to)
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if sum.Orders != 1 || len(sum.Rates) != 2 || sum.Gross != 210 || sum.Tax != 10 || sum.Net != 200 {
+		t.Fatalf("unexpected summary %+v", sum)
+	}
+	if r := sum.Rates[1]; r.Rate != 10 || r.Gross != 110 || r.Net != 100 || r.Tax != 10 {
+		t.Fatalf("unexpected rate summary %+v", r)
+	}
+	if sum, _ := os.TaxSummary(ctx, to, to.Add(time.Hour)); sum.Orders != 0 || len(sum.Rates) != 0 {
+		t.Fatalf("expected empty period, got %+v", sum)
+	}
+	if _, err := os.TaxSummary(ctx, to, from); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid period, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Package translit — транслитерация кириллицы в латиницу для слагов и поиска
+package translit
+
+import (
+	"strings"
+	"unicode"
+)
+
+var cyrToLat = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// ToLatin переводит строку в нижний регистр и транслитерирует кириллицу;
+// остальные символы сохраняются
+func ToLatin(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range strings.ToLower(s) {
+		if lat, ok := cyrToLat[r]; ok {
+			b.WriteString(lat)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Slug строит URL-слаг: латиница, цифры и дефисы между словами
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range ToLatin(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// ValidSlug проверяет, что слаг состоит из [a-z0-9] и одиночных дефисов между ними
+func ValidSlug(s string) bool {
+	return s != "" && Slug(s) == s
+}
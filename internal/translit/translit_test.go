@@ -0,0 +1,32 @@
+package translit
+
+import "testing"
+
+func TestToLatin(t *testing.T) {
+	cases := map[string]string{
+		"Аспирин":     "aspirin",
+		"Щётка Ёж":    "shchetka ezh",
+		"Ибупрофен-M": "ibuprofen-m",
+	}
+	for in, want := range cases {
+		if got := ToLatin(in); got != want {
+			t.Fatalf("ToLatin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	cases := map[string]string{
+		"Обезболивающие и жаропонижающие": "obezbolivayushchie-i-zharoponizhayushchie",
+		"  Vitamins & Minerals  ":         "vitamins-minerals",
+		"Витамин C 500":                   "vitamin-c-500",
+	}
+	for in, want := range cases {
+		if got := Slug(in); got != want {
+			t.Fatalf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !ValidSlug("cold-and-flu") || ValidSlug("Cold") || ValidSlug("a--b") || ValidSlug("") {
+		t.Fatalf("ValidSlug mismatch")
+	}
+}